@@ -0,0 +1,51 @@
+/*
+|--------------------------------------------------------------------------
+| Re-encryption Job
+|--------------------------------------------------------------------------
+|
+| This job encrypts the server shares that are not yet under the active
+| KMS key version. Pass -rotate-key to create a new key version first.
+|
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/joho/godotenv"
+
+	"celeste/interfaces"
+)
+
+func init() {
+	// load our environmental variables.
+	if err := godotenv.Load(); err != nil {
+		panic(err)
+	}
+}
+
+func main() {
+	rotateKey := flag.Bool("rotate-key", false, "create a new KMS key version before re-encrypting")
+	flag.Parse()
+
+	ctx := context.Background()
+	service := interfaces.ServiceContainer().RegisterUserCommandService()
+
+	if *rotateKey {
+		err := service.RotateUserShareKey(ctx)
+		if err != nil {
+			log.Fatalf("[JOB] kms key rotation failed: %v", err)
+		}
+
+		log.Println("[JOB] rotated kms key")
+	}
+
+	total, err := service.ReencryptUserShares(ctx)
+	if err != nil {
+		log.Fatalf("[JOB] re-encryption failed after %d user shares: %v", total, err)
+	}
+
+	log.Printf("[JOB] re-encrypted %d user shares", total)
+}
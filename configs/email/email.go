@@ -0,0 +1,85 @@
+package email
+
+import (
+	"os"
+	"time"
+)
+
+// Config holds the email flow configurations
+type Config struct{}
+
+// PasswordResetTokenTTL returns the lifetime of password reset tokens
+func (c Config) PasswordResetTokenTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("PASSWORD_RESET_TOKEN_TTL"))
+	if err != nil || ttl <= 0 {
+		return time.Hour
+	}
+
+	return ttl
+}
+
+// PasswordResetURL returns the client page the password reset token is appended to
+func (c Config) PasswordResetURL() string {
+	return os.Getenv("PASSWORD_RESET_URL")
+}
+
+// VerificationTokenTTL returns the lifetime of email verification tokens
+func (c Config) VerificationTokenTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("EMAIL_VERIFICATION_TOKEN_TTL"))
+	if err != nil || ttl <= 0 {
+		return 24 * time.Hour
+	}
+
+	return ttl
+}
+
+// VerificationURL returns the client page the verification token is appended to
+func (c Config) VerificationURL() string {
+	return os.Getenv("EMAIL_VERIFICATION_URL")
+}
+
+// Driver returns the mailer backend, one of smtp, file or log
+// There is no default since file and log expose the emailed tokens, it must be chosen explicitly
+func (c Config) Driver() string {
+	return os.Getenv("MAIL_DRIVER")
+}
+
+// From returns the sender address of outgoing emails
+func (c Config) From() string {
+	return os.Getenv("MAIL_FROM")
+}
+
+// SMTPHost returns the SMTP server host
+func (c Config) SMTPHost() string {
+	return os.Getenv("MAIL_SMTP_HOST")
+}
+
+// SMTPPort returns the SMTP server port
+func (c Config) SMTPPort() string {
+	port := os.Getenv("MAIL_SMTP_PORT")
+	if len(port) == 0 {
+		return "587"
+	}
+
+	return port
+}
+
+// SMTPUsername returns the SMTP auth username, auth is skipped when empty
+func (c Config) SMTPUsername() string {
+	return os.Getenv("MAIL_SMTP_USERNAME")
+}
+
+// SMTPPassword returns the SMTP auth password
+func (c Config) SMTPPassword() string {
+	return os.Getenv("MAIL_SMTP_PASSWORD")
+}
+
+// FileDirectory returns the directory the file mailer writes messages to
+func (c Config) FileDirectory() string {
+	directory := os.Getenv("MAIL_FILE_DIR")
+	if len(directory) == 0 {
+		return "storage/mail"
+	}
+
+	return directory
+}
@@ -0,0 +1,27 @@
+package iam
+
+import (
+	"os"
+	"strings"
+)
+
+// Config holds the identity and access management configurations
+type Config struct{}
+
+// APIKeyHeader returns the header carrying the API key of service callers
+func (c Config) APIKeyHeader() string {
+	return "X-API-Key"
+}
+
+// AdminWalletAddresses returns the wallet addresses allowed to act on any user
+func (c Config) AdminWalletAddresses() []string {
+	var walletAddresses []string
+	for _, walletAddress := range strings.Split(os.Getenv("ADMIN_WALLET_ADDRESSES"), ",") {
+		walletAddress = strings.TrimSpace(walletAddress)
+		if len(walletAddress) > 0 {
+			walletAddresses = append(walletAddresses, walletAddress)
+		}
+	}
+
+	return walletAddresses
+}
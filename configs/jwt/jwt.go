@@ -0,0 +1,64 @@
+package jwt
+
+import (
+	"os"
+	"time"
+)
+
+// Config holds the JSON web token (JWT) configurations
+type Config struct{}
+
+// AccessTokenTTL returns the lifetime of issued access tokens
+func (c Config) AccessTokenTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("JWT_ACCESS_TOKEN_TTL"))
+	if err != nil || ttl <= 0 {
+		return 15 * time.Minute
+	}
+
+	return ttl
+}
+
+// Algorithm returns the signing algorithm of the tokens
+func (c Config) Algorithm() string {
+	algorithm := os.Getenv("JWT_ALGORITHM")
+	if len(algorithm) == 0 {
+		return "HS256"
+	}
+
+	return algorithm
+}
+
+// Audience returns the audience claim of the tokens, omitted when empty
+func (c Config) Audience() string {
+	return os.Getenv("JWT_AUDIENCE")
+}
+
+// Issuer returns the issuer claim of the tokens
+func (c Config) Issuer() string {
+	issuer := os.Getenv("JWT_ISSUER")
+	if len(issuer) == 0 {
+		return os.Getenv("API_NAME")
+	}
+
+	return issuer
+}
+
+// PrivateKeyFile returns the PEM encoded private key used by the asymmetric algorithms
+func (c Config) PrivateKeyFile() string {
+	return os.Getenv("JWT_PRIVATE_KEY_FILE")
+}
+
+// RefreshTokenTTL returns the lifetime of issued refresh tokens, renewed on every rotation
+func (c Config) RefreshTokenTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("JWT_REFRESH_TOKEN_TTL"))
+	if err != nil || ttl <= 0 {
+		return 30 * 24 * time.Hour
+	}
+
+	return ttl
+}
+
+// Secret returns the shared secret used by the HMAC algorithms
+func (c Config) Secret() string {
+	return os.Getenv("JWT_SECRET")
+}
@@ -0,0 +1,107 @@
+package password
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config holds the password policy configurations
+type Config struct{}
+
+// Argon2Iterations returns the number of passes over the memory of argon2id hashes
+func (c Config) Argon2Iterations() uint32 {
+	iterations, err := strconv.ParseUint(os.Getenv("PASSWORD_ARGON2_ITERATIONS"), 10, 32)
+	if err != nil || iterations == 0 {
+		return 3
+	}
+
+	return uint32(iterations)
+}
+
+// Argon2Memory returns the memory in KiB used by argon2id hashes
+func (c Config) Argon2Memory() uint32 {
+	memory, err := strconv.ParseUint(os.Getenv("PASSWORD_ARGON2_MEMORY"), 10, 32)
+	if err != nil || memory == 0 {
+		return 64 * 1024
+	}
+
+	return uint32(memory)
+}
+
+// Argon2Parallelism returns the number of threads used by argon2id hashes
+func (c Config) Argon2Parallelism() uint8 {
+	parallelism, err := strconv.ParseUint(os.Getenv("PASSWORD_ARGON2_PARALLELISM"), 10, 8)
+	if err != nil || parallelism == 0 {
+		return 2
+	}
+
+	return uint8(parallelism)
+}
+
+// BannedWords returns the words passwords must not contain in addition to the user email and name
+func (c Config) BannedWords() []string {
+	var words []string
+	for _, word := range strings.Split(os.Getenv("PASSWORD_BANNED_WORDS"), ",") {
+		if word = strings.TrimSpace(word); len(word) > 0 {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+// BcryptCost returns the cost of bcrypt hashes
+func (c Config) BcryptCost() int {
+	cost, err := strconv.Atoi(os.Getenv("PASSWORD_BCRYPT_COST"))
+	if err != nil || cost < 4 || cost > 31 {
+		return 10
+	}
+
+	return cost
+}
+
+// BreachedListFile returns the breached password hash list used instead of the bundled one, empty uses the bundled list
+func (c Config) BreachedListFile() string {
+	return os.Getenv("PASSWORD_BREACHED_LIST_FILE")
+}
+
+// CheckBreached returns whether passwords are checked against the breached password hash list
+func (c Config) CheckBreached() bool {
+	check, err := strconv.ParseBool(os.Getenv("PASSWORD_CHECK_BREACHED"))
+	if err != nil {
+		return true
+	}
+
+	return check
+}
+
+// HashAlgorithm returns the algorithm of new password hashes, either argon2id or bcrypt
+func (c Config) HashAlgorithm() string {
+	algorithm := os.Getenv("PASSWORD_HASH_ALGORITHM")
+	if len(algorithm) == 0 {
+		return "argon2id"
+	}
+
+	return algorithm
+}
+
+// MinCharacterClasses returns how many of lowercase, uppercase, digit and symbol characters passwords must mix
+func (c Config) MinCharacterClasses() int {
+	classes, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_CHARACTER_CLASSES"))
+	if err != nil || classes < 0 || classes > 4 {
+		return 3
+	}
+
+	return classes
+}
+
+// MinLength returns the minimum number of characters of passwords
+func (c Config) MinLength() int {
+	length, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH"))
+	if err != nil || length <= 0 {
+		return 12
+	}
+
+	return length
+}
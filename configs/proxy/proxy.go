@@ -0,0 +1,41 @@
+package proxy
+
+import (
+	"log"
+	"net"
+	"os"
+	"strings"
+)
+
+// Config holds the reverse proxy configurations
+type Config struct{}
+
+// TrustedProxies returns the networks of the reverse proxies allowed to set the client address headers
+// Entries are comma separated IP addresses or CIDR ranges, forwarding headers are ignored when empty
+func (c Config) TrustedProxies() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("[SERVER] ignoring invalid trusted proxy %q", entry)
+			continue
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds the login rate limit configurations
+type Config struct{}
+
+// LoginBackoffBase returns the wait after the first failed login, doubled on every further failure
+func (c Config) LoginBackoffBase() time.Duration {
+	base, err := time.ParseDuration(os.Getenv("LOGIN_BACKOFF_BASE"))
+	if err != nil || base < 0 {
+		return time.Second
+	}
+
+	return base
+}
+
+// LoginIPMaxFailures returns the failed logins from one IP address, across accounts, before it is locked out
+func (c Config) LoginIPMaxFailures() uint {
+	failures, err := strconv.ParseUint(os.Getenv("LOGIN_IP_MAX_FAILURES"), 10, 32)
+	if err != nil || failures == 0 {
+		return 20
+	}
+
+	return uint(failures)
+}
+
+// LoginLockoutDuration returns how long an account or IP address stays locked out
+func (c Config) LoginLockoutDuration() time.Duration {
+	duration, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT_DURATION"))
+	if err != nil || duration <= 0 {
+		return 15 * time.Minute
+	}
+
+	return duration
+}
+
+// LoginMaxFailures returns the failed logins of one account before it is locked out
+func (c Config) LoginMaxFailures() uint {
+	failures, err := strconv.ParseUint(os.Getenv("LOGIN_MAX_FAILURES"), 10, 32)
+	if err != nil || failures == 0 {
+		return 5
+	}
+
+	return uint(failures)
+}
+
+// Store returns the failed attempt store, only memory is built in
+func (c Config) Store() string {
+	store := os.Getenv("RATE_LIMIT_STORE")
+	if len(store) == 0 {
+		return "memory"
+	}
+
+	return store
+}
@@ -0,0 +1,18 @@
+package scope
+
+import (
+	"os"
+)
+
+// Config holds the caller scope configurations
+type Config struct{}
+
+// InternalHeader returns the header carrying the internal service token
+func (c Config) InternalHeader() string {
+	return "X-Internal-Token"
+}
+
+// InternalToken returns the token granting the internal scope, the scope is disabled when empty
+func (c Config) InternalToken() string {
+	return os.Getenv("INTERNAL_API_TOKEN")
+}
@@ -0,0 +1,54 @@
+package signing
+
+import (
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Config holds the wallet signing configurations
+type Config struct{}
+
+// AllowedChainIDs returns list of chain ids that EIP-712 domains are allowed to target
+func (c Config) AllowedChainIDs() []*big.Int {
+	var chainIDs []*big.Int
+	for _, value := range splitList(os.Getenv("SIGNING_ALLOWED_CHAIN_IDS")) {
+		chainID, ok := new(big.Int).SetString(value, 0)
+		if !ok {
+			continue
+		}
+
+		chainIDs = append(chainIDs, chainID)
+	}
+
+	return chainIDs
+}
+
+// AllowedVerifyingContracts returns list of contracts that EIP-712 domains are allowed to target
+func (c Config) AllowedVerifyingContracts() []common.Address {
+	var contracts []common.Address
+	for _, value := range splitList(os.Getenv("SIGNING_ALLOWED_VERIFYING_CONTRACTS")) {
+		if !common.IsHexAddress(value) {
+			continue
+		}
+
+		contracts = append(contracts, common.HexToAddress(value))
+	}
+
+	return contracts
+}
+
+// splitList splits a comma separated env value
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) > 0 {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
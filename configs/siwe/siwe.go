@@ -0,0 +1,50 @@
+package siwe
+
+import (
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config holds the Sign-In With Ethereum configurations
+type Config struct{}
+
+// AllowedChainIDs returns the chains messages may be signed for
+func (c Config) AllowedChainIDs() []*big.Int {
+	var chainIDs []*big.Int
+	for _, value := range strings.Split(os.Getenv("SIWE_ALLOWED_CHAIN_IDS"), ",") {
+		chainID, ok := new(big.Int).SetString(strings.TrimSpace(value), 10)
+		if !ok {
+			continue
+		}
+
+		chainIDs = append(chainIDs, chainID)
+	}
+
+	if len(chainIDs) == 0 {
+		return []*big.Int{big.NewInt(1)}
+	}
+
+	return chainIDs
+}
+
+// Domain returns the domain messages must be issued by, the host and optional port of the client
+func (c Config) Domain() string {
+	domain := os.Getenv("SIWE_DOMAIN")
+	if len(domain) == 0 {
+		return "localhost:3000"
+	}
+
+	return domain
+}
+
+// NonceTTL returns how long a nonce may be used to sign in
+func (c Config) NonceTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("SIWE_NONCE_TTL"))
+	if err != nil || ttl <= 0 {
+		return 5 * time.Minute
+	}
+
+	return ttl
+}
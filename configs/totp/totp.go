@@ -0,0 +1,21 @@
+package totp
+
+import (
+	"os"
+)
+
+// Config holds the time-based one-time password (TOTP) configurations
+type Config struct{}
+
+// Issuer returns the issuer shown by authenticator apps
+func (c Config) Issuer() string {
+	issuer := os.Getenv("TOTP_ISSUER")
+	if len(issuer) == 0 {
+		issuer = os.Getenv("API_NAME")
+	}
+	if len(issuer) == 0 {
+		return "Celeste"
+	}
+
+	return issuer
+}
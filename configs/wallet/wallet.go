@@ -0,0 +1,34 @@
+package wallet
+
+import (
+	"os"
+	"strconv"
+)
+
+// Config holds the wallet Shamir Secret Sharing (SSS) configurations
+type Config struct{}
+
+// MaxShareCount returns the maximum number of shares a wallet can be split into
+func (c Config) MaxShareCount() int {
+	return 10
+}
+
+// ShareCount returns the default number of shares, including the server share, a wallet is split into
+func (c Config) ShareCount() int {
+	count, err := strconv.Atoi(os.Getenv("SSS_SHARE_COUNT"))
+	if err != nil || count < 2 {
+		return 3 // default is 2 of 3
+	}
+
+	return count
+}
+
+// ShareThreshold returns the default number of shares required to reconstruct a wallet
+func (c Config) ShareThreshold() int {
+	threshold, err := strconv.Atoi(os.Getenv("SSS_SHARE_THRESHOLD"))
+	if err != nil || threshold < 2 {
+		return 2 // default is 2 of 3
+	}
+
+	return threshold
+}
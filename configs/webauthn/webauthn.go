@@ -0,0 +1,70 @@
+package webauthn
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// Config holds the WebAuthn relying party configurations
+type Config struct{}
+
+// ChallengeTTL returns how long a registration or login ceremony may take
+func (c Config) ChallengeTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("WEBAUTHN_CHALLENGE_TTL"))
+	if err != nil || ttl <= 0 {
+		return 5 * time.Minute
+	}
+
+	return ttl
+}
+
+// Origins returns the origins the ceremonies may run on, e.g. https://app.example.com
+func (c Config) Origins() []string {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("WEBAUTHN_ORIGINS"), ",") {
+		origin = strings.TrimSpace(origin)
+		if len(origin) > 0 {
+			origins = append(origins, origin)
+		}
+	}
+
+	if len(origins) == 0 {
+		return []string{"http://localhost:3000"}
+	}
+
+	return origins
+}
+
+// RPID returns the relying party ID, the domain the credentials are scoped to
+func (c Config) RPID() string {
+	rpID := os.Getenv("WEBAUTHN_RP_ID")
+	if len(rpID) == 0 {
+		return "localhost"
+	}
+
+	return rpID
+}
+
+// RPName returns the relying party name shown by authenticators
+func (c Config) RPName() string {
+	name := os.Getenv("WEBAUTHN_RP_NAME")
+	if len(name) == 0 {
+		name = os.Getenv("API_NAME")
+	}
+	if len(name) == 0 {
+		return "Celeste"
+	}
+
+	return name
+}
+
+// UserVerification returns the user verification requirement, one of required, preferred or discouraged
+func (c Config) UserVerification() string {
+	switch requirement := os.Getenv("WEBAUTHN_USER_VERIFICATION"); requirement {
+	case "required", "discouraged":
+		return requirement
+	default:
+		return "preferred"
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `user_shares`;
+
+ALTER TABLE `users` DROP COLUMN `share_threshold`, DROP COLUMN `share_count`;
\ No newline at end of file
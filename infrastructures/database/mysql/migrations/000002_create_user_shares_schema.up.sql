@@ -0,0 +1,26 @@
+ALTER TABLE
+    `users`
+ADD
+    COLUMN `share_threshold` tinyint unsigned NOT NULL DEFAULT 2 AFTER `sss_1`,
+ADD
+    COLUMN `share_count` tinyint unsigned NOT NULL DEFAULT 3 AFTER `share_threshold`;
+
+CREATE TABLE
+    `user_shares` (
+        `wallet_address` varchar(42) NOT NULL,
+        `share_index` tinyint unsigned NOT NULL,
+        `holder_type` varchar(20) NOT NULL,
+        `metadata` json NULL DEFAULT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`wallet_address`, `share_index`),
+        CONSTRAINT `user_shares_wallet_address_foreign` FOREIGN KEY (`wallet_address`) REFERENCES `users` (`wallet_address`) ON DELETE CASCADE
+ );
+
+-- existing users were split 2 of 3: server (sss_1), device (sss2) and backup (sss3)
+INSERT INTO
+    `user_shares` (`wallet_address`, `share_index`, `holder_type`)
+SELECT `wallet_address`, 1, 'server' FROM `users`
+UNION ALL
+SELECT `wallet_address`, 2, 'device' FROM `users`
+UNION ALL
+SELECT `wallet_address`, 3, 'backup' FROM `users`;
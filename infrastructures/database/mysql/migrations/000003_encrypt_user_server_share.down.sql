@@ -0,0 +1 @@
+ALTER TABLE `users` DROP COLUMN `sss_1_dek`, DROP COLUMN `sss_1_key_id`, MODIFY COLUMN `sss_1` varchar(100) NOT NULL;
\ No newline at end of file
@@ -0,0 +1,8 @@
+ALTER TABLE
+    `users`
+MODIFY
+    COLUMN `sss_1` varchar(255) NOT NULL,
+ADD
+    COLUMN `sss_1_dek` varchar(255) NULL DEFAULT NULL AFTER `sss_1`,
+ADD
+    COLUMN `sss_1_key_id` varchar(64) NULL DEFAULT NULL AFTER `sss_1_dek`;
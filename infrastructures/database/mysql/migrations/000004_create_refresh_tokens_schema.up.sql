@@ -0,0 +1,14 @@
+CREATE TABLE
+    `refresh_tokens` (
+        `id` varchar(27) NOT NULL,
+        `family_id` varchar(27) NOT NULL,
+        `wallet_address` varchar(42) NOT NULL,
+        `token_hash` char(64) NOT NULL UNIQUE,
+        `expires_at` timestamp NOT NULL,
+        `rotated_at` timestamp NULL DEFAULT NULL,
+        `revoked_at` timestamp NULL DEFAULT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`id`),
+        KEY `refresh_tokens_family_id_index` (`family_id`),
+        CONSTRAINT `refresh_tokens_wallet_address_foreign` FOREIGN KEY (`wallet_address`) REFERENCES `users` (`wallet_address`) ON DELETE CASCADE
+ );
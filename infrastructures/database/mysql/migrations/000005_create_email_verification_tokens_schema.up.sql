@@ -0,0 +1,11 @@
+CREATE TABLE
+    `email_verification_tokens` (
+        `id` varchar(27) NOT NULL,
+        `wallet_address` varchar(42) NOT NULL,
+        `token_hash` char(64) NOT NULL UNIQUE,
+        `expires_at` timestamp NOT NULL,
+        `used_at` timestamp NULL DEFAULT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`id`),
+        CONSTRAINT `email_verification_tokens_wallet_address_foreign` FOREIGN KEY (`wallet_address`) REFERENCES `users` (`wallet_address`) ON DELETE CASCADE
+ );
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `user_recovery_codes`;
+
+DROP TABLE IF EXISTS `user_totp`;
\ No newline at end of file
@@ -0,0 +1,24 @@
+CREATE TABLE
+    `user_totp` (
+        `wallet_address` varchar(42) NOT NULL,
+        `secret` varchar(255) NOT NULL,
+        `secret_dek` varchar(255) NOT NULL,
+        `secret_key_id` varchar(64) NOT NULL,
+        `last_used_step` bigint NULL DEFAULT NULL,
+        `confirmed_at` timestamp NULL DEFAULT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`wallet_address`),
+        CONSTRAINT `user_totp_wallet_address_foreign` FOREIGN KEY (`wallet_address`) REFERENCES `users` (`wallet_address`) ON DELETE CASCADE
+ );
+
+CREATE TABLE
+    `user_recovery_codes` (
+        `id` varchar(27) NOT NULL,
+        `wallet_address` varchar(42) NOT NULL,
+        `code_hash` char(64) NOT NULL,
+        `used_at` timestamp NULL DEFAULT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`id`),
+        UNIQUE KEY `user_recovery_codes_wallet_address_code_hash_unique` (`wallet_address`, `code_hash`),
+        CONSTRAINT `user_recovery_codes_wallet_address_foreign` FOREIGN KEY (`wallet_address`) REFERENCES `users` (`wallet_address`) ON DELETE CASCADE
+ );
@@ -0,0 +1,27 @@
+CREATE TABLE
+    `webauthn_credentials` (
+        `id` varchar(27) NOT NULL,
+        `wallet_address` varchar(42) NOT NULL,
+        `credential_id` varchar(1400) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+        `public_key` blob NOT NULL,
+        `sign_count` int unsigned NOT NULL DEFAULT 0,
+        `name` varchar(255) NOT NULL,
+        `last_used_at` timestamp NULL DEFAULT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`id`),
+        UNIQUE KEY `webauthn_credentials_credential_id_unique` (`credential_id`),
+        KEY `webauthn_credentials_wallet_address_index` (`wallet_address`),
+        CONSTRAINT `webauthn_credentials_wallet_address_foreign` FOREIGN KEY (`wallet_address`) REFERENCES `users` (`wallet_address`) ON DELETE CASCADE
+ );
+
+CREATE TABLE
+    `webauthn_challenges` (
+        `id` varchar(27) NOT NULL,
+        `wallet_address` varchar(42) NULL DEFAULT NULL,
+        `ceremony` varchar(16) NOT NULL,
+        `challenge_hash` char(64) NOT NULL UNIQUE,
+        `expires_at` timestamp NOT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`id`),
+        CONSTRAINT `webauthn_challenges_wallet_address_foreign` FOREIGN KEY (`wallet_address`) REFERENCES `users` (`wallet_address`) ON DELETE CASCADE
+ );
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `siwe_nonces`;
\ No newline at end of file
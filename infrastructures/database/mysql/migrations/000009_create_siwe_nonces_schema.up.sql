@@ -0,0 +1,8 @@
+CREATE TABLE
+    `siwe_nonces` (
+        `id` varchar(27) NOT NULL,
+        `nonce_hash` char(64) NOT NULL UNIQUE,
+        `expires_at` timestamp NOT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`id`)
+ );
@@ -0,0 +1,60 @@
+CREATE TABLE
+    `roles` (
+        `name` varchar(32) NOT NULL,
+        `description` varchar(255) NOT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`name`)
+ );
+
+CREATE TABLE
+    `permissions` (
+        `name` varchar(64) NOT NULL,
+        `description` varchar(255) NOT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`name`)
+ );
+
+CREATE TABLE
+    `role_permissions` (
+        `role` varchar(32) NOT NULL,
+        `permission` varchar(64) NOT NULL,
+        PRIMARY KEY (`role`, `permission`),
+        CONSTRAINT `role_permissions_role_foreign` FOREIGN KEY (`role`) REFERENCES `roles` (`name`) ON DELETE CASCADE,
+        CONSTRAINT `role_permissions_permission_foreign` FOREIGN KEY (`permission`) REFERENCES `permissions` (`name`) ON DELETE CASCADE
+ );
+
+CREATE TABLE
+    `user_roles` (
+        `wallet_address` varchar(42) NOT NULL,
+        `role` varchar(32) NOT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`wallet_address`, `role`),
+        CONSTRAINT `user_roles_wallet_address_foreign` FOREIGN KEY (`wallet_address`) REFERENCES `users` (`wallet_address`) ON DELETE CASCADE,
+        CONSTRAINT `user_roles_role_foreign` FOREIGN KEY (`role`) REFERENCES `roles` (`name`) ON DELETE CASCADE
+ );
+
+INSERT INTO
+    `roles` (`name`, `description`)
+VALUES
+    ('admin', 'Manages users and their roles'),
+    ('service', 'Backend service reading users');
+
+INSERT INTO
+    `permissions` (`name`, `description`)
+VALUES
+    ('roles:manage', 'Grant and revoke roles'),
+    ('users:deactivate', 'Deactivate any user'),
+    ('users:list', 'List users'),
+    ('users:read', 'View any user'),
+    ('users:update', 'Update any user');
+
+INSERT INTO
+    `role_permissions` (`role`, `permission`)
+VALUES
+    ('admin', 'roles:manage'),
+    ('admin', 'users:deactivate'),
+    ('admin', 'users:list'),
+    ('admin', 'users:read'),
+    ('admin', 'users:update'),
+    ('service', 'users:list'),
+    ('service', 'users:read');
@@ -0,0 +1,3 @@
+DELETE FROM `permissions` WHERE `name` = 'api_keys:manage';
+
+DROP TABLE IF EXISTS `api_keys`;
\ No newline at end of file
@@ -0,0 +1,25 @@
+CREATE TABLE
+    `api_keys` (
+        `id` varchar(27) NOT NULL,
+        `name` varchar(255) NOT NULL,
+        `prefix` char(12) NOT NULL UNIQUE,
+        `key_hash` char(64) NOT NULL,
+        `scopes` varchar(1024) NOT NULL,
+        `created_by` varchar(42) NULL DEFAULT NULL,
+        `expires_at` timestamp NULL DEFAULT NULL,
+        `last_used_at` timestamp NULL DEFAULT NULL,
+        `revoked_at` timestamp NULL DEFAULT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`id`),
+        CONSTRAINT `api_keys_created_by_foreign` FOREIGN KEY (`created_by`) REFERENCES `users` (`wallet_address`) ON DELETE SET NULL
+ );
+
+INSERT INTO
+    `permissions` (`name`, `description`)
+VALUES
+    ('api_keys:manage', 'Create, list and revoke API keys');
+
+INSERT INTO
+    `role_permissions` (`role`, `permission`)
+VALUES
+    ('admin', 'api_keys:manage');
@@ -0,0 +1,36 @@
+CREATE TABLE
+    `sessions` (
+        `id` varchar(27) NOT NULL,
+        `wallet_address` varchar(42) NOT NULL,
+        `device` varchar(255) NOT NULL,
+        `ip_address` varchar(45) NOT NULL,
+        `user_agent` varchar(512) NOT NULL,
+        `last_seen_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        `expires_at` timestamp NOT NULL,
+        `revoked_at` timestamp NULL DEFAULT NULL,
+        `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (`id`),
+        KEY `sessions_wallet_address_index` (`wallet_address`),
+        CONSTRAINT `sessions_wallet_address_foreign` FOREIGN KEY (`wallet_address`) REFERENCES `users` (`wallet_address`) ON DELETE CASCADE
+ );
+
+INSERT INTO
+    `sessions` (`id`, `wallet_address`, `device`, `ip_address`, `user_agent`, `last_seen_at`, `expires_at`, `created_at`)
+SELECT
+    `family_id`,
+    `wallet_address`,
+    'Unknown device',
+    '',
+    '',
+    MAX(`created_at`),
+    MAX(`expires_at`),
+    MIN(`created_at`)
+FROM
+    `refresh_tokens`
+WHERE
+    `revoked_at` IS NULL
+GROUP BY
+    `family_id`,
+    `wallet_address`
+HAVING
+    MAX(`expires_at`) > NOW();
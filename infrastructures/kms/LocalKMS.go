@@ -0,0 +1,252 @@
+package kms
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"celeste/infrastructures/kms/types"
+)
+
+// LocalKMS handles a software KMS for development and tests
+// Keys are AES-256-GCM keys stored by version in a JSON key file, or key encryption keys passed through the environment
+type LocalKMS struct {
+	keyFile     string
+	keys        map[string][]byte
+	activeKeyID string
+	latest      int // latest version of the key file, zero for environment keys
+	mu          sync.RWMutex
+}
+
+type localKeyFile struct {
+	Latest int            `json:"latest"`
+	Keys   map[int]string `json:"keys"`
+}
+
+const localPrefix = "local"
+
+// ActiveKeyID returns the id of the key version used for new encryptions
+func (k *LocalKMS) ActiveKeyID() (string, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return localPrefix + ":" + k.activeKeyID, nil
+}
+
+// Decrypt decrypts the ciphertext with the key version that encrypted it
+func (k *LocalKMS) Decrypt(ciphertext string) ([]byte, error) {
+	parts := strings.SplitN(ciphertext, ":", 3)
+	if len(parts) != 3 || parts[0] != localPrefix {
+		return nil, errors.New("invalid local kms ciphertext")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.RLock()
+	key, ok := k.keys[parts[1]]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("local kms key %q not found", parts[1])
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, sealed, []byte(parts[1]))
+}
+
+// Encrypt encrypts the plaintext with the active key version
+func (k *LocalKMS) Encrypt(plaintext []byte) (string, error) {
+	k.mu.RLock()
+	keyID := k.activeKeyID
+	key := k.keys[keyID]
+	k.mu.RUnlock()
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(keyID))
+
+	return fmt.Sprintf("%s:%s:%s", localPrefix, keyID, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// GenerateDataKey generates a 256-bit data encryption key returning its plaintext and encrypted form
+func (k *LocalKMS) GenerateDataKey() (types.DataKey, error) {
+	plaintext := make([]byte, 32)
+	if _, err := rand.Read(plaintext); err != nil {
+		return types.DataKey{}, err
+	}
+
+	ciphertext, err := k.Encrypt(plaintext)
+	if err != nil {
+		clear(plaintext)
+		return types.DataKey{}, err
+	}
+
+	return types.DataKey{
+		Plaintext:  plaintext,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+// Load reads the environment keys when given, otherwise the key file
+// A first key version is generated if the key file does not exist
+func (k *LocalKMS) Load(params types.LocalKMSParams) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keyFile = params.KeyFile
+	k.keys = map[string][]byte{}
+	k.activeKeyID = ""
+	k.latest = 0
+
+	if len(params.Keys) > 0 {
+		return k.loadKeys(params.Keys, params.ActiveKeyID)
+	}
+
+	if len(params.KeyFile) == 0 {
+		return errors.New("local kms keys or key file are required")
+	}
+
+	content, err := os.ReadFile(params.KeyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return k.rotate()
+	} else if err != nil {
+		return err
+	}
+
+	var keyFile localKeyFile
+	if err := json.Unmarshal(content, &keyFile); err != nil {
+		return err
+	}
+
+	for version, encodedKey := range keyFile.Keys {
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("local kms key version %d must be a base64 encoded 32 byte key", version)
+		}
+
+		k.keys[fmt.Sprintf("v%d", version)] = key
+	}
+
+	k.activeKeyID = fmt.Sprintf("v%d", keyFile.Latest)
+	if _, ok := k.keys[k.activeKeyID]; !ok {
+		return fmt.Errorf("local kms key version %d not found", keyFile.Latest)
+	}
+	k.latest = keyFile.Latest
+
+	return nil
+}
+
+// RotateKey creates a new key version used for subsequent encryptions
+// Environment keys are rotated by adding a key and pointing the active key id to it instead
+func (k *LocalKMS) RotateKey() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.latest == 0 && len(k.keys) > 0 {
+		return errors.New("local kms environment keys cannot be rotated, add a key and point the active key id to it")
+	}
+
+	return k.rotate()
+}
+
+// loadKeys parses the comma separated <id>:<base64 32 byte key> pairs, the lock must be held
+func (k *LocalKMS) loadKeys(keys, activeKeyID string) error {
+	for _, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+
+		keyID, encodedKey, found := strings.Cut(entry, ":")
+		if !found || len(keyID) == 0 {
+			return fmt.Errorf("invalid local kms key entry %q", keyID)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("local kms key %q must be a base64 encoded 32 byte key", keyID)
+		}
+
+		k.keys[keyID] = key
+	}
+
+	if _, ok := k.keys[activeKeyID]; !ok {
+		return fmt.Errorf("local kms active key %q not found", activeKeyID)
+	}
+	k.activeKeyID = activeKeyID
+
+	return nil
+}
+
+// rotate generates the next key version and persists the key file, the lock must be held
+func (k *LocalKMS) rotate() error {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+
+	keyFile := localKeyFile{
+		Latest: k.latest + 1,
+		Keys:   map[int]string{},
+	}
+	for keyID, existing := range k.keys {
+		var version int
+		fmt.Sscanf(keyID, "v%d", &version)
+		keyFile.Keys[version] = base64.StdEncoding.EncodeToString(existing)
+	}
+	keyFile.Keys[keyFile.Latest] = base64.StdEncoding.EncodeToString(key)
+
+	content, err := json.MarshalIndent(keyFile, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write then rename so a crash never leaves a partial key file
+	err = os.MkdirAll(filepath.Dir(k.keyFile), 0o700)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := k.keyFile + ".tmp"
+	err = os.WriteFile(tmpFile, content, 0o600)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpFile, k.keyFile)
+	if err != nil {
+		return err
+	}
+
+	k.activeKeyID = fmt.Sprintf("v%d", keyFile.Latest)
+	k.keys[k.activeKeyID] = key
+	k.latest = keyFile.Latest
+
+	return nil
+}
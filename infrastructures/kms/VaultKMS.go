@@ -0,0 +1,171 @@
+package kms
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"celeste/infrastructures/kms/types"
+)
+
+// VaultKMS handles the HashiCorp Vault transit secrets engine as KMS
+// Transit ciphertexts already follow the vault:v<version>:<data> format
+type VaultKMS struct {
+	address string
+	token   string
+	mount   string
+	keyName string
+	client  *http.Client
+}
+
+type vaultResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []string        `json:"errors"`
+}
+
+// ActiveKeyID returns the id of the key version used for new encryptions
+func (k *VaultKMS) ActiveKeyID() (string, error) {
+	var data struct {
+		LatestVersion int `json:"latest_version"`
+	}
+
+	err := k.request(http.MethodGet, "keys/"+url.PathEscape(k.keyName), nil, &data)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("vault:v%d", data.LatestVersion), nil
+}
+
+// Connect sets up the Vault client and verifies the transit key is reachable
+func (k *VaultKMS) Connect(params types.VaultKMSParams) error {
+	if len(params.Address) == 0 || len(params.Token) == 0 || len(params.KeyName) == 0 {
+		return errors.New("vault address, token and transit key are required")
+	}
+
+	k.address = strings.TrimRight(params.Address, "/")
+	k.token = params.Token
+	k.mount = strings.Trim(params.Mount, "/")
+	if len(k.mount) == 0 {
+		k.mount = "transit"
+	}
+	k.keyName = params.KeyName
+	k.client = &http.Client{Timeout: 10 * time.Second}
+
+	_, err := k.ActiveKeyID()
+
+	return err
+}
+
+// Decrypt decrypts the ciphertext with the key version that encrypted it
+func (k *VaultKMS) Decrypt(ciphertext string) ([]byte, error) {
+	var data struct {
+		Plaintext string `json:"plaintext"`
+	}
+
+	err := k.request(http.MethodPost, "decrypt/"+url.PathEscape(k.keyName), map[string]string{
+		"ciphertext": ciphertext,
+	}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(data.Plaintext)
+}
+
+// Encrypt encrypts the plaintext with the active key version
+func (k *VaultKMS) Encrypt(plaintext []byte) (string, error) {
+	var data struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+
+	err := k.request(http.MethodPost, "encrypt/"+url.PathEscape(k.keyName), map[string]string{
+		"plaintext": base64.StdEncoding.EncodeToString(plaintext),
+	}, &data)
+	if err != nil {
+		return "", err
+	}
+
+	return data.Ciphertext, nil
+}
+
+// GenerateDataKey generates a 256-bit data encryption key returning its plaintext and encrypted form
+func (k *VaultKMS) GenerateDataKey() (types.DataKey, error) {
+	var data struct {
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+	}
+
+	err := k.request(http.MethodPost, "datakey/plaintext/"+url.PathEscape(k.keyName), map[string]int{
+		"bits": 256,
+	}, &data)
+	if err != nil {
+		return types.DataKey{}, err
+	}
+
+	plaintext, err := base64.StdEncoding.DecodeString(data.Plaintext)
+	if err != nil {
+		return types.DataKey{}, err
+	}
+
+	return types.DataKey{
+		Plaintext:  plaintext,
+		Ciphertext: data.Ciphertext,
+	}, nil
+}
+
+// RotateKey creates a new key version used for subsequent encryptions
+func (k *VaultKMS) RotateKey() error {
+	return k.request(http.MethodPost, "keys/"+url.PathEscape(k.keyName)+"/rotate", nil, nil)
+}
+
+// request calls the transit endpoint and decodes the response data into out
+func (k *VaultKMS) request(method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/v1/%s/%s", k.address, k.mount, path), reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", k.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	var response vaultResponse
+	if res.StatusCode != http.StatusNoContent {
+		err = json.NewDecoder(res.Body).Decode(&response)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("vault transit %s failed with status %d: %s", path, res.StatusCode, strings.Join(response.Errors, "; "))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.Unmarshal(response.Data, out)
+}
@@ -0,0 +1,115 @@
+package kms
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"celeste/infrastructures/kms/types"
+)
+
+// KeyID returns the key version id of a self describing ciphertext
+func KeyID(ciphertext string) string {
+	parts := strings.SplitN(ciphertext, ":", 3)
+	if len(parts) != 3 {
+		return ""
+	}
+
+	return parts[0] + ":" + parts[1]
+}
+
+// Open decrypts the envelope by unwrapping its data encryption key with the KMS
+func Open(provider types.KMSProviderInterface, envelope types.Envelope) ([]byte, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	dek, err := provider.Decrypt(wrappedKey(envelope))
+	if err != nil {
+		return nil, err
+	}
+	defer clear(dek)
+
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, sealed, nil)
+}
+
+// Rewrap re-encrypts the data encryption key of the envelope with the active KMS key
+// The ciphertext is left untouched
+func Rewrap(provider types.KMSProviderInterface, envelope types.Envelope) (types.Envelope, error) {
+	dek, err := provider.Decrypt(wrappedKey(envelope))
+	if err != nil {
+		return types.Envelope{}, err
+	}
+	defer clear(dek)
+
+	wrappedKey, err := provider.Encrypt(dek)
+	if err != nil {
+		return types.Envelope{}, err
+	}
+
+	return types.Envelope{
+		Ciphertext: envelope.Ciphertext,
+		WrappedKey: wrappedKey,
+		KeyID:      KeyID(wrappedKey),
+	}, nil
+}
+
+// Seal encrypts the plaintext with a data encryption key generated by the KMS
+func Seal(provider types.KMSProviderInterface, plaintext []byte) (types.Envelope, error) {
+	dataKey, err := provider.GenerateDataKey()
+	if err != nil {
+		return types.Envelope{}, err
+	}
+	defer clear(dataKey.Plaintext)
+
+	aead, err := newAEAD(dataKey.Plaintext)
+	if err != nil {
+		return types.Envelope{}, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return types.Envelope{}, err
+	}
+
+	return types.Envelope{
+		Ciphertext: base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, nil)),
+		WrappedKey: dataKey.Ciphertext,
+		KeyID:      KeyID(dataKey.Ciphertext),
+	}, nil
+}
+
+// wrappedKey returns the self describing wrapped key of the envelope
+// Envelopes sealed with the former key encryption keys store the key id apart from the wrapped key, they open with the same keys as local KMS environment keys
+func wrappedKey(envelope types.Envelope) string {
+	if !strings.Contains(envelope.KeyID, ":") && !strings.Contains(envelope.WrappedKey, ":") {
+		return localPrefix + ":" + envelope.KeyID + ":" + envelope.WrappedKey
+	}
+
+	return envelope.WrappedKey
+}
+
+// newAEAD creates the AES-GCM cipher of the key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
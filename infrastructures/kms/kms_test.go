@@ -0,0 +1,255 @@
+package kms
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"celeste/infrastructures/kms/types"
+)
+
+func TestLocalKMSRotateRewrap(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "kms.json")
+
+	provider := &LocalKMS{}
+	err := provider.Load(types.LocalKMSParams{KeyFile: keyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envelope, err := Seal(provider, []byte("server share"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envelope.KeyID != "local:v1" {
+		t.Errorf("unexpected key id %s", envelope.KeyID)
+	}
+
+	if err := provider.RotateKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a reloaded provider must still open envelopes under the previous version
+	reloaded := &LocalKMS{}
+	err = reloaded.Load(types.LocalKMSParams{KeyFile: keyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	activeKeyID, _ := reloaded.ActiveKeyID()
+	if activeKeyID != "local:v2" {
+		t.Errorf("unexpected active key id %s", activeKeyID)
+	}
+
+	rewrapped, err := Rewrap(reloaded, envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rewrapped.KeyID != activeKeyID || rewrapped.Ciphertext != envelope.Ciphertext {
+		t.Errorf("unexpected rewrapped envelope %+v", rewrapped)
+	}
+
+	plaintext, err := Open(reloaded, rewrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "server share" {
+		t.Errorf("unexpected plaintext %q", plaintext)
+	}
+}
+
+func newTestKey(t *testing.T) string {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestLocalKMSEnvironmentKeysRewrap(t *testing.T) {
+	oldKey, newKey := newTestKey(t), newTestKey(t)
+
+	provider := &LocalKMS{}
+	err := provider.Load(types.LocalKMSParams{
+		Keys:        fmt.Sprintf("v1:%s", oldKey),
+		ActiveKeyID: "v1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envelope, err := Seal(provider, []byte("server share"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := provider.RotateKey(); err == nil {
+		t.Error("expected rotating environment keys to fail")
+	}
+
+	// rotate to a new active key while keeping the old one for decryption
+	err = provider.Load(types.LocalKMSParams{
+		Keys:        fmt.Sprintf("v1:%s,v2:%s", oldKey, newKey),
+		ActiveKeyID: "v2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rewrapped, err := Rewrap(provider, envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rewrapped.KeyID != "local:v2" || rewrapped.Ciphertext != envelope.Ciphertext {
+		t.Errorf("unexpected rewrapped envelope %+v", rewrapped)
+	}
+
+	// the old key is no longer needed after rewrapping
+	err = provider.Load(types.LocalKMSParams{
+		Keys:        fmt.Sprintf("v2:%s", newKey),
+		ActiveKeyID: "v2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := Open(provider, rewrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "server share" {
+		t.Errorf("unexpected plaintext %q", plaintext)
+	}
+
+	if _, err := Open(provider, envelope); err == nil {
+		t.Error("expected envelope under the removed key to fail")
+	}
+}
+
+func TestLocalKMSOpensKEKEnvelopes(t *testing.T) {
+	provider := &LocalKMS{}
+	err := provider.Load(types.LocalKMSParams{
+		Keys:        fmt.Sprintf("2024:%s", newTestKey(t)),
+		ActiveKeyID: "2024",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envelope, err := Seal(provider, []byte("server share"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// envelopes of the former key encryption keys store the bare key id and wrapped key
+	legacy := types.Envelope{
+		Ciphertext: envelope.Ciphertext,
+		WrappedKey: strings.TrimPrefix(envelope.WrappedKey, "local:2024:"),
+		KeyID:      "2024",
+	}
+
+	plaintext, err := Open(provider, legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "server share" {
+		t.Errorf("unexpected plaintext %q", plaintext)
+	}
+
+	rewrapped, err := Rewrap(provider, legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rewrapped.KeyID != "local:2024" {
+		t.Errorf("unexpected rewrapped key id %s", rewrapped.KeyID)
+	}
+}
+
+func TestVaultKMS(t *testing.T) {
+	// transit stand-in that "encrypts" by tagging the base64 plaintext with the key version
+	version := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+			return
+		}
+
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		var data interface{}
+		switch r.URL.Path {
+		case "/v1/transit/keys/celeste":
+			data = map[string]int{"latest_version": version}
+		case "/v1/transit/keys/celeste/rotate":
+			version++
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case "/v1/transit/encrypt/celeste":
+			data = map[string]string{"ciphertext": fmt.Sprintf("vault:v%d:%s", version, body["plaintext"].(string))}
+		case "/v1/transit/decrypt/celeste":
+			parts := strings.SplitN(body["ciphertext"].(string), ":", 3)
+			data = map[string]string{"plaintext": parts[2]}
+		case "/v1/transit/datakey/plaintext/celeste":
+			plaintext := "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+			data = map[string]string{"plaintext": plaintext, "ciphertext": fmt.Sprintf("vault:v%d:%s", version, plaintext)}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer server.Close()
+
+	provider := &VaultKMS{}
+	err := provider.Connect(types.VaultKMSParams{
+		Address: server.URL,
+		Token:   "token",
+		Mount:   "transit",
+		KeyName: "celeste",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envelope, err := Seal(provider, []byte("server share"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envelope.KeyID != "vault:v1" {
+		t.Errorf("unexpected key id %s", envelope.KeyID)
+	}
+
+	if err := provider.RotateKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	rewrapped, err := Rewrap(provider, envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rewrapped.KeyID != "vault:v2" {
+		t.Errorf("unexpected rewrapped key id %s", rewrapped.KeyID)
+	}
+
+	plaintext, err := Open(provider, rewrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "server share" {
+		t.Errorf("unexpected plaintext %q", plaintext)
+	}
+
+	if err := (&VaultKMS{}).Connect(types.VaultKMSParams{Address: server.URL, Token: "wrong", KeyName: "celeste"}); err == nil {
+		t.Error("expected connect with an invalid token to fail")
+	}
+}
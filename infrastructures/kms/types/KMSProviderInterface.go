@@ -0,0 +1,16 @@
+package types
+
+// KMSProviderInterface contains the implementable methods for the key management service (KMS) provider
+// Ciphertexts are self describing as <provider>:v<version>:<data> so they can be decrypted after key rotations
+type KMSProviderInterface interface {
+	// ActiveKeyID returns the id of the key version used for new encryptions
+	ActiveKeyID() (string, error)
+	// Decrypt decrypts the ciphertext with the key version that encrypted it
+	Decrypt(ciphertext string) ([]byte, error)
+	// Encrypt encrypts the plaintext with the active key version
+	Encrypt(plaintext []byte) (string, error)
+	// GenerateDataKey generates a 256-bit data encryption key returning its plaintext and encrypted form
+	GenerateDataKey() (DataKey, error)
+	// RotateKey creates a new key version used for subsequent encryptions
+	RotateKey() error
+}
@@ -0,0 +1,25 @@
+package types
+
+type DataKey struct {
+	Plaintext  []byte // must be cleared after use
+	Ciphertext string // data key encrypted with the KMS key
+}
+
+type Envelope struct {
+	Ciphertext string // base64 data encrypted with the data encryption key (DEK)
+	WrappedKey string // DEK encrypted with the KMS key
+	KeyID      string // KMS key version that wrapped the DEK
+}
+
+type LocalKMSParams struct {
+	Keys        string // comma separated <id>:<base64 32 byte key> pairs, used instead of the key file when set
+	ActiveKeyID string // id of the key of Keys used for new encryptions
+	KeyFile     string // created with a first key version if missing
+}
+
+type VaultKMSParams struct {
+	Address string
+	Token   string
+	Mount   string // transit secrets engine mount path
+	KeyName string
+}
@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/segmentio/ksuid"
+
+	"celeste/infrastructures/mailer/types"
+)
+
+// FileMailer handles the mailer that drops messages as .eml files for development
+type FileMailer struct {
+	params types.FileParams
+}
+
+// Load prepares the directory the messages are written to
+func (m *FileMailer) Load(params types.FileParams) error {
+	if len(params.Directory) == 0 || len(params.From) == 0 {
+		return errors.New("mail directory and sender are required")
+	}
+
+	err := os.MkdirAll(params.Directory, 0o700)
+	if err != nil {
+		return err
+	}
+
+	m.params = params
+
+	return nil
+}
+
+// Send delivers the message to its recipients
+func (m *FileMailer) Send(message types.Message) error {
+	content, err := buildMessage(m.params.From, message)
+	if err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405"), ksuid.New().String())
+
+	return os.WriteFile(filepath.Join(m.params.Directory, fileName), content, 0o600)
+}
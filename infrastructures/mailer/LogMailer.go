@@ -0,0 +1,19 @@
+package mailer
+
+import (
+	"log"
+	"strings"
+
+	"celeste/infrastructures/mailer/types"
+)
+
+// LogMailer handles the mailer that writes messages to the log instead of sending them
+// Only the text body is logged, the HTML body carries the same content
+type LogMailer struct{}
+
+// Send delivers the message to its recipients
+func (m *LogMailer) Send(message types.Message) error {
+	log.Printf("[MAILER] to: %s subject: %s\n%s", strings.Join(message.To, ", "), message.Subject, message.TextBody)
+
+	return nil
+}
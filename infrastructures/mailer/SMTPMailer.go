@@ -0,0 +1,128 @@
+package mailer
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/smtp"
+	"time"
+
+	"celeste/infrastructures/mailer/types"
+)
+
+// SMTPMailer handles the mailer that sends messages through an SMTP server
+type SMTPMailer struct {
+	params types.SMTPParams
+}
+
+// Connect validates the SMTP server parameters
+func (m *SMTPMailer) Connect(params types.SMTPParams) error {
+	if len(params.Host) == 0 || len(params.Port) == 0 || len(params.From) == 0 {
+		return errors.New("smtp host, port and sender are required")
+	}
+
+	m.params = params
+
+	return nil
+}
+
+// Send delivers the message to its recipients
+func (m *SMTPMailer) Send(message types.Message) error {
+	content, err := buildMessage(m.params.From, message)
+	if err != nil {
+		return err
+	}
+
+	from, err := recipientAddresses([]string{m.params.From})
+	if err != nil {
+		return err
+	}
+
+	to, err := recipientAddresses(message.To)
+	if err != nil {
+		return err
+	}
+
+	client, err := m.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	// upgrade the connection when the server offers it
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: m.params.Host})
+		if err != nil {
+			return err
+		}
+	}
+
+	// plain auth refuses to send credentials over an unencrypted connection except to localhost
+	if len(m.params.Username) > 0 {
+		err = client.Auth(smtp.PlainAuth("", m.params.Username, m.params.Password, m.params.Host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = client.Mail(from[0])
+	if err != nil {
+		return err
+	}
+
+	for _, recipient := range to {
+		err = client.Rcpt(recipient)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(content)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// dial opens the SMTP connection, port 465 uses implicit TLS
+func (m *SMTPMailer) dial() (*smtp.Client, error) {
+	address := net.JoinHostPort(m.params.Host, m.params.Port)
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if m.params.Port == "465" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, &tls.Config{ServerName: m.params.Host})
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// bound the whole conversation so a stalled server cannot block the request
+	err = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, m.params.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
@@ -0,0 +1,165 @@
+package mailer
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"celeste/infrastructures/mailer/types"
+)
+
+// smtpStandIn accepts a single SMTP session and returns the envelope and data it received
+func smtpStandIn(t *testing.T) (string, <-chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var lines []string
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 localhost ESMTP")
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				received <- lines
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			lines = append(lines, line)
+
+			if inData {
+				if line == "." {
+					inData = false
+					reply("250 OK")
+				}
+				continue
+			}
+
+			switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "DATA":
+				inData = true
+				reply("354 End data with <CR><LF>.<CR><LF>")
+			case "QUIT":
+				reply("221 Bye")
+				received <- lines
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	address, received := smtpStandIn(t)
+	host, port, _ := net.SplitHostPort(address)
+
+	smtpMailer := &SMTPMailer{}
+	err := smtpMailer.Connect(types.SMTPParams{Host: host, Port: port, From: "Celeste <no-reply@celeste.test>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message, err := NewTemplateMessage(TemplateEmailVerification, []string{"user@celeste.test"}, types.EmailVerificationData{
+		Name:      "User",
+		Link:      "https://celeste.test/verify-email?token=abc",
+		ExpiresIn: "24h0m0s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = smtpMailer.Send(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := strings.Join(<-received, "\n")
+	for _, expected := range []string{
+		"MAIL FROM:<no-reply@celeste.test>",
+		"RCPT TO:<user@celeste.test>",
+		"Subject: Verify your email",
+		"Content-Type: multipart/alternative",
+		"https://celeste.test/verify-email?token=3Dabc",
+	} {
+		if !strings.Contains(session, expected) {
+			t.Errorf("missing %q in session:\n%s", expected, session)
+		}
+	}
+}
+
+func TestFileMailerSend(t *testing.T) {
+	directory := filepath.Join(t.TempDir(), "mail")
+
+	fileMailer := &FileMailer{}
+	err := fileMailer.Load(types.FileParams{Directory: directory, From: "no-reply@celeste.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fileMailer.Send(types.Message{To: []string{"user@celeste.test"}, Subject: "Hello", TextBody: "Hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(directory, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one message file, found %v", files)
+	}
+
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "Subject: Hello") || strings.Contains(string(content), "multipart") {
+		t.Errorf("unexpected message file:\n%s", content)
+	}
+}
+
+func TestTemplateMessageEscapesHTML(t *testing.T) {
+	message, err := NewTemplateMessage(TemplateSecurityAlert, []string{"user@celeste.test"}, types.SecurityAlertData{
+		Name:      "<script>alert(1)</script>",
+		Event:     "Password changed",
+		IPAddress: "127.0.0.1",
+		Time:      "2024-01-01 00:00:00 UTC",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(message.HTMLBody, "<script>") {
+		t.Errorf("html body is not escaped:\n%s", message.HTMLBody)
+	}
+	if !strings.Contains(message.TextBody, "Password changed") {
+		t.Errorf("unexpected text body:\n%s", message.TextBody)
+	}
+}
+
+func TestBuildMessageRejectsHeaderInjection(t *testing.T) {
+	_, err := buildMessage("no-reply@celeste.test", types.Message{
+		To:      []string{"user@celeste.test"},
+		Subject: "Hello\r\nBcc: attacker@celeste.test",
+	})
+	if err == nil {
+		t.Error("expected an error for a subject with line breaks")
+	}
+}
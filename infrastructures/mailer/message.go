@@ -0,0 +1,129 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"celeste/infrastructures/mailer/types"
+)
+
+// buildMessage encodes the message as RFC 5322 with a multipart alternative body when an HTML body is set
+func buildMessage(from string, message types.Message) ([]byte, error) {
+	if len(message.To) == 0 {
+		return nil, errors.New("message has no recipients")
+	}
+
+	fromAddress, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	var to []string
+	for _, recipient := range message.To {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient address: %w", err)
+		}
+
+		to = append(to, address.String())
+	}
+
+	// headers must not be split by user controlled values
+	if strings.ContainsAny(message.Subject, "\r\n") {
+		return nil, errors.New("invalid subject")
+	}
+
+	messageID := make([]byte, 16)
+	if _, err := rand.Read(messageID); err != nil {
+		return nil, err
+	}
+
+	domain := fromAddress.Address[strings.LastIndex(fromAddress.Address, "@")+1:]
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "From: %s\r\n", fromAddress.String())
+	fmt.Fprintf(&out, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&out, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&out, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&out, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(messageID), domain)
+	fmt.Fprintf(&out, "MIME-Version: 1.0\r\n")
+
+	if len(message.HTMLBody) == 0 {
+		fmt.Fprintf(&out, "Content-Type: text/plain; charset=utf-8\r\n")
+		fmt.Fprintf(&out, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+		err = writeQuotedPrintable(&out, message.TextBody)
+		if err != nil {
+			return nil, err
+		}
+
+		return out.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&out)
+	fmt.Fprintf(&out, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", message.TextBody},
+		{"text/html; charset=utf-8", message.HTMLBody},
+	} {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = writeQuotedPrintable(partWriter, part.body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// recipientAddresses returns the bare addresses of the recipients for the SMTP envelope
+func recipientAddresses(recipients []string) ([]string, error) {
+	var addresses []string
+	for _, recipient := range recipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return nil, err
+		}
+
+		addresses = append(addresses, address.Address)
+	}
+
+	return addresses, nil
+}
+
+// writeQuotedPrintable writes the body with the quoted-printable transfer encoding
+func writeQuotedPrintable(w io.Writer, body string) error {
+	writer := quotedprintable.NewWriter(w)
+	if _, err := writer.Write([]byte(body)); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	htmlTemplate "html/template"
+	"strings"
+	textTemplate "text/template"
+
+	"celeste/infrastructures/mailer/types"
+)
+
+const (
+	// TemplateEmailVerification is sent with the email verification token
+	TemplateEmailVerification string = "email_verification"
+	// TemplatePasswordReset is sent with the password reset token
+	TemplatePasswordReset string = "password_reset"
+	// TemplateSecurityAlert is sent on sensitive account activity
+	TemplateSecurityAlert string = "security_alert"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// NewTemplateMessage renders the subject, text and HTML bodies of the template into a message
+// The subject and text body come from <name>.txt while the HTML body comes from <name>.html which escapes the data
+func NewTemplateMessage(name string, to []string, data interface{}) (types.Message, error) {
+	// every template defines the same blocks so each is parsed on its own
+	textFile, err := textTemplate.ParseFS(templateFiles, "templates/"+name+".txt")
+	if err != nil {
+		return types.Message{}, err
+	}
+
+	htmlFile, err := htmlTemplate.ParseFS(templateFiles, "templates/"+name+".html")
+	if err != nil {
+		return types.Message{}, err
+	}
+
+	subject, err := executeText(textFile, "subject", data)
+	if err != nil {
+		return types.Message{}, err
+	}
+
+	textBody, err := executeText(textFile, "text", data)
+	if err != nil {
+		return types.Message{}, err
+	}
+
+	var htmlBody bytes.Buffer
+	err = htmlFile.ExecuteTemplate(&htmlBody, "html", data)
+	if err != nil {
+		return types.Message{}, err
+	}
+
+	return types.Message{
+		To:       to,
+		Subject:  strings.TrimSpace(subject),
+		TextBody: textBody,
+		HTMLBody: htmlBody.String(),
+	}, nil
+}
+
+// executeText renders the named text template definition
+func executeText(t *textTemplate.Template, name string, data interface{}) (string, error) {
+	var out bytes.Buffer
+	err := t.ExecuteTemplate(&out, name, data)
+	if err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
@@ -0,0 +1,7 @@
+package types
+
+// MailerInterface contains the implementable methods for the mailer
+type MailerInterface interface {
+	// Send delivers the message to its recipients
+	Send(message Message) error
+}
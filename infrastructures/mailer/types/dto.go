@@ -0,0 +1,40 @@
+package types
+
+type Message struct {
+	To       []string
+	Subject  string
+	TextBody string
+	HTMLBody string // optional alternative to the text body
+}
+
+type SMTPParams struct {
+	Host     string
+	Port     string // 465 uses implicit TLS, other ports upgrade with STARTTLS when offered
+	Username string // authentication is skipped when empty
+	Password string
+	From     string
+}
+
+type FileParams struct {
+	Directory string // created if missing
+	From      string
+}
+
+type EmailVerificationData struct {
+	Name      string
+	Link      string
+	ExpiresIn string
+}
+
+type PasswordResetData struct {
+	Name      string
+	Link      string
+	ExpiresIn string
+}
+
+type SecurityAlertData struct {
+	Name      string
+	Event     string
+	IPAddress string
+	Time      string
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"celeste/infrastructures/ratelimit/types"
+)
+
+// MemoryAttemptStore handles the failed attempt store kept in the process memory
+// Counts are not shared between instances, use a shared store when running more than one
+type MemoryAttemptStore struct {
+	attempts  map[string]memoryAttempt
+	lastPrune time.Time
+	mu        sync.Mutex
+}
+
+type memoryAttempt struct {
+	types.Attempt
+	expiresAt time.Time
+}
+
+// pruneInterval bounds how often expired keys are removed so the map cannot grow without limit
+const pruneInterval = time.Minute
+
+// Get returns the failed attempts of the key, a zero attempt when none are tracked
+func (s *MemoryAttemptStore) Get(key string) (types.Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok || time.Now().After(attempt.expiresAt) {
+		return types.Attempt{}, nil
+	}
+
+	return attempt.Attempt, nil
+}
+
+// RecordFailure counts a failed attempt of the key, the count expires after the ttl passes without failures
+func (s *MemoryAttemptStore) RecordFailure(key string, ttl time.Duration) (types.Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.attempts == nil {
+		s.attempts = map[string]memoryAttempt{}
+	}
+	s.prune(now)
+
+	attempt, ok := s.attempts[key]
+	if !ok || now.After(attempt.expiresAt) {
+		attempt = memoryAttempt{}
+	}
+
+	attempt.Failures++
+	attempt.LastFailureAt = now
+	attempt.expiresAt = now.Add(ttl)
+	s.attempts[key] = attempt
+
+	return attempt.Attempt, nil
+}
+
+// Reset forgets the failed attempts of the key
+func (s *MemoryAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+
+	return nil
+}
+
+// prune removes the expired keys at most once per prune interval
+func (s *MemoryAttemptStore) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < pruneInterval {
+		return
+	}
+	s.lastPrune = now
+
+	for key, attempt := range s.attempts {
+		if now.After(attempt.expiresAt) {
+			delete(s.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package ratelimit
+
+import (
+	"time"
+
+	"celeste/infrastructures/ratelimit/types"
+)
+
+// Delay returns how long the key must wait before its next attempt and whether the wait is a lockout
+// Every failure below the max doubles the backoff, reaching the max locks the key for the lockout duration
+func Delay(attempt types.Attempt, policy types.Policy, now time.Time) (time.Duration, bool) {
+	if attempt.Failures == 0 {
+		return 0, false
+	}
+
+	if policy.MaxFailures > 0 && attempt.Failures >= policy.MaxFailures {
+		wait := attempt.LastFailureAt.Add(policy.LockoutDuration).Sub(now)
+		if wait <= 0 {
+			return 0, false
+		}
+
+		return wait, true
+	}
+
+	backoff := policy.BackoffBase
+	for i := uint(1); i < attempt.Failures && backoff < policy.LockoutDuration; i++ {
+		backoff *= 2
+	}
+	if backoff > policy.LockoutDuration {
+		backoff = policy.LockoutDuration
+	}
+
+	wait := attempt.LastFailureAt.Add(backoff).Sub(now)
+	if wait <= 0 {
+		return 0, false
+	}
+
+	return wait, false
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"celeste/infrastructures/ratelimit/types"
+)
+
+func TestDelay(t *testing.T) {
+	policy := types.Policy{
+		MaxFailures:     5,
+		BackoffBase:     time.Second,
+		LockoutDuration: 15 * time.Minute,
+	}
+	now := time.Now()
+
+	tests := []struct {
+		failures uint
+		elapsed  time.Duration
+		wait     time.Duration
+		locked   bool
+	}{
+		{0, 0, 0, false},
+		{1, 0, time.Second, false},
+		{1, time.Second, 0, false},
+		{3, time.Second, 3 * time.Second, false},
+		{4, 0, 8 * time.Second, false},
+		{5, time.Minute, 14 * time.Minute, true},
+		{5, 15 * time.Minute, 0, false},
+	}
+
+	for _, test := range tests {
+		wait, locked := Delay(types.Attempt{Failures: test.failures, LastFailureAt: now.Add(-test.elapsed)}, policy, now)
+		if wait != test.wait || locked != test.locked {
+			t.Errorf("%d failures %s ago: expected %s %t, got %s %t", test.failures, test.elapsed, test.wait, test.locked, wait, locked)
+		}
+	}
+}
+
+func TestDelayCapsBackoff(t *testing.T) {
+	policy := types.Policy{BackoffBase: time.Second, LockoutDuration: time.Minute}
+	now := time.Now()
+
+	wait, locked := Delay(types.Attempt{Failures: 40, LastFailureAt: now}, policy, now)
+	if wait != time.Minute || locked {
+		t.Errorf("expected backoff capped at a minute, got %s %t", wait, locked)
+	}
+}
+
+func TestMemoryAttemptStore(t *testing.T) {
+	store := &MemoryAttemptStore{}
+
+	for i := 0; i < 3; i++ {
+		if _, err := store.RecordFailure("login:account:user@celeste.test", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	attempt, _ := store.Get("login:account:user@celeste.test")
+	if attempt.Failures != 3 {
+		t.Errorf("expected 3 failures, got %d", attempt.Failures)
+	}
+
+	// failures older than the ttl start a new count
+	_, _ = store.RecordFailure("login:ip:127.0.0.1", -time.Second)
+	attempt, _ = store.RecordFailure("login:ip:127.0.0.1", time.Minute)
+	if attempt.Failures != 1 {
+		t.Errorf("expected expired failures to be dropped, got %d", attempt.Failures)
+	}
+
+	_ = store.Reset("login:account:user@celeste.test")
+	attempt, _ = store.Get("login:account:user@celeste.test")
+	if attempt.Failures != 0 {
+		t.Errorf("expected reset attempts, got %d", attempt.Failures)
+	}
+}
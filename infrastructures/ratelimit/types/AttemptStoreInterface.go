@@ -0,0 +1,16 @@
+package types
+
+import (
+	"time"
+)
+
+// AttemptStoreInterface contains the implementable methods for the failed attempt store
+// Keys are opaque, e.g. login:account:<email> or login:ip:<address>, so a shared store like Redis can back several instances
+type AttemptStoreInterface interface {
+	// Get returns the failed attempts of the key, a zero attempt when none are tracked
+	Get(key string) (Attempt, error)
+	// RecordFailure counts a failed attempt of the key, the count expires after the ttl passes without failures
+	RecordFailure(key string, ttl time.Duration) (Attempt, error)
+	// Reset forgets the failed attempts of the key
+	Reset(key string) error
+}
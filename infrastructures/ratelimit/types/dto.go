@@ -0,0 +1,16 @@
+package types
+
+import (
+	"time"
+)
+
+type Attempt struct {
+	Failures      uint
+	LastFailureAt time.Time
+}
+
+type Policy struct {
+	MaxFailures     uint          // failures before the key is locked out
+	BackoffBase     time.Duration // wait after the first failure, doubled on every further failure
+	LockoutDuration time.Duration // wait once the max failures is reached, also caps the backoff
+}
@@ -0,0 +1,65 @@
+package jwt
+
+import (
+	"context"
+	"net/http"
+
+	iamConfig "celeste/configs/iam"
+	"celeste/interfaces/http/rest/viewmodels"
+	"celeste/internal/errors"
+	"celeste/module/auth/application"
+	serviceTypes "celeste/module/auth/infrastructure/service/types"
+)
+
+type apiKeyContextKey struct{}
+
+var config = iamConfig.Config{}
+
+// APIKeyMiddleware authenticates service callers presenting an API key, they act with the scopes of the key
+// Requests without the header pass through to JWTAuthMiddleware
+func APIKeyMiddleware(authenticator application.AuthCommandServiceInterface) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey := r.Header.Get(config.APIKeyHeader())
+			if len(apiKey) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := authenticator.AuthenticateAPIKey(r.Context(), apiKey)
+			if err != nil {
+				var httpCode int
+				var errorMsg string
+
+				switch err.Error() {
+				case errors.InvalidAPIKey:
+					httpCode = http.StatusUnauthorized
+					errorMsg = "Invalid API key."
+				default:
+					httpCode = http.StatusInternalServerError
+					errorMsg = "Please contact technical support."
+				}
+
+				response := viewmodels.HTTPResponseVM{
+					Status:    httpCode,
+					Success:   false,
+					Message:   errorMsg,
+					ErrorCode: err.Error(),
+				}
+
+				response.JSON(w)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), apiKeyContextKey{}, res)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// APIKey returns the API key the request was authenticated with, false for other callers
+func APIKey(ctx context.Context) (serviceTypes.APIKeyResult, bool) {
+	apiKey, ok := ctx.Value(apiKeyContextKey{}).(serviceTypes.APIKeyResult)
+
+	return apiKey, ok
+}
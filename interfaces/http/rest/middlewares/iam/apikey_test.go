@@ -0,0 +1,70 @@
+package jwt
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiError "celeste/internal/errors"
+	"celeste/internal/rbac"
+	serviceTypes "celeste/module/auth/infrastructure/service/types"
+)
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	authenticator := &fakeAuthenticator{apiKeys: map[string]serviceTypes.APIKeyResult{
+		"valid": {ID: "billing", Scopes: []string{rbac.PermissionUsersRead}},
+	}}
+
+	tests := map[string]struct {
+		apiKey    string
+		status    int
+		errorCode string
+		scoped    bool
+	}{
+		"valid key":   {"valid", http.StatusOK, "", true},
+		"invalid key": {"invalid", http.StatusUnauthorized, apiError.InvalidAPIKey, false},
+		"missing key": {"", http.StatusOK, "", false},
+	}
+
+	for name, test := range tests {
+		handler := APIKeyMiddleware(authenticator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey, ok := APIKey(r.Context())
+			if ok != test.scoped {
+				t.Errorf("%s: expected the request to carry an API key to be %t", name, test.scoped)
+			}
+			if ok && !HasPermission(r.Context(), rbac.PermissionUsersRead) {
+				t.Errorf("%s: expected the scopes of API key %s to be granted", name, apiKey.ID)
+			}
+
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		r := httptest.NewRequest("GET", "/", nil)
+		if len(test.apiKey) > 0 {
+			r.Header.Set(config.APIKeyHeader(), test.apiKey)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", name, test.status, w.Code)
+			continue
+		}
+
+		if len(test.errorCode) > 0 {
+			var response struct {
+				ErrorCode string `json:"errorCode"`
+			}
+
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+
+			if response.ErrorCode != test.errorCode {
+				t.Errorf("%s: expected error code %s, got %s", name, test.errorCode, response.ErrorCode)
+			}
+		}
+	}
+}
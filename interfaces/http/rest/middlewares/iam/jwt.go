@@ -7,56 +7,104 @@ import (
 
 	"celeste/interfaces/http/rest/viewmodels"
 	"celeste/internal/errors"
+	"celeste/module/auth/application"
 )
 
-// JWTAuthMiddleware handles JWT authentication custom errors
-func JWTAuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		_, claims, err := jwtauth.FromContext(r.Context())
-		if err != nil {
-			var httpCode int
-			var errorMsg string
-
-			switch err {
-			case jwtauth.ErrExpired:
-				httpCode = http.StatusUnauthorized
-				errorMsg = "Token has expired."
-			case jwtauth.ErrNoTokenFound:
-				httpCode = http.StatusUnauthorized
-				errorMsg = "No token found."
-			case jwtauth.ErrUnauthorized:
-				httpCode = http.StatusUnauthorized
-				errorMsg = "Invalid token."
-			default:
-				httpCode = http.StatusUnauthorized
-				errorMsg = "Invalid token"
+// JWTAuthMiddleware handles JWT authentication custom errors and rejects access tokens of revoked sessions
+func JWTAuthMiddleware(authenticator application.AuthCommandServiceInterface) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// trusted internal services and API key callers do not act on behalf of a user
+			if isServiceCaller(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
 			}
 
-			response := viewmodels.HTTPResponseVM{
-				Status:    httpCode,
-				Success:   false,
-				Message:   errorMsg,
-				ErrorCode: errors.UnauthorizedAccess,
+			_, claims, err := jwtauth.FromContext(r.Context())
+			if err != nil {
+				var httpCode int
+				var errorMsg string
+
+				switch err {
+				case jwtauth.ErrExpired:
+					httpCode = http.StatusUnauthorized
+					errorMsg = "Token has expired."
+				case jwtauth.ErrNoTokenFound:
+					httpCode = http.StatusUnauthorized
+					errorMsg = "No token found."
+				case jwtauth.ErrUnauthorized:
+					httpCode = http.StatusUnauthorized
+					errorMsg = "Invalid token."
+				default:
+					httpCode = http.StatusUnauthorized
+					errorMsg = "Invalid token"
+				}
+
+				response := viewmodels.HTTPResponseVM{
+					Status:    httpCode,
+					Success:   false,
+					Message:   errorMsg,
+					ErrorCode: errors.UnauthorizedAccess,
+				}
+
+				response.JSON(w)
+				return
 			}
 
-			response.JSON(w)
-			return
-		}
-
-		// if token is nil, creates unauthorized response
-		if claims == nil {
-			response := viewmodels.HTTPResponseVM{
-				Status:    http.StatusUnauthorized,
-				Success:   false,
-				Message:   "Invalid token",
-				ErrorCode: errors.UnauthorizedAccess,
+			// if token is nil, creates unauthorized response
+			if claims == nil {
+				response := viewmodels.HTTPResponseVM{
+					Status:    http.StatusUnauthorized,
+					Success:   false,
+					Message:   "Invalid token",
+					ErrorCode: errors.UnauthorizedAccess,
+				}
+
+				response.JSON(w)
+				return
 			}
 
-			response.JSON(w)
-			return
-		}
+			// every access token belongs to a session, a signed out device must not keep using its token
+			sessionID := SessionID(r.Context())
+			if len(sessionID) == 0 {
+				response := viewmodels.HTTPResponseVM{
+					Status:    http.StatusUnauthorized,
+					Success:   false,
+					Message:   "Invalid token",
+					ErrorCode: errors.UnauthorizedAccess,
+				}
+
+				response.JSON(w)
+				return
+			}
+
+			err = authenticator.VerifySession(r.Context(), sessionID)
+			if err != nil {
+				var httpCode int
+				var errorMsg string
+
+				switch err.Error() {
+				case errors.SessionRevoked:
+					httpCode = http.StatusUnauthorized
+					errorMsg = "Session has been revoked."
+				default:
+					httpCode = http.StatusInternalServerError
+					errorMsg = "Please contact technical support."
+				}
+
+				response := viewmodels.HTTPResponseVM{
+					Status:    httpCode,
+					Success:   false,
+					Message:   errorMsg,
+					ErrorCode: err.Error(),
+				}
+
+				response.JSON(w)
+				return
+			}
 
-		// if token is valid, proceeds to the next handler
-		next.ServeHTTP(w, r)
-	})
+			// if token is valid, proceeds to the next handler
+			next.ServeHTTP(w, r)
+		})
+	}
 }
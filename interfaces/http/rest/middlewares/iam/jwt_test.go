@@ -0,0 +1,126 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/jwtauth/v5"
+
+	apiError "celeste/internal/errors"
+	"celeste/internal/token"
+	"celeste/module/auth/application"
+	serviceTypes "celeste/module/auth/infrastructure/service/types"
+)
+
+const walletAddress string = "0x71C7656EC7ab88b098defB751B7401B5f6d8976F"
+
+// fakeAuthenticator rejects the sessions listed as revoked and the API keys it does not know
+type fakeAuthenticator struct {
+	application.AuthCommandServiceInterface
+	apiKeys map[string]serviceTypes.APIKeyResult
+	revoked map[string]bool
+}
+
+func (authenticator *fakeAuthenticator) AuthenticateAPIKey(ctx context.Context, apiKey string) (serviceTypes.APIKeyResult, error) {
+	res, ok := authenticator.apiKeys[apiKey]
+	if !ok {
+		return serviceTypes.APIKeyResult{}, errors.New(apiError.InvalidAPIKey)
+	}
+
+	return res, nil
+}
+
+func (authenticator *fakeAuthenticator) VerifySession(ctx context.Context, id string) error {
+	if authenticator.revoked[id] {
+		return errors.New(apiError.SessionRevoked)
+	}
+
+	return nil
+}
+
+// withAccessToken returns a request carrying a verified access token of the session
+func withAccessToken(t *testing.T, sessionID string) *http.Request {
+	return withClaims(t, map[string]interface{}{
+		"sub":                walletAddress,
+		token.ClaimSessionID: sessionID,
+	})
+}
+
+// withClaims returns a request carrying a verified access token with the claims
+func withClaims(t *testing.T, claims map[string]interface{}) *http.Request {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+
+	accessToken, _, err := tokenAuth.Encode(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+
+	return r.WithContext(jwtauth.NewContext(r.Context(), accessToken, nil))
+}
+
+func TestJWTAuthMiddleware(t *testing.T) {
+	authenticator := &fakeAuthenticator{revoked: map[string]bool{"revoked": true}}
+
+	tests := map[string]struct {
+		sessionID string
+		status    int
+		errorCode string
+	}{
+		"active session":  {"active", http.StatusOK, ""},
+		"revoked session": {"revoked", http.StatusUnauthorized, apiError.SessionRevoked},
+		"missing session": {"", http.StatusUnauthorized, apiError.UnauthorizedAccess},
+	}
+
+	for name, test := range tests {
+		handler := JWTAuthMiddleware(authenticator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, withAccessToken(t, test.sessionID))
+
+		if w.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", name, test.status, w.Code)
+			continue
+		}
+
+		if len(test.errorCode) > 0 {
+			var response struct {
+				ErrorCode string `json:"errorCode"`
+			}
+
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+
+			if response.ErrorCode != test.errorCode {
+				t.Errorf("%s: expected error code %s, got %s", name, test.errorCode, response.ErrorCode)
+			}
+		}
+	}
+}
+
+func TestSubjectWithAPIKey(t *testing.T) {
+	authenticator := &fakeAuthenticator{revoked: map[string]bool{"revoked": true}}
+
+	r := withAccessToken(t, "revoked")
+	r = r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, serviceTypes.APIKeyResult{}))
+
+	handler := JWTAuthMiddleware(authenticator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsSubject(r.Context(), walletAddress) {
+			t.Error("expected the access token of a revoked session to be ignored for API key callers")
+		}
+
+		if sessionID := SessionID(r.Context()); len(sessionID) > 0 {
+			t.Errorf("expected no session for API key callers, got %s", sessionID)
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+}
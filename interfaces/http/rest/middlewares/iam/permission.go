@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/jwtauth/v5"
+
+	"celeste/interfaces/http/rest/middlewares/scope"
+	"celeste/interfaces/http/rest/viewmodels"
+	"celeste/internal/errors"
+	"celeste/internal/rbac"
+)
+
+// RequirePermission rejects callers whose access token or API key does not grant the permission
+// It runs after JWTAuthMiddleware, trusted internal services hold every permission
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasPermission(r.Context(), permission) {
+				response := viewmodels.HTTPResponseVM{
+					Status:    http.StatusForbidden,
+					Success:   false,
+					Message:   "You do not have permission to access this resource.",
+					ErrorCode: errors.ForbiddenAccess,
+				}
+
+				response.JSON(w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// HasPermission reports whether the caller was granted the permission
+func HasPermission(ctx context.Context, permission string) bool {
+	if scope.HasInternalScope(ctx) {
+		return true
+	}
+
+	// API key callers only hold the scopes of the key
+	if apiKey, ok := APIKey(ctx); ok {
+		return slices.Contains(apiKey.Scopes, permission)
+	}
+
+	return slices.Contains(claimStrings(ctx, rbac.ClaimPermissions), permission)
+}
+
+// claimStrings returns the string values of a list claim of the access token
+func claimStrings(ctx context.Context, key string) []string {
+	_, claims, err := jwtauth.FromContext(ctx)
+	if err != nil {
+		return nil
+	}
+
+	claim, _ := claims[key].([]interface{})
+
+	var values []string
+	for _, value := range claim {
+		if s, ok := value.(string); ok {
+			values = append(values, s)
+		}
+	}
+
+	return values
+}
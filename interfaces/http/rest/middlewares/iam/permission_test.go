@@ -0,0 +1,53 @@
+package jwt
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiError "celeste/internal/errors"
+	"celeste/internal/rbac"
+)
+
+func TestRequirePermission(t *testing.T) {
+	tests := map[string]struct {
+		permissions []interface{}
+		status      int
+	}{
+		"granted permission": {[]interface{}{rbac.PermissionUsersRead, rbac.PermissionUsersList}, http.StatusOK},
+		"other permission":   {[]interface{}{rbac.PermissionUsersRead}, http.StatusForbidden},
+		"no permission":      {nil, http.StatusForbidden},
+	}
+
+	for name, test := range tests {
+		handler := RequirePermission(rbac.PermissionUsersList)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, withClaims(t, map[string]interface{}{
+			"sub":                 walletAddress,
+			rbac.ClaimPermissions: test.permissions,
+		}))
+
+		if w.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", name, test.status, w.Code)
+			continue
+		}
+
+		if test.status == http.StatusForbidden {
+			var response struct {
+				ErrorCode string `json:"errorCode"`
+			}
+
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+
+			if response.ErrorCode != apiError.ForbiddenAccess {
+				t.Errorf("%s: expected error code %s, got %s", name, apiError.ForbiddenAccess, response.ErrorCode)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package jwt
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-chi/jwtauth/v5"
+
+	"celeste/interfaces/http/rest/middlewares/scope"
+	"celeste/internal/token"
+)
+
+// Subject returns the wallet address of the authenticated user, empty when the request carries no valid token
+// API key and internal callers do not act on behalf of a user, so an access token sent alongside them is ignored
+func Subject(ctx context.Context) string {
+	if isServiceCaller(ctx) {
+		return ""
+	}
+
+	token, _, err := jwtauth.FromContext(ctx)
+	if err != nil || token == nil {
+		return ""
+	}
+
+	return token.Subject()
+}
+
+// SessionID returns the ID of the session the access token belongs to, empty when the request carries no valid token
+func SessionID(ctx context.Context) string {
+	if isServiceCaller(ctx) {
+		return ""
+	}
+
+	_, claims, err := jwtauth.FromContext(ctx)
+	if err != nil {
+		return ""
+	}
+
+	sessionID, _ := claims[token.ClaimSessionID].(string)
+
+	return sessionID
+}
+
+// IsSubject reports whether the authenticated user owns the wallet address
+func IsSubject(ctx context.Context, walletAddress string) bool {
+	subject := Subject(ctx)
+
+	return len(subject) > 0 && strings.EqualFold(subject, walletAddress)
+}
+
+// CanActOn reports whether the caller may act on the user of the wallet address
+// Owners act on themselves, everyone else needs the permission to act on any user
+func CanActOn(ctx context.Context, walletAddress, permission string) bool {
+	return IsSubject(ctx, walletAddress) || HasPermission(ctx, permission)
+}
+
+// isServiceCaller reports whether an API key or the internal scope authenticated the request
+func isServiceCaller(ctx context.Context) bool {
+	_, ok := APIKey(ctx)
+
+	return ok || scope.HasInternalScope(ctx)
+}
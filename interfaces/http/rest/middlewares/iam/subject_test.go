@@ -0,0 +1,85 @@
+package jwt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"celeste/interfaces/http/rest/middlewares/scope"
+	"celeste/internal/rbac"
+	serviceTypes "celeste/module/auth/infrastructure/service/types"
+)
+
+const otherWalletAddress string = "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+
+// withInternalScope returns the context of a request authenticated with the internal service token
+func withInternalScope(t *testing.T, r *http.Request) context.Context {
+	t.Setenv("INTERNAL_API_TOKEN", "internal")
+	r.Header.Set("X-Internal-Token", "internal")
+
+	var ctx context.Context
+	scope.InternalScopeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), r)
+
+	if ctx == nil {
+		t.Fatal("expected the internal token to be accepted")
+	}
+
+	return ctx
+}
+
+func TestIsSubject(t *testing.T) {
+	tests := map[string]struct {
+		ctx           context.Context
+		walletAddress string
+		expected      bool
+	}{
+		"owner":                       {withAccessToken(t, "session").Context(), walletAddress, true},
+		"owner with different casing": {withAccessToken(t, "session").Context(), strings.ToLower(walletAddress), true},
+		"other user":                  {withAccessToken(t, "session").Context(), otherWalletAddress, false},
+		"missing token":               {context.Background(), walletAddress, false},
+		"empty wallet address":        {withClaims(t, map[string]interface{}{}).Context(), "", false},
+		"API key caller": {
+			context.WithValue(withAccessToken(t, "session").Context(), apiKeyContextKey{}, serviceTypes.APIKeyResult{}),
+			walletAddress,
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		if IsSubject(test.ctx, test.walletAddress) != test.expected {
+			t.Errorf("%s: expected IsSubject to be %t", name, test.expected)
+		}
+	}
+}
+
+func TestCanActOn(t *testing.T) {
+	admin := withClaims(t, map[string]interface{}{
+		"sub":                 otherWalletAddress,
+		rbac.ClaimPermissions: []interface{}{rbac.PermissionUsersDeactivate},
+	}).Context()
+
+	tests := map[string]struct {
+		ctx        context.Context
+		permission string
+		expected   bool
+	}{
+		"owner":                         {withAccessToken(t, "session").Context(), rbac.PermissionUsersDeactivate, true},
+		"other user without permission": {withClaims(t, map[string]interface{}{"sub": otherWalletAddress}).Context(), rbac.PermissionUsersDeactivate, false},
+		"admin with permission":         {admin, rbac.PermissionUsersDeactivate, true},
+		"admin with another permission": {admin, rbac.PermissionUsersUpdate, false},
+		"internal service":              {withInternalScope(t, httptest.NewRequest("GET", "/", nil)), rbac.PermissionUsersUpdate, true},
+		"API key with the scope":        {context.WithValue(context.Background(), apiKeyContextKey{}, serviceTypes.APIKeyResult{Scopes: []string{rbac.PermissionUsersUpdate}}), rbac.PermissionUsersUpdate, true},
+		"API key without the scope":     {context.WithValue(context.Background(), apiKeyContextKey{}, serviceTypes.APIKeyResult{}), rbac.PermissionUsersUpdate, false},
+		"missing token":                 {context.Background(), rbac.PermissionUsersDeactivate, false},
+	}
+
+	for name, test := range tests {
+		if CanActOn(test.ctx, walletAddress, test.permission) != test.expected {
+			t.Errorf("%s: expected CanActOn to be %t", name, test.expected)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package realip
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	proxyConfig "celeste/configs/proxy"
+)
+
+var config = proxyConfig.Config{}
+
+// RealIPMiddleware sets the remote address to the client address reported by a trusted reverse proxy
+// X-Forwarded-For and X-Real-IP are ignored unless the connection comes from one of the trusted proxies,
+// otherwise any client could pick a new address on every request and escape the per IP address limits
+func RealIPMiddleware(next http.Handler) http.Handler {
+	trustedProxies := config.TrustedProxies()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip := clientIP(r, trustedProxies); len(ip) > 0 {
+			r.RemoteAddr = ip
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP returns the client address forwarded by a trusted proxy, empty when the headers cannot be trusted
+// The closest untrusted address of X-Forwarded-For is used since the addresses before it are set by the client
+func clientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !isTrusted(net.ParseIP(host), trustedProxies) {
+		return ""
+	}
+
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); len(forwardedFor) > 0 {
+		addresses := strings.Split(forwardedFor, ",")
+		for i := len(addresses) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(addresses[i]))
+			if ip == nil {
+				return ""
+			}
+
+			if !isTrusted(ip, trustedProxies) || i == 0 {
+				return ip.String()
+			}
+		}
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+
+	return ""
+}
+
+// isTrusted reports whether the address belongs to one of the trusted proxies
+func isTrusted(ip net.IP, trustedProxies []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,40 @@
+package realip
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	trustedProxies := []*net.IPNet{proxies}
+
+	tests := map[string]struct {
+		remoteAddr   string
+		forwardedFor string
+		realIP       string
+		expected     string
+	}{
+		"untrusted peer spoofing":      {"203.0.113.7:4000", "198.51.100.1", "198.51.100.2", ""},
+		"trusted proxy":                {"10.0.0.2:4000", "198.51.100.1", "", "198.51.100.1"},
+		"client prepending addresses":  {"10.0.0.2:4000", "1.2.3.4, 198.51.100.1, 10.0.0.3", "", "198.51.100.1"},
+		"trusted proxy with real ip":   {"10.0.0.2:4000", "", "198.51.100.1", "198.51.100.1"},
+		"trusted proxy invalid header": {"10.0.0.2:4000", "not an ip", "", ""},
+	}
+
+	for name, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = test.remoteAddr
+		if len(test.forwardedFor) > 0 {
+			r.Header.Set("X-Forwarded-For", test.forwardedFor)
+		}
+		if len(test.realIP) > 0 {
+			r.Header.Set("X-Real-IP", test.realIP)
+		}
+
+		if ip := clientIP(r, trustedProxies); ip != test.expected {
+			t.Errorf("%s: expected %q, got %q", name, test.expected, ip)
+		}
+	}
+}
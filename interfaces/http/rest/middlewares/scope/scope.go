@@ -0,0 +1,53 @@
+package scope
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+
+	scopeConfig "celeste/configs/scope"
+	"celeste/interfaces/http/rest/viewmodels"
+	"celeste/internal/errors"
+)
+
+type contextKey struct{}
+
+// Internal is the scope of trusted internal services allowed to read privileged projections
+const Internal = "internal"
+
+var config = scopeConfig.Config{}
+
+// InternalScopeMiddleware grants the internal scope to requests carrying a valid internal service token
+// Requests without the token pass through unscoped
+func InternalScopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(config.InternalHeader())
+		if len(token) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		internalToken := config.InternalToken()
+		if len(internalToken) == 0 || subtle.ConstantTimeCompare([]byte(token), []byte(internalToken)) != 1 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusUnauthorized,
+				Success:   false,
+				Message:   "Invalid internal token.",
+				ErrorCode: errors.UnauthorizedAccess,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), contextKey{}, Internal)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// HasInternalScope checks whether the request was granted the internal scope
+func HasInternalScope(ctx context.Context) bool {
+	scope, _ := ctx.Value(contextKey{}).(string)
+
+	return scope == Internal
+}
@@ -20,10 +20,15 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
 
 	"celeste/interfaces"
 	"celeste/interfaces/http/rest/middlewares/cors"
+	iam "celeste/interfaces/http/rest/middlewares/iam"
+	"celeste/interfaces/http/rest/middlewares/realip"
+	"celeste/interfaces/http/rest/middlewares/scope"
 	"celeste/interfaces/http/rest/viewmodels"
+	"celeste/internal/rbac"
 )
 
 // ChiRouterInterface declares methods for the chi router
@@ -42,6 +47,10 @@ var (
 // InitRouter initializes main routes
 func (router *router) InitRouter() *chi.Mux {
 	// DI assignment
+	tokenAuth := interfaces.ServiceContainer().RegisterJWTAuth()
+	authCommandService := interfaces.ServiceContainer().RegisterAuthCommandService()
+	authCommandController := interfaces.ServiceContainer().RegisterAuthRESTCommandController()
+	authQueryController := interfaces.ServiceContainer().RegisterAuthRESTQueryController()
 	userQueryController := interfaces.ServiceContainer().RegisterUserRESTQueryController()
 	userCommandController := interfaces.ServiceContainer().RegisterUserRESTCommandController()
 
@@ -50,7 +59,7 @@ func (router *router) InitRouter() *chi.Mux {
 
 	// global and recommended middlewares
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(realip.RealIPMiddleware)
 	r.Use(middleware.Logger)
 	r.Use(cors.Init().Handler)
 	r.Use(middleware.Recoverer)
@@ -82,16 +91,83 @@ func (router *router) InitRouter() *chi.Mux {
 	r.Group(func(r chi.Router) {
 		r.Route("/v1", func(r chi.Router) {
 
+			// auth module
+			r.Route("/auth", func(r chi.Router) {
+				r.Post("/login", authCommandController.Login)
+				r.Post("/logout", authCommandController.Logout)
+				r.Post("/refresh", authCommandController.RefreshToken)
+				r.Post("/siwe/nonce", authCommandController.CreateSIWENonce)
+				r.Post("/siwe/verify", authCommandController.VerifySIWE)
+				r.Post("/webauthn/login/begin", authCommandController.BeginWebAuthnLogin)
+				r.Post("/webauthn/login/finish", authCommandController.FinishWebAuthnLogin)
+
+				// authenticated routes
+				r.Group(func(r chi.Router) {
+					r.Use(jwtauth.Verifier(tokenAuth))
+					r.Use(iam.JWTAuthMiddleware(authCommandService))
+
+					r.Post("/webauthn/register/begin", authCommandController.BeginWebAuthnRegistration)
+					r.Post("/webauthn/register/finish", authCommandController.FinishWebAuthnRegistration)
+				})
+			})
+
+			// admin routes
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(scope.InternalScopeMiddleware)
+				r.Use(jwtauth.Verifier(tokenAuth))
+				r.Use(iam.APIKeyMiddleware(authCommandService))
+				r.Use(iam.JWTAuthMiddleware(authCommandService))
+
+				r.Group(func(r chi.Router) {
+					r.Use(iam.RequirePermission(rbac.PermissionAPIKeysManage))
+
+					r.Post("/api-keys", authCommandController.CreateAPIKey)
+					r.Get("/api-keys", authQueryController.GetAPIKeys)
+					r.Delete("/api-keys/{id}", authCommandController.RevokeAPIKey)
+				})
+
+				r.Group(func(r chi.Router) {
+					r.Use(iam.RequirePermission(rbac.PermissionRolesManage))
+
+					r.Put("/users/{walletAddress}/roles/{role}", authCommandController.GrantUserRole)
+					r.Delete("/users/{walletAddress}/roles/{role}", authCommandController.RevokeUserRole)
+				})
+			})
+
 			// user module
 			r.Route("/user", func(r chi.Router) {
+				r.Use(scope.InternalScopeMiddleware)
+
 				r.Post("/add", userCommandController.CreateUser)
-				r.Get("/", userQueryController.GetUserByEmail)
-				r.Get("/list", userQueryController.GetUsers)
-				r.Get("/{walletAddress}", userQueryController.GetUserByWalletAddress)
-				r.Put("/{walletAddress}/update", userCommandController.UpdateUserByWalletAddress)
 				r.Put("/email/verify", userCommandController.UpdateUserEmailVerifiedAt)
-				r.Put("/{walletAddress}/password/update", userCommandController.UpdateUserPassword)
-				r.Patch("/{walletAddress}/deactivate", userCommandController.DeactivateUser)
+				r.Post("/email/verify/resend", userCommandController.ResendEmailVerification)
+				r.Post("/password/forgot", userCommandController.SendPasswordReset)
+				r.Put("/password/reset", userCommandController.ResetUserPassword)
+
+				// authenticated routes
+				r.Group(func(r chi.Router) {
+					r.Use(jwtauth.Verifier(tokenAuth))
+					r.Use(iam.APIKeyMiddleware(authCommandService))
+					r.Use(iam.JWTAuthMiddleware(authCommandService))
+
+					r.Get("/", userQueryController.GetUserByEmail)
+					r.With(iam.RequirePermission(rbac.PermissionUsersList)).Get("/list", userQueryController.GetUsers)
+					r.Get("/{walletAddress}", userQueryController.GetUserByWalletAddress)
+					r.Post("/{walletAddress}/recover", userCommandController.RecoverUserWallet)
+					r.Get("/{walletAddress}/sessions", authQueryController.GetSessions)
+					r.Delete("/{walletAddress}/sessions/{id}", authCommandController.RevokeSession)
+					r.Put("/{walletAddress}/shares/rotate", userCommandController.RotateUserShares)
+					r.Post("/{walletAddress}/sign/message", userCommandController.SignMessage)
+					r.Post("/{walletAddress}/sign/transaction", userCommandController.SignTransaction)
+					r.Post("/{walletAddress}/sign/typed-data", userCommandController.SignTypedData)
+					r.Post("/{walletAddress}/totp/enroll", userCommandController.EnrollUserTOTP)
+					r.Put("/{walletAddress}/totp/confirm", userCommandController.ConfirmUserTOTP)
+					r.Put("/{walletAddress}/totp/disable", userCommandController.DisableUserTOTP)
+					r.Put("/{walletAddress}/totp/recovery-codes", userCommandController.RegenerateUserRecoveryCodes)
+					r.Put("/{walletAddress}/update", userCommandController.UpdateUserByWalletAddress)
+					r.Put("/{walletAddress}/password/update", userCommandController.UpdateUserPassword)
+					r.Patch("/{walletAddress}/deactivate", userCommandController.DeactivateUser)
+				})
 			})
 		})
 	})
@@ -14,8 +14,27 @@ import (
 	"os"
 	"sync"
 
+	"github.com/go-chi/jwtauth/v5"
+
+	emailConfig "celeste/configs/email"
+	jwtConfig "celeste/configs/jwt"
+	passwordConfig "celeste/configs/password"
+	ratelimitConfig "celeste/configs/ratelimit"
 	"celeste/infrastructures/database/mysql"
 	"celeste/infrastructures/database/mysql/types"
+	"celeste/infrastructures/kms"
+	kmsTypes "celeste/infrastructures/kms/types"
+	"celeste/infrastructures/mailer"
+	mailerTypes "celeste/infrastructures/mailer/types"
+	"celeste/infrastructures/ratelimit"
+	ratelimitTypes "celeste/infrastructures/ratelimit/types"
+	"celeste/internal/password"
+	"celeste/internal/token"
+	authApplication "celeste/module/auth/application"
+	authRepository "celeste/module/auth/infrastructure/repository"
+	authService "celeste/module/auth/infrastructure/service"
+	authREST "celeste/module/auth/interfaces/http/rest"
+	userApplication "celeste/module/user/application"
 	userRepository "celeste/module/user/infrastructure/repository"
 	userService "celeste/module/user/infrastructure/service"
 	userREST "celeste/module/user/interfaces/http/rest"
@@ -28,8 +47,17 @@ type ServiceContainerInterface interface {
 	// RegisterUserGRPCQueryController() userGRPC.UserQueryController
 
 	// REST
+	RegisterAuthRESTCommandController() authREST.AuthCommandController
+	RegisterAuthRESTQueryController() authREST.AuthQueryController
 	RegisterUserRESTCommandController() userREST.UserCommandController
 	RegisterUserRESTQueryController() userREST.UserQueryController
+
+	// Jobs
+	RegisterUserCommandService() userApplication.UserCommandServiceInterface
+
+	// Auth
+	RegisterAuthCommandService() authApplication.AuthCommandServiceInterface
+	RegisterJWTAuth() *jwtauth.JWTAuth
 }
 
 type kernel struct{}
@@ -39,6 +67,11 @@ var (
 	k              *kernel
 	containerOnce  sync.Once
 	mysqlDBHandler *mysql.MySQLDBHandler
+	kmsProvider    kmsTypes.KMSProviderInterface
+	tokenAuth      *jwtauth.JWTAuth
+	mailerHandler  mailerTypes.MailerInterface
+	passwordPolicy *password.Policy
+	attemptStore   ratelimitTypes.AttemptStoreInterface
 )
 
 // ================================= gRPC ===================================
@@ -66,6 +99,28 @@ var (
 
 // ==========================================================================
 // ================================= REST ===================================
+// RegisterAuthRESTCommandController performs dependency injection to the RegisterAuthRESTCommandController
+func (k *kernel) RegisterAuthRESTCommandController() authREST.AuthCommandController {
+	service := k.authCommandServiceContainer()
+
+	controller := authREST.AuthCommandController{
+		AuthCommandServiceInterface: service,
+	}
+
+	return controller
+}
+
+// RegisterAuthRESTQueryController performs dependency injection to the RegisterAuthRESTQueryController
+func (k *kernel) RegisterAuthRESTQueryController() authREST.AuthQueryController {
+	service := k.authQueryServiceContainer()
+
+	controller := authREST.AuthQueryController{
+		AuthQueryServiceInterface: service,
+	}
+
+	return controller
+}
+
 // RegisterUserRESTCommandController performs dependency injection to the RegisterUserRESTCommandController
 func (k *kernel) RegisterUserRESTCommandController() userREST.UserCommandController {
 	service := k.userCommandServiceContainer()
@@ -89,17 +144,102 @@ func (k *kernel) RegisterUserRESTQueryController() userREST.UserQueryController
 }
 
 // ==========================================================================
-func (k *kernel) userCommandServiceContainer() *userService.UserCommandService {
-	repository := &userRepository.UserCommandRepository{
+// ================================= Jobs ===================================
+// RegisterUserCommandService performs dependency injection to the user command service for background jobs
+func (k *kernel) RegisterUserCommandService() userApplication.UserCommandServiceInterface {
+	return k.userCommandServiceContainer()
+}
+
+// ==========================================================================
+// ================================= Auth ===================================
+// RegisterAuthCommandService performs dependency injection to the auth command service for the API key middleware
+func (k *kernel) RegisterAuthCommandService() authApplication.AuthCommandServiceInterface {
+	return k.authCommandServiceContainer()
+}
+
+// RegisterJWTAuth returns the shared token signer and verifier
+func (k *kernel) RegisterJWTAuth() *jwtauth.JWTAuth {
+	return tokenAuth
+}
+
+// ==========================================================================
+func (k *kernel) authCommandServiceContainer() *authService.AuthCommandService {
+	service, _ := k.commandServicesContainer()
+
+	return service
+}
+
+func (k *kernel) authQueryServiceContainer() *authService.AuthQueryService {
+	repository := &authRepository.AuthQueryRepository{
+		MySQLDBHandlerInterface: mysqlDBHandler,
+	}
+
+	service := &authService.AuthQueryService{
+		AuthQueryRepositoryInterface: &authRepository.AuthQueryRepositoryCircuitBreaker{
+			AuthQueryRepositoryInterface: repository,
+		},
+	}
+
+	return service
+}
+
+// commandServicesContainer builds the auth and user command services together since each one calls the other
+func (k *kernel) commandServicesContainer() (*authService.AuthCommandService, *userService.UserCommandService) {
+	repository := &authRepository.AuthCommandRepository{
 		MySQLDBHandlerInterface: mysqlDBHandler,
 	}
 
-	service := &userService.UserCommandService{
+	queryRepository := &authRepository.AuthQueryRepository{
+		MySQLDBHandlerInterface: mysqlDBHandler,
+	}
+
+	userCommandRepository := &userRepository.UserCommandRepository{
+		MySQLDBHandlerInterface: mysqlDBHandler,
+	}
+
+	userQueryRepository := &userRepository.UserQueryRepository{
+		MySQLDBHandlerInterface: mysqlDBHandler,
+	}
+
+	service := &authService.AuthCommandService{
+		AuthCommandRepositoryInterface: &authRepository.AuthCommandRepositoryCircuitBreaker{
+			AuthCommandRepositoryInterface: repository,
+		},
+		AuthQueryRepositoryInterface: &authRepository.AuthQueryRepositoryCircuitBreaker{
+			AuthQueryRepositoryInterface: queryRepository,
+		},
+		UserCommandRepositoryInterface: &userRepository.UserCommandRepositoryCircuitBreaker{
+			UserCommandRepositoryInterface: userCommandRepository,
+		},
+		UserQueryRepositoryInterface: &userRepository.UserQueryRepositoryCircuitBreaker{
+			UserQueryRepositoryInterface: userQueryRepository,
+		},
+		AttemptStoreInterface: attemptStore,
+		JWTAuth:               tokenAuth,
+	}
+
+	userCommandService := &userService.UserCommandService{
 		UserCommandRepositoryInterface: &userRepository.UserCommandRepositoryCircuitBreaker{
-			UserCommandRepositoryInterface: repository,
+			UserCommandRepositoryInterface: userCommandRepository,
+		},
+		UserQueryRepositoryInterface: &userRepository.UserQueryRepositoryCircuitBreaker{
+			UserQueryRepositoryInterface: userQueryRepository,
 		},
+		KMSProviderInterface:     kmsProvider,
+		MailerInterface:          mailerHandler,
+		UserAuthServiceInterface: service,
+		AttemptStoreInterface:    attemptStore,
+		Policy:                   passwordPolicy,
 	}
 
+	service.UserCommandServiceInterface = userCommandService
+
+	return service, userCommandService
+}
+
+func (k *kernel) userCommandServiceContainer() *userService.UserCommandService {
+	_, service := k.commandServicesContainer()
+
 	return service
 }
 
@@ -132,6 +272,84 @@ func registerHandlers() {
 	if err != nil {
 		log.Fatalf("[SERVER] mysql database is not responding: %v", err)
 	}
+
+	// connect to the key management service of the server shares
+	switch os.Getenv("KMS_PROVIDER") {
+	case "vault":
+		vaultKMS := &kms.VaultKMS{}
+		err = vaultKMS.Connect(kmsTypes.VaultKMSParams{
+			Address: os.Getenv("VAULT_ADDR"),
+			Token:   os.Getenv("VAULT_TOKEN"),
+			Mount:   os.Getenv("VAULT_TRANSIT_MOUNT"),
+			KeyName: os.Getenv("VAULT_TRANSIT_KEY"),
+		})
+		kmsProvider = vaultKMS
+	case "", "local":
+		localKMS := &kms.LocalKMS{}
+		err = localKMS.Load(kmsTypes.LocalKMSParams{
+			Keys:        os.Getenv("KEK_KEYS"),
+			ActiveKeyID: os.Getenv("KEK_ACTIVE_KEY_ID"),
+			KeyFile:     os.Getenv("KMS_LOCAL_KEY_FILE"),
+		})
+		kmsProvider = localKMS
+	default:
+		log.Fatalf("[SERVER] unsupported kms provider: %s", os.Getenv("KMS_PROVIDER"))
+	}
+	if err != nil {
+		log.Fatalf("[SERVER] kms provider is not responding: %v", err)
+	}
+
+	// setup the mailer
+	mailConfig := emailConfig.Config{}
+	switch mailConfig.Driver() {
+	case "smtp":
+		smtpMailer := &mailer.SMTPMailer{}
+		err = smtpMailer.Connect(mailerTypes.SMTPParams{
+			Host:     mailConfig.SMTPHost(),
+			Port:     mailConfig.SMTPPort(),
+			Username: mailConfig.SMTPUsername(),
+			Password: mailConfig.SMTPPassword(),
+			From:     mailConfig.From(),
+		})
+		mailerHandler = smtpMailer
+	case "file":
+		fileMailer := &mailer.FileMailer{}
+		err = fileMailer.Load(mailerTypes.FileParams{
+			Directory: mailConfig.FileDirectory(),
+			From:      mailConfig.From(),
+		})
+		mailerHandler = fileMailer
+	case "log":
+		mailerHandler = &mailer.LogMailer{}
+	case "":
+		log.Fatal("[SERVER] MAIL_DRIVER is not set, use smtp in production")
+	default:
+		log.Fatalf("[SERVER] unsupported mail driver: %s", mailConfig.Driver())
+	}
+	if err != nil {
+		log.Fatalf("[SERVER] mailer is not configured: %v", err)
+	}
+
+	// setup the password policy
+	passwordPolicy, err = password.NewPolicy(passwordConfig.Config{})
+	if err != nil {
+		log.Fatalf("[SERVER] password policy is not configured: %v", err)
+	}
+
+	// setup the failed login attempt store
+	limitConfig := ratelimitConfig.Config{}
+	switch limitConfig.Store() {
+	case "memory":
+		attemptStore = &ratelimit.MemoryAttemptStore{}
+	default:
+		log.Fatalf("[SERVER] unsupported rate limit store: %s", limitConfig.Store())
+	}
+
+	// setup the access token signer and verifier
+	tokenAuth, err = token.NewJWTAuth(jwtConfig.Config{})
+	if err != nil {
+		log.Fatalf("[SERVER] jwt is not configured: %v", err)
+	}
 }
 
 // ServiceContainer export instantiated service container once
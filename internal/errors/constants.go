@@ -1,6 +1,8 @@
 package errors
 
 const (
+	// AccountLocked is the code for logins to an account locked out after too many failed attempts
+	AccountLocked string = "ACCOUNT_LOCKED"
 	// DatabaseError is the code for any database changes errors
 	DatabaseError string = "DATABASE_ERROR"
 	// DuplicateRecord is the code for duplicate records
@@ -9,12 +11,32 @@ const (
 	ForbiddenAccess string = "FORBIDDEN_ACCESS"
 	// HystrixTimeout is the code for hystrix timeouts
 	HystrixTimeout string = "HYSTRIX_TIMEOUT"
+	// InvalidAPIKey is the code for unknown, expired or revoked API keys
+	InvalidAPIKey string = "INVALID_API_KEY"
+	// InvalidCredentials is the code for login attempts with an unknown email or wrong password
+	InvalidCredentials string = "INVALID_CREDENTIALS"
+	// InvalidRefreshToken is the code for unknown, expired, revoked or reused refresh tokens
+	InvalidRefreshToken string = "INVALID_REFRESH_TOKEN"
+	// InvalidRole is the code for granting a role that does not exist
+	InvalidRole string = "INVALID_ROLE"
 	// InvalidRequestPayload is the code for binding errors
 	InvalidRequestPayload string = "INVALID_REQUEST_PAYLOAD"
+	// InvalidTOTPCode is the code for wrong, expired or replayed TOTP codes and unknown or used recovery codes
+	InvalidTOTPCode string = "INVALID_TOTP_CODE"
+	// InvalidToken is the code for unknown, expired or already used single-use tokens
+	InvalidToken string = "INVALID_TOKEN"
 	// InvalidPassword is the code for invalid password
 	InvalidPassword string = "INVALID_PASSWORD"
 	// InvalidPayload is the code for payload not satisfying requirements
 	InvalidPayload string = "INVALID_PAYLOAD"
+	// InvalidSIWEMessage is the code for Sign-In With Ethereum messages that are malformed, expired, for another domain or chain, or use an unknown nonce
+	InvalidSIWEMessage string = "INVALID_SIWE_MESSAGE"
+	// InvalidScope is the code for API key scopes that are not known permissions
+	InvalidScope string = "INVALID_SCOPE"
+	// InvalidShare is the code for secret shares that fail to reconstruct the wallet
+	InvalidShare string = "INVALID_SHARE"
+	// InvalidWebAuthnResponse is the code for WebAuthn responses failing verification or answering an unknown, expired or used challenge
+	InvalidWebAuthnResponse string = "INVALID_WEBAUTHN_RESPONSE"
 	// MaximumLimitReached is the code when the max limit is reached
 	MaximumLimitReached string = "MAX_LIMIT_REACHED"
 	// MissingAPIEndpoint is the code for 404 API endpoints
@@ -23,14 +45,42 @@ const (
 	MissingConfiguration string = "MISSING_CONFIGURATION"
 	// MissingRecord is the code for no record found
 	MissingRecord string = "MISSING_RECORD"
+	// PasswordBreached is the code for passwords found in the breached password list
+	PasswordBreached string = "PASSWORD_BREACHED"
+	// PasswordContainsBannedWord is the code for passwords containing a banned word or the user email or name
+	PasswordContainsBannedWord string = "PASSWORD_CONTAINS_BANNED_WORD"
+	// PasswordTooLong is the code for passwords longer than the hasher accepts
+	PasswordTooLong string = "PASSWORD_TOO_LONG"
+	// PasswordTooShort is the code for passwords shorter than the minimum length
+	PasswordTooShort string = "PASSWORD_TOO_SHORT"
+	// PasswordTooSimple is the code for passwords mixing too few character classes
+	PasswordTooSimple string = "PASSWORD_TOO_SIMPLE"
+	// PasswordUnchanged is the code for password changes that reuse the current password
+	PasswordUnchanged string = "PASSWORD_UNCHANGED"
 	// ServerError is the code for server error
 	ServerError string = "SERVER_ERROR"
 	// ServerMaintenance is the code for server maintenance
 	ServerMaintenance string = "SERVER_MAINTENANCE"
+	// SessionRevoked is the code for access tokens of a revoked or expired session
+	SessionRevoked string = "SESSION_REVOKED"
+	// ShareRotated is the code for a server share replaced by a concurrent rotation
+	ShareRotated string = "SHARE_ROTATED"
 	// StorageUploadFailed is the code when storage upload (like to s3) failed
 	StorageUploadFailed string = "STORAGE_UPLOAD_FAILED"
 	// SystemScriptFailed is the code when scripts failed
 	SystemScriptFailed string = "SYSTEM_SCRIPT_FAILED"
+	// TOTPAlreadyEnabled is the code for enrolling a TOTP secret while two-factor authentication is enabled
+	TOTPAlreadyEnabled string = "TOTP_ALREADY_ENABLED"
+	// TOTPNotEnabled is the code for TOTP operations while two-factor authentication is disabled
+	TOTPNotEnabled string = "TOTP_NOT_ENABLED"
+	// TOTPRequired is the code for operations missing the TOTP code of a user with two-factor authentication
+	TOTPRequired string = "TOTP_REQUIRED"
+	// TooManyRequests is the code for requests made before the backoff after failed attempts elapsed
+	TooManyRequests string = "TOO_MANY_REQUESTS"
 	// UnauthorizedAccess is the code for accessing restricted routes
 	UnauthorizedAccess string = "UNAUTHORIZED_ACCESS"
+	// UnsupportedDomain is the code for signing requests targeting a chain or contract not on the allowlist
+	UnsupportedDomain string = "UNSUPPORTED_DOMAIN"
+	// WebAuthnCredentialExists is the code for registering a WebAuthn credential that is already registered
+	WebAuthnCredentialExists string = "WEBAUTHN_CREDENTIAL_EXISTS"
 )
@@ -1,19 +1,187 @@
 package password
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
+
+	passwordConfig "celeste/configs/password"
+)
+
+const (
+	// AlgorithmArgon2id hashes are encoded as $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+	AlgorithmArgon2id string = "argon2id"
+	// AlgorithmBcrypt hashes are encoded as $2a$<cost>$<salt and key>
+	AlgorithmBcrypt string = "bcrypt"
+)
+
+const (
+	argon2SaltLength uint32 = 16
+	argon2KeyLength  uint32 = 32
 )
 
+// Hasher hashes new passwords with the configured algorithm and verifies hashes of every supported algorithm
+type Hasher struct {
+	Algorithm         string
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	BcryptCost        int
+}
+
+// argon2Hash holds the decoded fields of an argon2id hash
+type argon2Hash struct {
+	version     int
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// NewHasher creates the hasher from the password configurations
+func NewHasher(config passwordConfig.Config) *Hasher {
+	return &Hasher{
+		Algorithm:         config.HashAlgorithm(),
+		Argon2Memory:      config.Argon2Memory(),
+		Argon2Iterations:  config.Argon2Iterations(),
+		Argon2Parallelism: config.Argon2Parallelism(),
+		BcryptCost:        config.BcryptCost(),
+	}
+}
+
 // CheckPasswordHash compares the password and hash version if equal
 func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-
-	return err == nil
+	return NewHasher(passwordConfig.Config{}).Verify(password, hash)
 }
 
-// HashPassword hash the password using bcrypt
+// HashPassword hash the password using the configured algorithm
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 10) // recommended default
+	return NewHasher(passwordConfig.Config{}).Hash(password)
+}
+
+// NeedsRehash reports whether the hash was made with another algorithm or parameters than the configured ones
+func NeedsRehash(hash string) bool {
+	return NewHasher(passwordConfig.Config{}).NeedsRehash(hash)
+}
+
+// Hash hashes the password with the algorithm of the hasher
+func (hasher *Hasher) Hash(password string) (string, error) {
+	switch hasher.Algorithm {
+	case AlgorithmArgon2id:
+		salt := make([]byte, argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+
+		key := argon2.IDKey([]byte(password), salt, hasher.Argon2Iterations, hasher.Argon2Memory, hasher.Argon2Parallelism, argon2KeyLength)
+
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version,
+			hasher.Argon2Memory,
+			hasher.Argon2Iterations,
+			hasher.Argon2Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key),
+		), nil
+	case AlgorithmBcrypt:
+		bytes, err := bcrypt.GenerateFromPassword([]byte(password), hasher.BcryptCost)
+
+		return string(bytes), err
+	default:
+		return "", fmt.Errorf("unsupported password hash algorithm: %s", hasher.Algorithm)
+	}
+}
+
+// Verify compares the password with a hash of any supported algorithm, the algorithm is read from the hash
+func (hasher *Hasher) Verify(password, hash string) bool {
+	switch hashAlgorithm(hash) {
+	case AlgorithmArgon2id:
+		decoded, err := decodeArgon2Hash(hash)
+		if err != nil {
+			return false
+		}
+
+		key := argon2.IDKey([]byte(password), decoded.salt, decoded.iterations, decoded.memory, decoded.parallelism, uint32(len(decoded.key)))
+
+		return subtle.ConstantTimeCompare(key, decoded.key) == 1
+	case AlgorithmBcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// NeedsRehash reports whether the hash was made with another algorithm or parameters than the ones of the hasher
+func (hasher *Hasher) NeedsRehash(hash string) bool {
+	if hashAlgorithm(hash) != hasher.Algorithm {
+		return true
+	}
+
+	switch hasher.Algorithm {
+	case AlgorithmArgon2id:
+		decoded, err := decodeArgon2Hash(hash)
+
+		return err != nil ||
+			decoded.version != argon2.Version ||
+			decoded.memory != hasher.Argon2Memory ||
+			decoded.iterations != hasher.Argon2Iterations ||
+			decoded.parallelism != hasher.Argon2Parallelism
+	case AlgorithmBcrypt:
+		cost, err := bcrypt.Cost([]byte(hash))
+
+		return err != nil || cost != hasher.BcryptCost
+	default:
+		return false
+	}
+}
+
+// hashAlgorithm detects the algorithm from the hash prefix, empty when unknown
+func hashAlgorithm(hash string) string {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return AlgorithmArgon2id
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return AlgorithmBcrypt
+	default:
+		return ""
+	}
+}
+
+// decodeArgon2Hash parses the fields of an encoded argon2id hash
+func decodeArgon2Hash(hash string) (argon2Hash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return argon2Hash{}, fmt.Errorf("invalid argon2id hash")
+	}
+
+	var decoded argon2Hash
+	_, err := fmt.Sscanf(parts[2], "v=%d", &decoded.version)
+	if err != nil {
+		return argon2Hash{}, err
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &decoded.memory, &decoded.iterations, &decoded.parallelism)
+	if err != nil {
+		return argon2Hash{}, err
+	}
+
+	decoded.salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2Hash{}, err
+	}
+
+	decoded.key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(decoded.key) == 0 {
+		return argon2Hash{}, fmt.Errorf("invalid argon2id hash key")
+	}
 
-	return string(bytes), err
+	return decoded, nil
 }
@@ -0,0 +1,88 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testHasher keeps the argon2id parameters small so the tests stay fast
+func testHasher() *Hasher {
+	return &Hasher{
+		Algorithm:         AlgorithmArgon2id,
+		Argon2Memory:      1024,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+		BcryptCost:        bcrypt.MinCost,
+	}
+}
+
+func TestHasherArgon2id(t *testing.T) {
+	hasher := testHasher()
+
+	hash, err := hasher.Hash("k7#Rv9!qLm2&Zx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("unexpected hash encoding %s", hash)
+	}
+
+	if !hasher.Verify("k7#Rv9!qLm2&Zx", hash) {
+		t.Error("expected password to match")
+	}
+	if hasher.Verify("k7#Rv9!qLm2&Zy", hash) {
+		t.Error("unexpected match of a wrong password")
+	}
+	if hasher.NeedsRehash(hash) {
+		t.Error("unexpected rehash of a current hash")
+	}
+
+	// a stronger configuration upgrades the existing hashes
+	hasher.Argon2Iterations = 2
+	if !hasher.NeedsRehash(hash) {
+		t.Error("expected rehash after the parameters changed")
+	}
+	if !hasher.Verify("k7#Rv9!qLm2&Zx", hash) {
+		t.Error("expected the previous parameters to still verify")
+	}
+}
+
+func TestHasherLegacyBcrypt(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("k7#Rv9!qLm2&Zx"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hasher := testHasher()
+	if !hasher.Verify("k7#Rv9!qLm2&Zx", string(legacy)) {
+		t.Error("expected legacy bcrypt hash to match")
+	}
+	if !hasher.NeedsRehash(string(legacy)) {
+		t.Error("expected legacy bcrypt hash to need a rehash")
+	}
+
+	hasher.Algorithm = AlgorithmBcrypt
+	if hasher.NeedsRehash(string(legacy)) {
+		t.Error("unexpected rehash of a bcrypt hash with the configured cost")
+	}
+}
+
+func TestHasherRejectsMalformedHashes(t *testing.T) {
+	hasher := testHasher()
+
+	for _, hash := range []string{"", "plaintext", "$argon2id$v=19$m=1024,t=1,p=1$c2FsdA", "$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5"} {
+		if hasher.Verify("", hash) {
+			t.Errorf("unexpected match of %q", hash)
+		}
+		if !hasher.NeedsRehash(hash) {
+			t.Errorf("expected %q to need a rehash", hash)
+		}
+	}
+
+	hasher.Algorithm = "md5"
+	if _, err := hasher.Hash("k7#Rv9!qLm2&Zx"); err == nil {
+		t.Error("expected an error for an unsupported algorithm")
+	}
+}
@@ -0,0 +1,185 @@
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	passwordConfig "celeste/configs/password"
+	apiError "celeste/internal/errors"
+)
+
+// MaxLength is the number of bytes bcrypt reads from a password, longer passwords would be silently truncated
+const MaxLength int = 72
+
+// minBannedTermLength skips short name and email fragments that would reject too many passwords
+const minBannedTermLength int = 4
+
+//go:embed breached.txt
+var bundledBreachedList []byte
+
+// Policy holds the rules new passwords must satisfy
+type Policy struct {
+	MinLength           int
+	MinCharacterClasses int
+	BannedWords         []string
+	// breached holds SHA-1 suffixes by their 5 character prefix, nil skips the breached check
+	breached map[string]map[string]struct{}
+}
+
+// NewPolicy creates the password policy from the password configurations and loads the breached password hash list
+func NewPolicy(config passwordConfig.Config) (*Policy, error) {
+	policy := &Policy{
+		MinLength:           config.MinLength(),
+		MinCharacterClasses: config.MinCharacterClasses(),
+		BannedWords:         config.BannedWords(),
+	}
+
+	if !config.CheckBreached() {
+		return policy, nil
+	}
+
+	var list io.Reader = bytes.NewReader(bundledBreachedList)
+	if file := config.BreachedListFile(); len(file) > 0 {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		list = f
+	}
+
+	breached, err := loadBreachedList(list)
+	if err != nil {
+		return nil, err
+	}
+	policy.breached = breached
+
+	return policy, nil
+}
+
+// Validate checks the password against every rule and returns the error code of the first rule it breaks
+// The user inputs, like the email and name, must not appear in the password
+func (policy *Policy) Validate(password string, userInputs ...string) error {
+	if len(password) > MaxLength {
+		return errors.New(apiError.PasswordTooLong)
+	}
+
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return errors.New(apiError.PasswordTooShort)
+	}
+
+	if characterClasses(password) < policy.MinCharacterClasses {
+		return errors.New(apiError.PasswordTooSimple)
+	}
+
+	lowerPassword := strings.ToLower(password)
+	for _, term := range bannedTerms(append(userInputs, policy.BannedWords...)) {
+		if strings.Contains(lowerPassword, term) {
+			return errors.New(apiError.PasswordContainsBannedWord)
+		}
+	}
+
+	if policy.IsBreached(password) {
+		return errors.New(apiError.PasswordBreached)
+	}
+
+	return nil
+}
+
+// IsBreached reports whether the password appears in the breached password hash list
+// The hashes are bucketed by their prefix like k-anonymity range queries so only one small bucket is compared
+func (policy *Policy) IsBreached(password string) bool {
+	if policy.breached == nil {
+		return false
+	}
+
+	hash := sha1.Sum([]byte(password))
+	hexHash := strings.ToUpper(hex.EncodeToString(hash[:]))
+
+	_, ok := policy.breached[hexHash[:5]][hexHash[5:]]
+
+	return ok
+}
+
+// loadBreachedList reads one uppercase or lowercase SHA-1 hash per line, an optional ":count" suffix is ignored
+func loadBreachedList(list io.Reader) (map[string]map[string]struct{}, error) {
+	breached := map[string]map[string]struct{}{}
+
+	scanner := bufio.NewScanner(list)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			line = line[:i]
+		}
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if len(line) != sha1.Size*2 {
+			return nil, errors.New("invalid breached password hash: " + line)
+		}
+
+		line = strings.ToUpper(line)
+		if breached[line[:5]] == nil {
+			breached[line[:5]] = map[string]struct{}{}
+		}
+		breached[line[:5]][line[5:]] = struct{}{}
+	}
+
+	return breached, scanner.Err()
+}
+
+// characterClasses counts the lowercase, uppercase, digit and symbol classes used by the password
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+
+	return lower + upper + digit + symbol
+}
+
+// bannedTerms lowercases the words and splits them on non alphanumeric characters so "jane.miller@mail.com" bans "jane" and "miller"
+// The domain of email addresses is dropped since it is shared by many users
+func bannedTerms(words []string) []string {
+	var terms []string
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if i := strings.LastIndexByte(word, '@'); i >= 0 {
+			word = word[:i]
+		}
+		if utf8.RuneCountInString(word) >= minBannedTermLength {
+			terms = append(terms, word)
+		}
+
+		fields := strings.FieldsFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, field := range fields {
+			if utf8.RuneCountInString(field) >= minBannedTermLength && field != word {
+				terms = append(terms, field)
+			}
+		}
+	}
+
+	return terms
+}
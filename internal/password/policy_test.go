@@ -0,0 +1,80 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	apiError "celeste/internal/errors"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	breached, err := loadBreachedList(strings.NewReader(string(bundledBreachedList)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := &Policy{
+		MinLength:           12,
+		MinCharacterClasses: 3,
+		BannedWords:         []string{"celeste"},
+		breached:            breached,
+	}
+
+	tests := []struct {
+		password string
+		expected string
+	}{
+		{"k7#Rv9!qLm2&Zx", ""},
+		{"Sh0rt!pass", apiError.PasswordTooShort},
+		{strings.Repeat("aB3!", 19), apiError.PasswordTooLong},
+		{"alllowercaseletters", apiError.PasswordTooSimple},
+		{"Jane-Rv9!qLm2&Zx", apiError.PasswordContainsBannedWord},
+		{"Miller#Rv9!qLm2", apiError.PasswordContainsBannedWord},
+		{"My-Celeste-9!q", apiError.PasswordContainsBannedWord},
+		{"Password123!", apiError.PasswordBreached},
+	}
+
+	for _, test := range tests {
+		err := policy.Validate(test.password, "jane.miller@mail.com", "Jane Miller")
+		if len(test.expected) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", test.password, err)
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("%s: expected %s, got %v", test.password, test.expected, err)
+		}
+	}
+}
+
+func TestPolicyIgnoresEmailDomain(t *testing.T) {
+	policy := &Policy{MinLength: 12, MinCharacterClasses: 3}
+
+	err := policy.Validate("Mailbox#Rv9!qLm2", "jane@mailbox.com")
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestLoadBreachedListRangeFormat(t *testing.T) {
+	// breach corpus downloads append the number of occurrences to each hash
+	breached, err := loadBreachedList(strings.NewReader("# comment\n5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy := &Policy{breached: breached}
+	if !policy.IsBreached("password") {
+		t.Error("expected password to be breached")
+	}
+	if policy.IsBreached("k7#Rv9!qLm2&Zx") {
+		t.Error("unexpected breached password")
+	}
+
+	_, err = loadBreachedList(strings.NewReader("not-a-hash\n"))
+	if err == nil {
+		t.Error("expected an error for an invalid hash")
+	}
+}
@@ -0,0 +1,33 @@
+package rbac
+
+// Roles seeded by the migrations
+const (
+	RoleAdmin   string = "admin"
+	RoleService string = "service"
+)
+
+// Permissions seeded by the migrations and granted to roles through the role permissions
+const (
+	PermissionAPIKeysManage   string = "api_keys:manage"
+	PermissionRolesManage     string = "roles:manage"
+	PermissionUsersDeactivate string = "users:deactivate"
+	PermissionUsersList       string = "users:list"
+	PermissionUsersRead       string = "users:read"
+	PermissionUsersUpdate     string = "users:update"
+)
+
+// Permissions lists every permission, the scopes an API key may be granted
+var Permissions = []string{
+	PermissionAPIKeysManage,
+	PermissionRolesManage,
+	PermissionUsersDeactivate,
+	PermissionUsersList,
+	PermissionUsersRead,
+	PermissionUsersUpdate,
+}
+
+// Claims of the access token carrying the roles of the subject and the permissions they grant
+const (
+	ClaimPermissions string = "permissions"
+	ClaimRoles       string = "roles"
+)
@@ -0,0 +1,211 @@
+package siwe
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Version is the only message version defined by EIP-4361
+const Version string = "1"
+
+// ClockSkew is how far in the future the issued at time of a message may be
+const ClockSkew = time.Minute
+
+// minNonceLength is the shortest nonce allowed by EIP-4361
+const minNonceLength int = 8
+
+const headerSuffix string = " wants you to sign in with your Ethereum account:"
+
+// Message holds the fields of an EIP-4361 message
+type Message struct {
+	Scheme         string
+	Domain         string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        *big.Int
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time
+	NotBefore      *time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// NewNonce returns a random alphanumeric nonce
+func NewNonce() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(nonce), nil
+}
+
+// Parse reads an EIP-4361 message, the address must be EIP-55 checksummed
+func Parse(message string) (Message, error) {
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	if len(lines) < 2 || !strings.HasSuffix(lines[0], headerSuffix) {
+		return Message{}, errors.New("invalid message header")
+	}
+
+	var parsed Message
+
+	parsed.Domain = strings.TrimSuffix(lines[0], headerSuffix)
+	if scheme, domain, ok := strings.Cut(parsed.Domain, "://"); ok {
+		parsed.Scheme, parsed.Domain = scheme, domain
+	}
+	if len(parsed.Domain) == 0 {
+		return Message{}, errors.New("missing domain")
+	}
+
+	parsed.Address = lines[1]
+	if !common.IsHexAddress(parsed.Address) || common.HexToAddress(parsed.Address).Hex() != parsed.Address {
+		return Message{}, errors.New("address must be EIP-55 checksummed")
+	}
+
+	// the statement is optional and surrounded by empty lines
+	i := 2
+	for i < len(lines) && len(lines[i]) == 0 {
+		i++
+	}
+	if i < len(lines) && !strings.HasPrefix(lines[i], "URI: ") {
+		parsed.Statement = lines[i]
+		i++
+		for i < len(lines) && len(lines[i]) == 0 {
+			i++
+		}
+	}
+
+	seen := map[string]bool{}
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if len(line) == 0 && i == len(lines)-1 {
+			break
+		}
+
+		if line == "Resources:" {
+			for i++; i < len(lines) && strings.HasPrefix(lines[i], "- "); i++ {
+				parsed.Resources = append(parsed.Resources, strings.TrimPrefix(lines[i], "- "))
+			}
+			if i < len(lines) && !(len(lines[i]) == 0 && i == len(lines)-1) {
+				return Message{}, errors.New("unexpected line after resources")
+			}
+			break
+		}
+
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok || seen[key] {
+			return Message{}, errors.New("invalid message field: " + line)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "URI":
+			parsed.URI = value
+		case "Version":
+			parsed.Version = value
+		case "Chain ID":
+			var ok bool
+			parsed.ChainID, ok = new(big.Int).SetString(value, 10)
+			if !ok || parsed.ChainID.Sign() <= 0 {
+				return Message{}, errors.New("invalid chain ID")
+			}
+		case "Nonce":
+			parsed.Nonce = value
+		case "Issued At":
+			parsed.IssuedAt, err = time.Parse(time.RFC3339, value)
+		case "Expiration Time":
+			parsed.ExpirationTime, err = parseTime(value)
+		case "Not Before":
+			parsed.NotBefore, err = parseTime(value)
+		case "Request ID":
+			parsed.RequestID = value
+		default:
+			return Message{}, errors.New("unknown message field: " + key)
+		}
+		if err != nil {
+			return Message{}, errors.New("invalid " + strings.ToLower(key))
+		}
+	}
+
+	if uri, err := url.Parse(parsed.URI); err != nil || !uri.IsAbs() {
+		return Message{}, errors.New("invalid URI")
+	}
+
+	if parsed.ChainID == nil || parsed.IssuedAt.IsZero() {
+		return Message{}, errors.New("missing chain ID or issued at")
+	}
+
+	if len(parsed.Nonce) < minNonceLength || !isAlphanumeric(parsed.Nonce) {
+		return Message{}, errors.New("nonce must be at least 8 alphanumeric characters")
+	}
+
+	return parsed, nil
+}
+
+// Validate checks the message targets the domain and one of the chains and is valid at the moment
+func (m Message) Validate(domain string, chainIDs []*big.Int, now time.Time) error {
+	if m.Version != Version {
+		return errors.New("unsupported message version")
+	}
+
+	if !strings.EqualFold(m.Domain, domain) {
+		return errors.New("domain mismatch")
+	}
+
+	allowed := false
+	for _, chainID := range chainIDs {
+		if chainID.Cmp(m.ChainID) == 0 {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return errors.New("chain not allowed")
+	}
+
+	if m.IssuedAt.After(now.Add(ClockSkew)) {
+		return errors.New("message issued in the future")
+	}
+
+	if m.ExpirationTime != nil && !now.Before(*m.ExpirationTime) {
+		return errors.New("message expired")
+	}
+
+	if m.NotBefore != nil && now.Before(*m.NotBefore) {
+		return errors.New("message not yet valid")
+	}
+
+	return nil
+}
+
+// parseTime parses an optional RFC 3339 timestamp
+func parseTime(value string) (*time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
+// isAlphanumeric reports whether the value only holds ASCII letters and digits
+func isAlphanumeric(value string) bool {
+	for _, c := range value {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,98 @@
+package siwe
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMessage = `example.com wants you to sign in with your Ethereum account:
+0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+
+I accept the Terms of Service: https://example.com/tos
+
+URI: https://example.com/login
+Version: 1
+Chain ID: 1
+Nonce: 32891756ab
+Issued At: 2026-01-01T00:00:00Z
+Expiration Time: 2026-01-01T00:10:00Z
+Resources:
+- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/
+- https://example.com/my-web2-claim.json`
+
+func TestParse(t *testing.T) {
+	message, err := Parse(testMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.Domain != "example.com" || message.Address != "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2" {
+		t.Errorf("unexpected header %s %s", message.Domain, message.Address)
+	}
+	if message.Statement != "I accept the Terms of Service: https://example.com/tos" {
+		t.Errorf("unexpected statement %q", message.Statement)
+	}
+	if message.ChainID.Int64() != 1 || message.Nonce != "32891756ab" || message.ExpirationTime == nil {
+		t.Errorf("unexpected fields %+v", message)
+	}
+	if len(message.Resources) != 2 {
+		t.Errorf("expected 2 resources, got %d", len(message.Resources))
+	}
+}
+
+func TestParseWithoutStatement(t *testing.T) {
+	message := strings.Replace(testMessage, "I accept the Terms of Service: https://example.com/tos\n\n", "", 1)
+
+	parsed, err := Parse("https://" + message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Scheme != "https" || parsed.Domain != "example.com" || len(parsed.Statement) != 0 {
+		t.Errorf("unexpected header %s %s %q", parsed.Scheme, parsed.Domain, parsed.Statement)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := map[string][]string{
+		"lowercase address": {"0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"},
+		"short nonce":       {"Nonce: 32891756ab", "Nonce: 1234"},
+		"missing chain":     {"Chain ID: 1\n", ""},
+		"unknown field":     {"Version: 1", "Version: 1\nSession: 1"},
+		"duplicate field":   {"Version: 1", "Version: 1\nVersion: 1"},
+		"bad header":        {"wants you to sign in", "wants to sign in"},
+	}
+
+	for name, replacement := range tests {
+		if _, err := Parse(strings.Replace(testMessage, replacement[0], replacement[1], 1)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	message, err := Parse(testMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainIDs := []*big.Int{big.NewInt(1)}
+	now := time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC)
+
+	if err := message.Validate("example.com", chainIDs, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := message.Validate("evil.com", chainIDs, now); err == nil {
+		t.Error("expected an error for another domain")
+	}
+	if err := message.Validate("example.com", []*big.Int{big.NewInt(10)}, now); err == nil {
+		t.Error("expected an error for another chain")
+	}
+	if err := message.Validate("example.com", chainIDs, now.Add(10*time.Minute)); err == nil {
+		t.Error("expected an error for an expired message")
+	}
+	if err := message.Validate("example.com", chainIDs, now.Add(-10*time.Minute)); err == nil {
+		t.Error("expected an error for a message issued in the future")
+	}
+}
@@ -0,0 +1,175 @@
+package token
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/segmentio/ksuid"
+
+	jwtConfig "celeste/configs/jwt"
+)
+
+// ClaimSessionID is the access token claim holding the ID of the session the token belongs to
+const ClaimSessionID string = "sid"
+
+// NewJWTAuth creates the token signer and verifier from the JWT configurations
+func NewJWTAuth(config jwtConfig.Config) (*jwtauth.JWTAuth, error) {
+	algorithm := config.Algorithm()
+
+	validateOptions := []jwt.ValidateOption{
+		jwt.WithAcceptableSkew(30 * time.Second),
+	}
+	if issuer := config.Issuer(); len(issuer) > 0 {
+		validateOptions = append(validateOptions, jwt.WithIssuer(issuer))
+	}
+	if audience := config.Audience(); len(audience) > 0 {
+		validateOptions = append(validateOptions, jwt.WithAudience(audience))
+	}
+
+	switch {
+	case strings.HasPrefix(algorithm, "HS"):
+		secret := config.Secret()
+		if len(secret) < 32 {
+			return nil, errors.New("JWT_SECRET must be at least 32 characters")
+		}
+
+		return jwtauth.New(algorithm, []byte(secret), nil, validateOptions...), nil
+	case strings.HasPrefix(algorithm, "RS"), strings.HasPrefix(algorithm, "PS"), strings.HasPrefix(algorithm, "ES"), algorithm == "EdDSA":
+		privateKey, err := loadPrivateKey(config.PrivateKeyFile())
+		if err != nil {
+			return nil, err
+		}
+
+		return jwtauth.New(algorithm, privateKey, privateKey.Public(), validateOptions...), nil
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %s", algorithm)
+	}
+}
+
+// IssueAccessToken signs an access token for the subject returning the token and its expiry
+func IssueAccessToken(tokenAuth *jwtauth.JWTAuth, config jwtConfig.Config, subject string, claims map[string]interface{}) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(config.AccessTokenTTL())
+
+	tokenClaims := map[string]interface{}{}
+	for key, value := range claims {
+		tokenClaims[key] = value
+	}
+
+	tokenClaims[jwt.SubjectKey] = subject
+	tokenClaims[jwt.JwtIDKey] = ksuid.New().String()
+	tokenClaims[jwt.IssuedAtKey] = now
+	tokenClaims[jwt.NotBeforeKey] = now
+	tokenClaims[jwt.ExpirationKey] = expiresAt
+	if issuer := config.Issuer(); len(issuer) > 0 {
+		tokenClaims[jwt.IssuerKey] = issuer
+	}
+	if audience := config.Audience(); len(audience) > 0 {
+		tokenClaims[jwt.AudienceKey] = audience
+	}
+
+	_, tokenString, err := tokenAuth.Encode(tokenClaims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// apiKeyPrefix starts every API key so leaked keys are easy to recognize
+const apiKeyPrefix string = "celeste_"
+
+// apiKeyLookupLength is the length of the hex encoded lookup prefix of an API key
+const apiKeyLookupLength int = 12
+
+// GenerateAPIKey generates an API key returning it with its lookup prefix and the hash to store
+// Keys look like celeste_<lookup prefix>_<secret>, the lookup prefix finds the key without scanning every hash
+func GenerateAPIKey() (string, string, string, error) {
+	lookup := make([]byte, apiKeyLookupLength/2)
+	if _, err := rand.Read(lookup); err != nil {
+		return "", "", "", err
+	}
+
+	secret, _, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	lookupPrefix := hex.EncodeToString(lookup)
+	apiKey := apiKeyPrefix + lookupPrefix + "_" + secret
+
+	return apiKey, lookupPrefix, HashOpaqueToken(apiKey), nil
+}
+
+// APIKeyLookupPrefix returns the lookup prefix of the API key, false when the key is malformed
+func APIKeyLookupPrefix(apiKey string) (string, bool) {
+	rest, ok := strings.CutPrefix(apiKey, apiKeyPrefix)
+	if !ok || len(rest) <= apiKeyLookupLength+1 || rest[apiKeyLookupLength] != '_' {
+		return "", false
+	}
+
+	return rest[:apiKeyLookupLength], true
+}
+
+// GenerateOpaqueToken generates a random URL safe token returning it with the hash to store
+func GenerateOpaqueToken() (string, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	opaqueToken := base64.RawURLEncoding.EncodeToString(secret)
+
+	return opaqueToken, HashOpaqueToken(opaqueToken), nil
+}
+
+// HashOpaqueToken returns the hex encoded SHA-256 hash of the token
+func HashOpaqueToken(opaqueToken string) string {
+	hash := sha256.Sum256([]byte(opaqueToken))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// loadPrivateKey reads a PKCS #8, PKCS #1 or SEC 1 PEM encoded private key
+func loadPrivateKey(keyFile string) (crypto.Signer, error) {
+	if len(keyFile) == 0 {
+		return nil, errors.New("JWT_PRIVATE_KEY_FILE is required for asymmetric algorithms")
+	}
+
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("invalid PEM private key")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key type")
+		}
+
+		return signer, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return x509.ParseECPrivateKey(block.Bytes)
+}
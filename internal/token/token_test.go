@@ -0,0 +1,77 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-chi/jwtauth/v5"
+
+	jwtConfig "celeste/configs/jwt"
+)
+
+func TestIssueAccessToken(t *testing.T) {
+	t.Setenv("JWT_ALGORITHM", "HS256")
+	t.Setenv("JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	t.Setenv("JWT_ISSUER", "celeste")
+	t.Setenv("JWT_ACCESS_TOKEN_TTL", "5m")
+
+	config := jwtConfig.Config{}
+	tokenAuth, err := NewJWTAuth(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletAddress := "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"
+	accessToken, expiresAt, err := IssueAccessToken(tokenAuth, config, walletAddress, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ttl := time.Until(expiresAt); ttl > 5*time.Minute || ttl < 4*time.Minute {
+		t.Errorf("unexpected token lifetime %s", ttl)
+	}
+
+	token, err := jwtauth.VerifyToken(tokenAuth, accessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Subject() != walletAddress || token.Issuer() != "celeste" {
+		t.Errorf("unexpected claims sub=%s iss=%s", token.Subject(), token.Issuer())
+	}
+
+	// tokens from another issuer must be rejected
+	t.Setenv("JWT_ISSUER", "other")
+	otherAuth, err := NewJWTAuth(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwtauth.VerifyToken(otherAuth, accessToken); err == nil {
+		t.Error("expected issuer mismatch error")
+	}
+
+	t.Setenv("JWT_SECRET", "short")
+	if _, err := NewJWTAuth(config); err == nil {
+		t.Error("expected short secret error")
+	}
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	apiKey, lookupPrefix, keyHash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, ok := APIKeyLookupPrefix(apiKey)
+	if !ok || parsed != lookupPrefix {
+		t.Errorf("expected lookup prefix %s, got %s", lookupPrefix, parsed)
+	}
+	if keyHash != HashOpaqueToken(apiKey) {
+		t.Error("expected the hash of the whole key")
+	}
+
+	for _, malformed := range []string{"", "celeste_", "celeste_0123456789ab", "other_0123456789ab_secret", "celeste_0123456789abXsecret"} {
+		if _, ok := APIKeyLookupPrefix(malformed); ok {
+			t.Errorf("expected %q to be rejected", malformed)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCodeRFC6238(t *testing.T) {
+	// SHA1 test vectors of RFC 6238 appendix B truncated to 6 digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, test := range tests {
+		code, err := Code(secret, Step(time.Unix(test.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != test.code {
+			t.Errorf("%d: expected %s, got %s", test.unix, test.code, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	code, _ := Code(secret, Step(now)-1)
+
+	step, ok := Validate(secret, code, now)
+	if !ok || step != Step(now)-1 {
+		t.Errorf("expected the previous step to be accepted, got %d %t", step, ok)
+	}
+
+	if _, ok := Validate(secret, code, now.Add(2*Period)); ok {
+		t.Error("unexpected acceptance of an expired code")
+	}
+	if _, ok := Validate(secret, "12345", now); ok {
+		t.Error("unexpected acceptance of a short code")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("Celeste", "user@celeste.test", "JBSWY3DPEHPK3PXP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Celeste:user@celeste.test" {
+		t.Errorf("unexpected uri %s", uri)
+	}
+	if uri.Query().Get("secret") != "JBSWY3DPEHPK3PXP" || uri.Query().Get("issuer") != "Celeste" {
+		t.Errorf("unexpected uri query %s", uri.RawQuery)
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' || strings.ContainsAny(code, "01ilo") {
+			t.Errorf("unexpected recovery code %s", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) != 10 {
+		t.Errorf("expected unique recovery codes, got %v", codes)
+	}
+
+	if NormalizeRecoveryCode(" ABCDE-FGHJK ") != "abcde-fghjk" {
+		t.Error("unexpected normalized recovery code")
+	}
+}
@@ -0,0 +1,94 @@
+package useragent
+
+import (
+	"strings"
+)
+
+// maxLength is the longest user agent kept, longer ones are truncated
+const maxLength int = 512
+
+// pattern names the browser or operating system of user agents containing the token
+type pattern struct {
+	token string
+	name  string
+}
+
+// browsers are matched in order since most user agents mention several engines
+var browsers = []pattern{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"CriOS/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+// operating systems are matched in order since iOS and Android user agents also mention macOS and Linux
+var operatingSystems = []pattern{
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// Device describes the browser and operating system of the user agent like "Chrome on macOS"
+// Clients that are not browsers are described by the product of the user agent
+func Device(userAgent string) string {
+	browser := match(userAgent, browsers)
+	os := match(userAgent, operatingSystems)
+
+	switch {
+	case len(browser) > 0 && len(os) > 0:
+		return browser + " on " + os
+	case len(browser) > 0:
+		return browser
+	case len(os) > 0:
+		return os
+	}
+
+	// like curl/8.4.0 or okhttp/4.12.0
+	product, _, _ := strings.Cut(userAgent, " ")
+	product, _, _ = strings.Cut(product, "/")
+	if len(product) == 0 {
+		return "Unknown device"
+	}
+
+	return Truncate(product, 255)
+}
+
+// Truncate cuts the value to the length in bytes without splitting a character
+func Truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+
+	for length > 0 && !isRuneStart(value[length]) {
+		length--
+	}
+
+	return value[:length]
+}
+
+// Normalize trims the user agent and truncates it to the stored length
+func Normalize(userAgent string) string {
+	return Truncate(strings.TrimSpace(userAgent), maxLength)
+}
+
+// match returns the name of the first pattern found in the user agent
+func match(userAgent string, patterns []pattern) string {
+	for _, pattern := range patterns {
+		if strings.Contains(userAgent, pattern.token) {
+			return pattern.name
+		}
+	}
+
+	return ""
+}
+
+// isRuneStart reports whether the byte starts a UTF-8 encoded character
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
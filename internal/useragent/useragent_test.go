@@ -0,0 +1,34 @@
+package useragent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDevice(t *testing.T) {
+	tests := map[string]string{
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36":                   "Chrome on macOS",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51":       "Edge on Windows",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1": "Safari on iOS",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36":                   "Chrome on Android",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0":                                                                  "Firefox on Linux",
+		"curl/8.4.0": "curl",
+		"":           "Unknown device",
+	}
+
+	for userAgent, expected := range tests {
+		if device := Device(userAgent); device != expected {
+			t.Errorf("expected %q for %q, got %q", expected, userAgent, device)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if normalized := Normalize(strings.Repeat("é", 300)); len(normalized) != maxLength {
+		t.Errorf("expected %d bytes, got %d", maxLength, len(normalized))
+	}
+
+	if normalized := Normalize("  curl/8.4.0 "); normalized != "curl/8.4.0" {
+		t.Errorf("unexpected user agent %q", normalized)
+	}
+}
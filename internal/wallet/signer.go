@@ -0,0 +1,78 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// SignMessage signs the message following EIP-191 (personal_sign)
+// The returned signature uses the legacy 27/28 recovery id expected by wallets and ecrecover
+func SignMessage(privateKey *ecdsa.PrivateKey, message []byte) ([]byte, error) {
+	return signHash(privateKey, accounts.TextHash(message))
+}
+
+// RecoverMessageSigner returns the wallet address that produced the EIP-191 signature
+func RecoverMessageSigner(message []byte, signature []byte) (string, error) {
+	return RecoverHashSigner(accounts.TextHash(message), signature)
+}
+
+// signHash signs the digest and shifts the recovery id to 27/28
+func signHash(privateKey *ecdsa.PrivateKey, hash []byte) ([]byte, error) {
+	signature, err := crypto.Sign(hash, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+
+	return signature, nil
+}
+
+// RecoverHashSigner recovers the signer address of a digest signed with a 27/28 recovery id
+func RecoverHashSigner(hash []byte, signature []byte) (string, error) {
+	if len(signature) != crypto.SignatureLength {
+		return "", errors.New("invalid signature length")
+	}
+
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return "", err
+	}
+
+	return crypto.PubkeyToAddress(*publicKey).Hex(), nil
+}
+
+// SignTypedData hashes the EIP-712 typed data and signs the digest
+// It returns the signature and the signed digest
+func SignTypedData(privateKey *ecdsa.PrivateKey, typedData apitypes.TypedData) ([]byte, []byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signature, err := signHash(privateKey, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return signature, hash, nil
+}
+
+// SignDynamicFeeTx signs the EIP-1559 transaction for the given chain
+func SignDynamicFeeTx(privateKey *ecdsa.PrivateKey, tx *types.DynamicFeeTx) (*types.Transaction, error) {
+	if tx.ChainID == nil || tx.ChainID.Sign() <= 0 {
+		return nil, errors.New("invalid chain id")
+	}
+
+	return types.SignNewTx(privateKey, types.LatestSignerForChainID(tx.ChainID), tx)
+}
@@ -0,0 +1,90 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/hashicorp/vault/shamir"
+
+	apiError "celeste/internal/errors"
+)
+
+// Address returns the checksummed wallet address of the private key
+func Address(privateKey *ecdsa.PrivateKey) string {
+	return crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+}
+
+// Combine reconstructs the private key from the base64 encoded Shamir shares
+func Combine(shares []string) (*ecdsa.PrivateKey, error) {
+	var byteShares [][]byte
+	for _, share := range shares {
+		byteShare, err := base64.StdEncoding.DecodeString(share)
+		if err != nil {
+			return nil, errors.New(apiError.InvalidShare)
+		}
+
+		byteShares = append(byteShares, byteShare)
+	}
+
+	recovered, err := shamir.Combine(byteShares)
+	if err != nil {
+		return nil, errors.New(apiError.InvalidShare)
+	}
+	defer clear(recovered)
+
+	privateKey, err := crypto.HexToECDSA(string(recovered))
+	if err != nil {
+		return nil, errors.New(apiError.InvalidShare)
+	}
+
+	return privateKey, nil
+}
+
+// CombineForAddress reconstructs the private key and verifies it derives the expected wallet address
+func CombineForAddress(shares []string, walletAddress string) (*ecdsa.PrivateKey, error) {
+	privateKey, err := Combine(shares)
+	if err != nil {
+		return nil, err
+	}
+
+	if crypto.PubkeyToAddress(privateKey.PublicKey) != common.HexToAddress(walletAddress) {
+		ZeroKey(privateKey)
+		return nil, errors.New(apiError.InvalidShare)
+	}
+
+	return privateKey, nil
+}
+
+// Split applies Shamir Secret Sharing (SSS) to the private key and returns the base64 encoded shares
+func Split(privateKey *ecdsa.PrivateKey, parts, threshold int) ([]string, error) {
+	privateKeyBytes := crypto.FromECDSA(privateKey)
+	defer clear(privateKeyBytes)
+
+	privateKeyEncoded := []byte(hexutil.Encode(privateKeyBytes)[2:]) // strip 0x
+	defer clear(privateKeyEncoded)
+
+	byteShares, err := shamir.Split(privateKeyEncoded, parts, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	var shares []string
+	for _, byteShare := range byteShares {
+		shares = append(shares, base64.StdEncoding.EncodeToString(byteShare))
+	}
+
+	return shares, nil
+}
+
+// ZeroKey wipes the private key scalar from memory
+func ZeroKey(privateKey *ecdsa.PrivateKey) {
+	if privateKey == nil || privateKey.D == nil {
+		return
+	}
+
+	clear(privateKey.D.Bits())
+}
@@ -0,0 +1,195 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+func TestSplitCombine(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletAddress := Address(privateKey)
+
+	shares, err := Split(privateKey, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// any two shares must reconstruct the wallet
+	for _, pair := range [][]string{{shares[0], shares[1]}, {shares[0], shares[2]}, {shares[1], shares[2]}} {
+		recovered, err := CombineForAddress(pair, walletAddress)
+		if err != nil {
+			t.Fatalf("combine failed: %v", err)
+		}
+
+		if Address(recovered) != walletAddress {
+			t.Errorf("recovered address %s, expected %s", Address(recovered), walletAddress)
+		}
+	}
+
+	// shares from another wallet must not pass the address check
+	otherKey, _ := crypto.GenerateKey()
+	otherShares, err := Split(otherKey, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CombineForAddress([]string{otherShares[0], otherShares[1]}, walletAddress); err == nil {
+		t.Error("expected address mismatch error")
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("hello celeste")
+	signature, err := SignMessage(privateKey, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := signature[crypto.RecoveryIDOffset]; v != 27 && v != 28 {
+		t.Errorf("unexpected recovery id %d", v)
+	}
+
+	signer, err := RecoverMessageSigner(message, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signer != Address(privateKey) {
+		t.Errorf("recovered signer %s, expected %s", signer, Address(privateKey))
+	}
+}
+
+func TestSignTypedData(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var typedData apitypes.TypedData
+	err = json.Unmarshal([]byte(`{
+		"types": {
+			"EIP712Domain": [
+				{"name": "name", "type": "string"},
+				{"name": "chainId", "type": "uint256"},
+				{"name": "verifyingContract", "type": "address"}
+			],
+			"Mail": [
+				{"name": "to", "type": "address"},
+				{"name": "contents", "type": "string"}
+			]
+		},
+		"primaryType": "Mail",
+		"domain": {
+			"name": "Celeste",
+			"chainId": 1,
+			"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+		},
+		"message": {
+			"to": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+			"contents": "Hello, Bob!"
+		}
+	}`), &typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature, hash, err := SignTypedData(privateKey, typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := RecoverHashSigner(hash, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signer != Address(privateKey) {
+		t.Errorf("recovered signer %s, expected %s", signer, Address(privateKey))
+	}
+}
+
+func TestSignDynamicFeeTx(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(privateKey.PublicKey)
+	to := common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")
+
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		from: {Balance: big.NewInt(params.Ether)},
+	})
+	defer backend.Close()
+	client := backend.Client()
+
+	ctx := context.Background()
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := SignDynamicFeeTx(privateKey, &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: new(big.Int).Add(head.BaseFee, big.NewInt(2*params.GWei)),
+		Gas:       params.TxGas,
+		To:        &to,
+		Value:     big.NewInt(params.GWei),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the raw transaction must decode and be accepted by the chain
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded types.Transaction
+	if err := decoded.UnmarshalBinary(rawTx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.SendTransaction(ctx, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+
+	receipt, err := client.TransactionReceipt(ctx, decoded.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Errorf("transaction failed with status %d", receipt.Status)
+	}
+
+	balance, err := client.BalanceAt(ctx, to, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(big.NewInt(params.GWei)) != 0 {
+		t.Errorf("recipient balance %s, expected %d", balance, big.NewInt(params.GWei))
+	}
+}
@@ -0,0 +1,131 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// COSE algorithm identifiers of the supported credential keys
+const (
+	AlgorithmES256 int64 = -7
+	AlgorithmEdDSA int64 = -8
+	AlgorithmRS256 int64 = -257
+)
+
+// SupportedAlgorithms lists the algorithms offered to authenticators, in order of preference
+var SupportedAlgorithms = []int64{AlgorithmES256, AlgorithmEdDSA, AlgorithmRS256}
+
+// COSE key parameters of RFC 9053
+const (
+	coseKeyType      int64 = 1
+	coseKeyAlgorithm int64 = 3
+	coseCurve        int64 = -1
+	coseX            int64 = -2
+	coseY            int64 = -3
+	coseRSAModulus   int64 = -1
+	coseRSAExponent  int64 = -2
+
+	coseKeyTypeOKP int64 = 1
+	coseKeyTypeEC2 int64 = 2
+	coseKeyTypeRSA int64 = 3
+
+	coseCurveP256    int64 = 1
+	coseCurveEd25519 int64 = 6
+)
+
+// minRSABits rejects RSA keys too weak to trust
+const minRSABits int = 2048
+
+// parsePublicKey decodes a COSE key into a public key and its algorithm
+func parsePublicKey(coseKey []byte) (crypto.PublicKey, int64, error) {
+	decoded, rest, err := decodeCBOR(coseKey)
+	if err != nil {
+		return nil, 0, err
+	} else if len(rest) > 0 {
+		return nil, 0, errors.New("trailing data after the credential public key")
+	}
+
+	key, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, 0, errors.New("credential public key is not a map")
+	}
+
+	keyType, _ := key[coseKeyType].(int64)
+	algorithm, _ := key[coseKeyAlgorithm].(int64)
+
+	switch {
+	case keyType == coseKeyTypeEC2 && algorithm == AlgorithmES256:
+		curve, _ := key[coseCurve].(int64)
+		x, _ := key[coseX].([]byte)
+		y, _ := key[coseY].([]byte)
+		if curve != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, errors.New("invalid ES256 public key")
+		}
+
+		publicKey := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !publicKey.Curve.IsOnCurve(publicKey.X, publicKey.Y) {
+			return nil, 0, errors.New("ES256 public key is not on the curve")
+		}
+
+		return publicKey, algorithm, nil
+	case keyType == coseKeyTypeOKP && algorithm == AlgorithmEdDSA:
+		curve, _ := key[coseCurve].(int64)
+		x, _ := key[coseX].([]byte)
+		if curve != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, errors.New("invalid EdDSA public key")
+		}
+
+		return ed25519.PublicKey(x), algorithm, nil
+	case keyType == coseKeyTypeRSA && algorithm == AlgorithmRS256:
+		modulus, _ := key[coseRSAModulus].([]byte)
+		exponent, _ := key[coseRSAExponent].([]byte)
+		if len(modulus)*8 < minRSABits || len(exponent) == 0 || len(exponent) > 4 {
+			return nil, 0, errors.New("invalid RS256 public key")
+		}
+
+		publicKey := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(new(big.Int).SetBytes(exponent).Int64()),
+		}
+
+		return publicKey, algorithm, nil
+	default:
+		return nil, 0, errors.New("unsupported credential public key algorithm")
+	}
+}
+
+// verifySignature checks the signature of the data with the COSE key
+func verifySignature(coseKey, data, signature []byte) error {
+	publicKey, _, err := parsePublicKey(coseKey)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(data)
+
+	var ok bool
+	switch publicKey := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(publicKey, digest[:], signature)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(publicKey, data, signature)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature) == nil
+	}
+
+	if !ok {
+		return errors.New("invalid assertion signature")
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+// Ceremony types found in the client data
+const (
+	CeremonyCreate string = "webauthn.create"
+	CeremonyGet    string = "webauthn.get"
+)
+
+// Authenticator data flags
+const (
+	flagUserPresent            byte = 0x01
+	flagUserVerified           byte = 0x04
+	flagAttestedCredentialData byte = 0x40
+)
+
+// challengeLength is the size of the random challenges in bytes
+const challengeLength int = 32
+
+// maxCredentialIDLength is the largest credential ID allowed by the specification
+const maxCredentialIDLength int = 1023
+
+// ErrSignCount is returned when the signature counter did not increase, a sign of a cloned authenticator
+var ErrSignCount = errors.New("signature counter did not increase")
+
+// Encoding is the unpadded base64url encoding used for challenges and credential IDs
+var Encoding = base64.RawURLEncoding
+
+// RelyingParty holds the server side of the ceremonies
+type RelyingParty struct {
+	ID                      string   // domain the credentials are scoped to
+	Origins                 []string // origins the ceremonies may run on
+	RequireUserVerification bool
+}
+
+// ClientData holds the client data collected by the browser
+type ClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// Credential holds a registered credential
+type Credential struct {
+	ID        []byte
+	PublicKey []byte // COSE encoded public key
+	SignCount uint32
+}
+
+// NewChallenge returns a random base64url encoded challenge
+func NewChallenge() (string, error) {
+	challenge := make([]byte, challengeLength)
+	if _, err := rand.Read(challenge); err != nil {
+		return "", err
+	}
+
+	return Encoding.EncodeToString(challenge), nil
+}
+
+// ParseClientData decodes the client data JSON of a ceremony
+func ParseClientData(clientDataJSON []byte) (ClientData, error) {
+	var clientData ClientData
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return ClientData{}, errors.New("invalid client data")
+	}
+
+	return clientData, nil
+}
+
+// UserVerified reports whether the authenticator verified the user, e.g. with a PIN or biometric check, rather than only their presence
+func UserVerified(authenticatorData []byte) bool {
+	return len(authenticatorData) > 32 && authenticatorData[32]&flagUserVerified != 0
+}
+
+// VerifyRegistration checks the response of a registration ceremony and returns the new credential
+// Attestation statements are not verified, credentials are trusted as reported like with none attestation
+func (rp *RelyingParty) VerifyRegistration(challenge string, clientDataJSON, attestationObject []byte) (Credential, error) {
+	err := rp.verifyClientData(clientDataJSON, CeremonyCreate, challenge)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	decoded, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	attestation, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return Credential{}, errors.New("attestation object is not a map")
+	}
+
+	authenticatorData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return Credential{}, errors.New("attestation object has no authenticator data")
+	}
+
+	flags, signCount, err := rp.verifyAuthenticatorData(authenticatorData)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	if flags&flagAttestedCredentialData == 0 {
+		return Credential{}, errors.New("authenticator data has no attested credential")
+	}
+
+	// the attested credential data follows the 37 byte header: aaguid, credential ID length, credential ID and public key
+	attested := authenticatorData[37:]
+	if len(attested) < 18 {
+		return Credential{}, errors.New("invalid attested credential data")
+	}
+
+	credentialIDLength := int(binary.BigEndian.Uint16(attested[16:18]))
+	if credentialIDLength == 0 || credentialIDLength > maxCredentialIDLength || len(attested) < 18+credentialIDLength {
+		return Credential{}, errors.New("invalid credential ID")
+	}
+
+	credentialID := attested[18 : 18+credentialIDLength]
+
+	// extensions may follow the public key, so only the first item is kept
+	keyData := attested[18+credentialIDLength:]
+	_, rest, err := decodeCBOR(keyData)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	publicKey := keyData[:len(keyData)-len(rest)]
+	if _, _, err := parsePublicKey(publicKey); err != nil {
+		return Credential{}, err
+	}
+
+	return Credential{
+		ID:        append([]byte(nil), credentialID...),
+		PublicKey: append([]byte(nil), publicKey...),
+		SignCount: signCount,
+	}, nil
+}
+
+// VerifyAssertion checks the response of an authentication ceremony with the stored credential and returns the new signature counter
+func (rp *RelyingParty) VerifyAssertion(challenge string, credential Credential, clientDataJSON, authenticatorData, signature []byte) (uint32, error) {
+	err := rp.verifyClientData(clientDataJSON, CeremonyGet, challenge)
+	if err != nil {
+		return 0, err
+	}
+
+	_, signCount, err := rp.verifyAuthenticatorData(authenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signedData := append(append([]byte(nil), authenticatorData...), clientDataHash[:]...)
+
+	err = verifySignature(credential.PublicKey, signedData, signature)
+	if err != nil {
+		return 0, err
+	}
+
+	// authenticators without a counter always report zero
+	if (signCount != 0 || credential.SignCount != 0) && signCount <= credential.SignCount {
+		return 0, ErrSignCount
+	}
+
+	return signCount, nil
+}
+
+// verifyClientData checks the ceremony type, challenge and origin of the client data
+func (rp *RelyingParty) verifyClientData(clientDataJSON []byte, ceremony, challenge string) error {
+	clientData, err := ParseClientData(clientDataJSON)
+	if err != nil {
+		return err
+	}
+
+	if clientData.Type != ceremony {
+		return errors.New("unexpected ceremony type")
+	}
+
+	if len(challenge) == 0 || subtle.ConstantTimeCompare([]byte(clientData.Challenge), []byte(challenge)) != 1 {
+		return errors.New("challenge mismatch")
+	}
+
+	if clientData.CrossOrigin || !slices.Contains(rp.Origins, clientData.Origin) {
+		return errors.New("origin not allowed")
+	}
+
+	return nil
+}
+
+// verifyAuthenticatorData checks the relying party ID hash and the user flags and returns the flags and signature counter
+func (rp *RelyingParty) verifyAuthenticatorData(authenticatorData []byte) (byte, uint32, error) {
+	if len(authenticatorData) < 37 {
+		return 0, 0, errors.New("invalid authenticator data")
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(authenticatorData[:32], rpIDHash[:]) {
+		return 0, 0, errors.New("relying party ID mismatch")
+	}
+
+	flags := authenticatorData[32]
+	if flags&flagUserPresent == 0 {
+		return 0, 0, errors.New("user not present")
+	}
+
+	if rp.RequireUserVerification && flags&flagUserVerified == 0 {
+		return 0, 0, errors.New("user not verified")
+	}
+
+	return flags, binary.BigEndian.Uint32(authenticatorData[33:37]), nil
+}
@@ -0,0 +1,268 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+)
+
+const (
+	testRPID   = "celeste.test"
+	testOrigin = "https://celeste.test"
+)
+
+var testRP = &RelyingParty{
+	ID:      testRPID,
+	Origins: []string{testOrigin},
+}
+
+// testAuthenticator signs ceremonies like a platform authenticator with a P-256 key
+type testAuthenticator struct {
+	privateKey   *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testAuthenticator{privateKey: privateKey, credentialID: []byte("test-credential")}
+}
+
+func (a *testAuthenticator) publicKey() []byte {
+	return encodeCBOR(map[int64]interface{}{
+		coseKeyType:      coseKeyTypeEC2,
+		coseKeyAlgorithm: AlgorithmES256,
+		coseCurve:        coseCurveP256,
+		coseX:            a.privateKey.X.FillBytes(make([]byte, 32)),
+		coseY:            a.privateKey.Y.FillBytes(make([]byte, 32)),
+	})
+}
+
+func (a *testAuthenticator) authenticatorData(rpID string, flags byte, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	data := append([]byte(nil), rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.publicKey()...)
+	}
+
+	return data
+}
+
+func (a *testAuthenticator) register(challenge string) ([]byte, []byte) {
+	clientDataJSON := clientData(CeremonyCreate, challenge, testOrigin)
+	attestationObject := encodeCBOR(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authenticatorData(testRPID, flagUserPresent|flagUserVerified|flagAttestedCredentialData, true),
+	})
+
+	return clientDataJSON, attestationObject
+}
+
+func (a *testAuthenticator) assert(t *testing.T, challenge string) ([]byte, []byte, []byte) {
+	t.Helper()
+
+	a.signCount++
+	clientDataJSON := clientData(CeremonyGet, challenge, testOrigin)
+	authenticatorData := a.authenticatorData(testRPID, flagUserPresent|flagUserVerified, false)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authenticatorData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.privateKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return clientDataJSON, authenticatorData, signature
+}
+
+func clientData(ceremony, challenge, origin string) []byte {
+	data, _ := json.Marshal(ClientData{Type: ceremony, Challenge: challenge, Origin: origin})
+	return data
+}
+
+// encodeCBOR encodes the subset of CBOR produced by authenticators, map keys are sorted for stable output
+func encodeCBOR(value interface{}) []byte {
+	header := func(majorType byte, argument uint64) []byte {
+		switch {
+		case argument < 24:
+			return []byte{majorType<<5 | byte(argument)}
+		case argument < 1<<8:
+			return []byte{majorType<<5 | 24, byte(argument)}
+		case argument < 1<<16:
+			return binary.BigEndian.AppendUint16([]byte{majorType<<5 | 25}, uint16(argument))
+		default:
+			return binary.BigEndian.AppendUint32([]byte{majorType<<5 | 26}, uint32(argument))
+		}
+	}
+
+	switch value := value.(type) {
+	case int64:
+		if value < 0 {
+			return header(1, uint64(-1-value))
+		}
+		return header(0, uint64(value))
+	case []byte:
+		return append(header(2, uint64(len(value))), value...)
+	case string:
+		return append(header(3, uint64(len(value))), value...)
+	case map[int64]interface{}:
+		keys := make([]int64, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+		out := header(5, uint64(len(value)))
+		for _, key := range keys {
+			out = append(out, encodeCBOR(key)...)
+			out = append(out, encodeCBOR(value[key])...)
+		}
+		return out
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		out := header(5, uint64(len(value)))
+		for _, key := range keys {
+			out = append(out, encodeCBOR(key)...)
+			out = append(out, encodeCBOR(value[key])...)
+		}
+		return out
+	default:
+		panic("unsupported CBOR value")
+	}
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	authenticator := newTestAuthenticator(t)
+
+	challenge, err := NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientDataJSON, attestationObject := authenticator.register(challenge)
+	credential, err := testRP.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(credential.ID) != string(authenticator.credentialID) {
+		t.Errorf("expected credential ID %q, got %q", authenticator.credentialID, credential.ID)
+	}
+
+	challenge, _ = NewChallenge()
+	clientDataJSON, authenticatorData, signature := authenticator.assert(t, challenge)
+
+	signCount, err := testRP.VerifyAssertion(challenge, credential, clientDataJSON, authenticatorData, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signCount != 1 {
+		t.Errorf("expected sign count 1, got %d", signCount)
+	}
+
+	// a replayed assertion carries a counter that did not increase
+	credential.SignCount = signCount
+	_, err = testRP.VerifyAssertion(challenge, credential, clientDataJSON, authenticatorData, signature)
+	if !errors.Is(err, ErrSignCount) {
+		t.Errorf("expected sign count error, got %v", err)
+	}
+}
+
+func TestVerifyRegistrationRejects(t *testing.T) {
+	authenticator := newTestAuthenticator(t)
+	challenge, _ := NewChallenge()
+	_, attestationObject := authenticator.register(challenge)
+
+	tests := map[string][]byte{
+		"wrong challenge": clientData(CeremonyCreate, "other", testOrigin),
+		"wrong origin":    clientData(CeremonyCreate, challenge, "https://evil.test"),
+		"wrong ceremony":  clientData(CeremonyGet, challenge, testOrigin),
+	}
+
+	for name, clientDataJSON := range tests {
+		if _, err := testRP.VerifyRegistration(challenge, clientDataJSON, attestationObject); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	otherRP := &RelyingParty{ID: "other.test", Origins: []string{testOrigin}}
+	clientDataJSON, attestationObject := authenticator.register(challenge)
+	if _, err := otherRP.VerifyRegistration(challenge, clientDataJSON, attestationObject); err == nil {
+		t.Error("expected an error for a credential scoped to another relying party")
+	}
+}
+
+func TestVerifyAssertionRejects(t *testing.T) {
+	authenticator := newTestAuthenticator(t)
+	credential := Credential{ID: authenticator.credentialID, PublicKey: authenticator.publicKey()}
+
+	challenge, _ := NewChallenge()
+	clientDataJSON, authenticatorData, signature := authenticator.assert(t, challenge)
+
+	signature[len(signature)-1] ^= 0xff
+	if _, err := testRP.VerifyAssertion(challenge, credential, clientDataJSON, authenticatorData, signature); err == nil {
+		t.Error("expected an error for a tampered signature")
+	}
+
+	// user verification is required but the flag is missing
+	strictRP := &RelyingParty{ID: testRPID, Origins: []string{testOrigin}, RequireUserVerification: true}
+	authenticatorData = authenticator.authenticatorData(testRPID, flagUserPresent, false)
+	if _, err := strictRP.VerifyAssertion(challenge, credential, clientDataJSON, authenticatorData, signature); err == nil {
+		t.Error("expected an error without user verification")
+	}
+}
+
+func TestParsePublicKeyEdDSA(t *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	coseKey := encodeCBOR(map[int64]interface{}{
+		coseKeyType:      coseKeyTypeOKP,
+		coseKeyAlgorithm: AlgorithmEdDSA,
+		coseCurve:        coseCurveEd25519,
+		coseX:            []byte(publicKey),
+	})
+
+	data := []byte("signed data")
+	if err := verifySignature(coseKey, data, ed25519.Sign(privateKey, data)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecodeCBORRejectsMalformed(t *testing.T) {
+	tests := map[string][]byte{
+		"empty":            {},
+		"truncated string": {0x45, 0x01},
+		"huge array":       {0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"indefinite map":   {0xbf},
+		"array key":        {0xa1, 0x80, 0x01},
+	}
+
+	for name, data := range tests {
+		if _, _, err := decodeCBOR(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
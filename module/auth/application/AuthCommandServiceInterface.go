@@ -0,0 +1,47 @@
+package application
+
+import (
+	"context"
+
+	"celeste/module/auth/infrastructure/service/types"
+)
+
+// AuthCommandServiceInterface holds the implementable methods for the auth command service
+type AuthCommandServiceInterface interface {
+	// AuthenticateAPIKey verifies the API key and returns it with its scopes
+	AuthenticateAPIKey(ctx context.Context, apiKey string) (types.APIKeyResult, error)
+	// BeginWebAuthnLogin starts a passkey login
+	BeginWebAuthnLogin(ctx context.Context) (types.BeginWebAuthnLoginResult, error)
+	// BeginWebAuthnRegistration starts the registration of a passkey for the user
+	BeginWebAuthnRegistration(ctx context.Context, walletAddress string) (types.BeginWebAuthnRegistrationResult, error)
+	// CreateAPIKey issues an API key with the scopes
+	CreateAPIKey(ctx context.Context, data types.CreateAPIKey) (types.CreateAPIKeyResult, error)
+	// CreateSIWENonce issues a single use nonce for a Sign-In With Ethereum message
+	CreateSIWENonce(ctx context.Context) (types.SIWENonceResult, error)
+	// DeleteUserWebAuthnCredentials removes every passkey of the user
+	DeleteUserWebAuthnCredentials(ctx context.Context, walletAddress string) error
+	// FinishWebAuthnLogin verifies the passkey assertion and issues an access and refresh token
+	FinishWebAuthnLogin(ctx context.Context, data types.FinishWebAuthnLogin) (types.TokenResult, error)
+	// FinishWebAuthnRegistration verifies and stores the passkey created by the authenticator
+	FinishWebAuthnRegistration(ctx context.Context, data types.FinishWebAuthnRegistration) error
+	// GrantUserRole grants the role to the user
+	GrantUserRole(ctx context.Context, data types.UserRole) error
+	// Login verifies the user credentials and issues an access and refresh token
+	Login(ctx context.Context, data types.Login) (types.TokenResult, error)
+	// Logout revokes the token family of the refresh token
+	Logout(ctx context.Context, refreshToken string) error
+	// RefreshToken rotates the refresh token and issues a new access token
+	RefreshToken(ctx context.Context, refreshToken string) (types.TokenResult, error)
+	// RevokeAPIKey revokes the API key
+	RevokeAPIKey(ctx context.Context, id string) error
+	// RevokeSession revokes the session of the user
+	RevokeSession(ctx context.Context, data types.RevokeSession) error
+	// RevokeUserRole revokes the role from the user
+	RevokeUserRole(ctx context.Context, data types.UserRole) error
+	// RevokeUserSessions revokes every session and refresh token of the user
+	RevokeUserSessions(ctx context.Context, walletAddress string) error
+	// VerifySIWE checks the signed Sign-In With Ethereum message and issues an access and refresh token
+	VerifySIWE(ctx context.Context, data types.VerifySIWE) (types.TokenResult, error)
+	// VerifySession rejects the session when it was revoked or expired
+	VerifySession(ctx context.Context, id string) error
+}
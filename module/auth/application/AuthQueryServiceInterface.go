@@ -0,0 +1,15 @@
+package application
+
+import (
+	"context"
+
+	"celeste/module/auth/domain/entity"
+)
+
+// AuthQueryServiceInterface holds the implementable methods for the auth query service
+type AuthQueryServiceInterface interface {
+	// GetAPIKeys get all API keys
+	GetAPIKeys(ctx context.Context) ([]entity.APIKey, error)
+	// GetSessions get the active sessions of a user
+	GetSessions(ctx context.Context, walletAddress string) ([]entity.Session, error)
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+)
+
+// APIKey holds the API key entity fields
+// Scopes are the comma separated permissions granted to the key
+type APIKey struct {
+	ID         string
+	Name       string
+	Prefix     string
+	KeyHash    string `db:"key_hash"`
+	Scopes     string
+	CreatedBy  *string    `db:"created_by"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+// GetModelName returns the model name of API key entity that can be used for naming schemas
+func (entity *APIKey) GetModelName() string {
+	return "api_keys"
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+)
+
+// RefreshToken holds the refresh token entity fields
+// Tokens issued from the same login share a family, rotating a token marks it as rotated and issues the next one
+type RefreshToken struct {
+	ID            string
+	FamilyID      string     `db:"family_id"`
+	WalletAddress string     `db:"wallet_address"`
+	TokenHash     string     `db:"token_hash"`
+	ExpiresAt     time.Time  `db:"expires_at"`
+	RotatedAt     *time.Time `db:"rotated_at"`
+	RevokedAt     *time.Time `db:"revoked_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+// GetModelName returns the model name of refresh token entity that can be used for naming schemas
+func (entity *RefreshToken) GetModelName() string {
+	return "refresh_tokens"
+}
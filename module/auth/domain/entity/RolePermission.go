@@ -0,0 +1,12 @@
+package entity
+
+// RolePermission holds a permission granted by a role
+type RolePermission struct {
+	Role       string
+	Permission string
+}
+
+// GetModelName returns the model name of role permission entity that can be used for naming schemas
+func (entity *RolePermission) GetModelName() string {
+	return "role_permissions"
+}
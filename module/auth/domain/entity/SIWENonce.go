@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+)
+
+// SIWENonce holds the Sign-In With Ethereum nonce entity fields
+type SIWENonce struct {
+	ID        string
+	NonceHash string    `db:"nonce_hash"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// GetModelName returns the model name of SIWE nonce entity that can be used for naming schemas
+func (entity *SIWENonce) GetModelName() string {
+	return "siwe_nonces"
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+)
+
+// Session holds the session entity fields, a session is a refresh token family and shares its ID
+type Session struct {
+	ID            string
+	WalletAddress string `db:"wallet_address"`
+	Device        string
+	IPAddress     string     `db:"ip_address"`
+	UserAgent     string     `db:"user_agent"`
+	LastSeenAt    time.Time  `db:"last_seen_at"`
+	ExpiresAt     time.Time  `db:"expires_at"`
+	RevokedAt     *time.Time `db:"revoked_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+// GetModelName returns the model name of session entity that can be used for naming schemas
+func (entity *Session) GetModelName() string {
+	return "sessions"
+}
@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+)
+
+// UserRole holds the role granted to a user
+type UserRole struct {
+	WalletAddress string `db:"wallet_address"`
+	Role          string
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// GetModelName returns the model name of user role entity that can be used for naming schemas
+func (entity *UserRole) GetModelName() string {
+	return "user_roles"
+}
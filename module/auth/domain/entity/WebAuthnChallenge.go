@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+)
+
+// WebAuthnChallenge holds the WebAuthn challenge entity fields
+// Registration challenges belong to a user, login challenges are issued before the user is known
+type WebAuthnChallenge struct {
+	ID            string
+	WalletAddress *string `db:"wallet_address"`
+	Ceremony      string
+	ChallengeHash string    `db:"challenge_hash"`
+	ExpiresAt     time.Time `db:"expires_at"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// GetModelName returns the model name of WebAuthn challenge entity that can be used for naming schemas
+func (entity *WebAuthnChallenge) GetModelName() string {
+	return "webauthn_challenges"
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+)
+
+// WebAuthnCredential holds the WebAuthn credential entity fields
+// The credential ID is base64url encoded and the public key is COSE encoded
+type WebAuthnCredential struct {
+	ID            string
+	WalletAddress string `db:"wallet_address"`
+	CredentialID  string `db:"credential_id"`
+	PublicKey     []byte `db:"public_key"`
+	SignCount     uint32 `db:"sign_count"`
+	Name          string
+	LastUsedAt    *time.Time `db:"last_used_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+// GetModelName returns the model name of WebAuthn credential entity that can be used for naming schemas
+func (entity *WebAuthnCredential) GetModelName() string {
+	return "webauthn_credentials"
+}
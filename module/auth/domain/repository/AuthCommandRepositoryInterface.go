@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"celeste/module/auth/infrastructure/repository/types"
+)
+
+// AuthCommandRepositoryInterface holds the implementable methods for auth command repository
+type AuthCommandRepositoryInterface interface {
+	// DeleteSIWENonce consumes the SIWE nonce
+	DeleteSIWENonce(id string) error
+	// DeleteUserRole revokes the role from the user
+	DeleteUserRole(data types.UserRole) error
+	// DeleteWebAuthnChallenge consumes the WebAuthn challenge
+	DeleteWebAuthnChallenge(id string) error
+	// DeleteWebAuthnCredentialsByWalletAddress removes every WebAuthn credential of the user
+	DeleteWebAuthnCredentialsByWalletAddress(walletAddress string) error
+	// InsertAPIKey inserts a new API key
+	InsertAPIKey(data types.CreateAPIKey) error
+	// InsertSIWENonce inserts a new SIWE nonce
+	InsertSIWENonce(data types.CreateSIWENonce) error
+	// InsertSession inserts a new session along with the first refresh token of its family
+	InsertSession(data types.CreateSession) error
+	// InsertUserRole grants the role to the user
+	InsertUserRole(data types.UserRole) error
+	// InsertWebAuthnChallenge inserts a new WebAuthn challenge
+	InsertWebAuthnChallenge(data types.CreateWebAuthnChallenge) error
+	// InsertWebAuthnCredential inserts a new WebAuthn credential
+	InsertWebAuthnCredential(data types.CreateWebAuthnCredential) error
+	// RevokeAPIKey revokes the API key
+	RevokeAPIKey(id string) error
+	// RevokeRefreshTokenFamily revokes every refresh token of the family
+	RevokeRefreshTokenFamily(familyID string) error
+	// RevokeSessionsByWalletAddress revokes every session and refresh token of the user
+	RevokeSessionsByWalletAddress(walletAddress string) error
+	// RotateRefreshToken marks the refresh token as rotated and inserts the next token of the family
+	RotateRefreshToken(data types.RotateRefreshToken) error
+	// UpdateAPIKeyLastUsedAt records the API key was just used
+	UpdateAPIKeyLastUsedAt(id string) error
+	// UpdateSessionLastSeenAt records the session was just used
+	UpdateSessionLastSeenAt(id string) error
+	// UpdateWebAuthnCredentialSignCount stores the signature counter of the last login with the credential
+	UpdateWebAuthnCredentialSignCount(data types.UpdateWebAuthnCredentialSignCount) error
+}
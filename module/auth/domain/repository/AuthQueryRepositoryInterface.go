@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"celeste/module/auth/domain/entity"
+)
+
+// AuthQueryRepositoryInterface holds the implementable methods for auth query repository
+type AuthQueryRepositoryInterface interface {
+	// SelectAPIKeyByPrefix select an API key by its lookup prefix
+	SelectAPIKeyByPrefix(prefix string) (entity.APIKey, error)
+	// SelectAPIKeys select every API key
+	SelectAPIKeys() ([]entity.APIKey, error)
+	// SelectRefreshTokenByHash select a refresh token by its hash
+	SelectRefreshTokenByHash(tokenHash string) (entity.RefreshToken, error)
+	// SelectRolePermissions select the permissions granted by every role
+	SelectRolePermissions() ([]entity.RolePermission, error)
+	// SelectSIWENonceByHash select a SIWE nonce by its hash
+	SelectSIWENonceByHash(nonceHash string) (entity.SIWENonce, error)
+	// SelectSessionByID select a session by its ID
+	SelectSessionByID(id string) (entity.Session, error)
+	// SelectSessionsByWalletAddress select the active sessions of a user
+	SelectSessionsByWalletAddress(walletAddress string) ([]entity.Session, error)
+	// SelectUserRolesByWalletAddress select the roles granted to a user
+	SelectUserRolesByWalletAddress(walletAddress string) ([]entity.UserRole, error)
+	// SelectWebAuthnChallengeByHash select a WebAuthn challenge by its hash
+	SelectWebAuthnChallengeByHash(challengeHash string) (entity.WebAuthnChallenge, error)
+	// SelectWebAuthnCredentialByCredentialID select a WebAuthn credential by its credential ID
+	SelectWebAuthnCredentialByCredentialID(credentialID string) (entity.WebAuthnCredential, error)
+	// SelectWebAuthnCredentialsByWalletAddress select the WebAuthn credentials of a user
+	SelectWebAuthnCredentialsByWalletAddress(walletAddress string) ([]entity.WebAuthnCredential, error)
+}
@@ -0,0 +1,527 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"celeste/infrastructures/database/mysql/types"
+	apiError "celeste/internal/errors"
+	"celeste/module/auth/domain/entity"
+	repositoryTypes "celeste/module/auth/infrastructure/repository/types"
+)
+
+// AuthCommandRepository handles the auth command repository logic
+type AuthCommandRepository struct {
+	types.MySQLDBHandlerInterface
+}
+
+// DeleteSIWENonce consumes the SIWE nonce, an already consumed nonce fails with an invalid SIWE message
+func (repository *AuthCommandRepository) DeleteSIWENonce(id string) error {
+	nonce := &entity.SIWENonce{
+		ID: id,
+	}
+
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE id=:id", nonce.GetModelName())
+	res, err := repository.MySQLDBHandlerInterface.Execute(stmt, nonce)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	} else if rowsAffected == 0 {
+		return errors.New(apiError.InvalidSIWEMessage)
+	}
+
+	return nil
+}
+
+// DeleteUserRole revokes the role from the user, a role the user does not have fails with a missing record
+func (repository *AuthCommandRepository) DeleteUserRole(data repositoryTypes.UserRole) error {
+	userRole := &entity.UserRole{
+		WalletAddress: data.WalletAddress,
+		Role:          data.Role,
+	}
+
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE wallet_address=:wallet_address AND role=:role", userRole.GetModelName())
+	res, err := repository.MySQLDBHandlerInterface.Execute(stmt, userRole)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	} else if rowsAffected == 0 {
+		return errors.New(apiError.MissingRecord)
+	}
+
+	return nil
+}
+
+// DeleteWebAuthnChallenge consumes the WebAuthn challenge, an already consumed challenge fails with an invalid WebAuthn response
+func (repository *AuthCommandRepository) DeleteWebAuthnChallenge(id string) error {
+	challenge := &entity.WebAuthnChallenge{
+		ID: id,
+	}
+
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE id=:id", challenge.GetModelName())
+	res, err := repository.MySQLDBHandlerInterface.Execute(stmt, challenge)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	} else if rowsAffected == 0 {
+		return errors.New(apiError.InvalidWebAuthnResponse)
+	}
+
+	return nil
+}
+
+// DeleteWebAuthnCredentialsByWalletAddress removes every WebAuthn credential of the user
+func (repository *AuthCommandRepository) DeleteWebAuthnCredentialsByWalletAddress(walletAddress string) error {
+	credential := &entity.WebAuthnCredential{
+		WalletAddress: walletAddress,
+	}
+
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE wallet_address=:wallet_address", credential.GetModelName())
+	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, credential)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// InsertAPIKey creates a new API key
+func (repository *AuthCommandRepository) InsertAPIKey(data repositoryTypes.CreateAPIKey) error {
+	apiKey := &entity.APIKey{
+		ID:        data.ID,
+		Name:      data.Name,
+		Prefix:    data.Prefix,
+		KeyHash:   data.KeyHash,
+		Scopes:    data.Scopes,
+		CreatedBy: data.CreatedBy,
+		ExpiresAt: data.ExpiresAt,
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (id, name, prefix, key_hash, scopes, created_by, expires_at) VALUES (:id, :name, :prefix, :key_hash, :scopes, :created_by, :expires_at)", apiKey.GetModelName())
+	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, apiKey)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// InsertSIWENonce creates a new SIWE nonce and removes the expired ones
+func (repository *AuthCommandRepository) InsertSIWENonce(data repositoryTypes.CreateSIWENonce) error {
+	nonce := &entity.SIWENonce{
+		ID:        data.ID,
+		NonceHash: data.NonceHash,
+		ExpiresAt: data.ExpiresAt,
+	}
+
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE expires_at < NOW()", nonce.GetModelName())
+	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, nonce)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	stmt = fmt.Sprintf("INSERT INTO %s (id, nonce_hash, expires_at) VALUES (:id, :nonce_hash, :expires_at)", nonce.GetModelName())
+	_, err = repository.MySQLDBHandlerInterface.Execute(stmt, nonce)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// InsertSession creates a new session along with the first refresh token of its family
+func (repository *AuthCommandRepository) InsertSession(data repositoryTypes.CreateSession) error {
+	session := &entity.Session{
+		ID:            data.RefreshToken.FamilyID,
+		WalletAddress: data.RefreshToken.WalletAddress,
+		Device:        data.Device,
+		IPAddress:     data.IPAddress,
+		UserAgent:     data.UserAgent,
+		ExpiresAt:     data.RefreshToken.ExpiresAt,
+	}
+
+	refreshToken := &entity.RefreshToken{
+		ID:            data.RefreshToken.ID,
+		FamilyID:      data.RefreshToken.FamilyID,
+		WalletAddress: data.RefreshToken.WalletAddress,
+		TokenHash:     data.RefreshToken.TokenHash,
+		ExpiresAt:     data.RefreshToken.ExpiresAt,
+	}
+
+	tx, err := repository.MySQLDBHandlerInterface.Begin()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+	defer tx.Rollback()
+
+	stmt := fmt.Sprintf("INSERT INTO %s (id, wallet_address, device, ip_address, user_agent, expires_at) VALUES (:id, :wallet_address, :device, :ip_address, :user_agent, :expires_at)", session.GetModelName())
+	_, err = tx.NamedExec(stmt, session)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	stmt = fmt.Sprintf("INSERT INTO %s (id, family_id, wallet_address, token_hash, expires_at) VALUES (:id, :family_id, :wallet_address, :token_hash, :expires_at)", refreshToken.GetModelName())
+	_, err = tx.NamedExec(stmt, refreshToken)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// InsertUserRole grants the role to the user, granting a role twice is a no-op
+func (repository *AuthCommandRepository) InsertUserRole(data repositoryTypes.UserRole) error {
+	userRole := &entity.UserRole{
+		WalletAddress: data.WalletAddress,
+		Role:          data.Role,
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (wallet_address, role) VALUES (:wallet_address, :role)", userRole.GetModelName())
+	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, userRole)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) {
+			switch mysqlErr.Number {
+			case 1062:
+				return nil
+			case 1452:
+				// the foreign key to the roles failed
+				return errors.New(apiError.InvalidRole)
+			}
+		}
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// InsertWebAuthnChallenge creates a new WebAuthn challenge and removes the expired ones
+func (repository *AuthCommandRepository) InsertWebAuthnChallenge(data repositoryTypes.CreateWebAuthnChallenge) error {
+	challenge := &entity.WebAuthnChallenge{
+		ID:            data.ID,
+		WalletAddress: data.WalletAddress,
+		Ceremony:      data.Ceremony,
+		ChallengeHash: data.ChallengeHash,
+		ExpiresAt:     data.ExpiresAt,
+	}
+
+	// abandoned ceremonies never consume their challenge
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE expires_at < NOW()", challenge.GetModelName())
+	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, challenge)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	stmt = fmt.Sprintf("INSERT INTO %s (id, wallet_address, ceremony, challenge_hash, expires_at) VALUES (:id, :wallet_address, :ceremony, :challenge_hash, :expires_at)", challenge.GetModelName())
+	_, err = repository.MySQLDBHandlerInterface.Execute(stmt, challenge)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// InsertWebAuthnCredential creates a new WebAuthn credential
+func (repository *AuthCommandRepository) InsertWebAuthnCredential(data repositoryTypes.CreateWebAuthnCredential) error {
+	credential := &entity.WebAuthnCredential{
+		ID:            data.ID,
+		WalletAddress: data.WalletAddress,
+		CredentialID:  data.CredentialID,
+		PublicKey:     data.PublicKey,
+		SignCount:     data.SignCount,
+		Name:          data.Name,
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (id, wallet_address, credential_id, public_key, sign_count, name) VALUES (:id, :wallet_address, :credential_id, :public_key, :sign_count, :name)", credential.GetModelName())
+	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, credential)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return errors.New(apiError.WebAuthnCredentialExists)
+		}
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// RevokeAPIKey revokes the API key, an unknown or already revoked key fails with a missing record
+func (repository *AuthCommandRepository) RevokeAPIKey(id string) error {
+	apiKey := &entity.APIKey{
+		ID: id,
+	}
+
+	stmt := fmt.Sprintf("UPDATE %s SET revoked_at=NOW() WHERE id=:id AND revoked_at IS NULL", apiKey.GetModelName())
+	res, err := repository.MySQLDBHandlerInterface.Execute(stmt, apiKey)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	} else if rowsAffected == 0 {
+		return errors.New(apiError.MissingRecord)
+	}
+
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every refresh token of the family along with its session
+func (repository *AuthCommandRepository) RevokeRefreshTokenFamily(familyID string) error {
+	revokedAt := time.Now()
+
+	refreshToken := &entity.RefreshToken{
+		FamilyID:  familyID,
+		RevokedAt: &revokedAt,
+	}
+
+	session := &entity.Session{
+		ID:        familyID,
+		RevokedAt: &revokedAt,
+	}
+
+	tx, err := repository.MySQLDBHandlerInterface.Begin()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+	defer tx.Rollback()
+
+	stmt := fmt.Sprintf("UPDATE %s SET revoked_at=:revoked_at WHERE family_id=:family_id AND revoked_at IS NULL", refreshToken.GetModelName())
+	_, err = tx.NamedExec(stmt, refreshToken)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	stmt = fmt.Sprintf("UPDATE %s SET revoked_at=:revoked_at WHERE id=:id AND revoked_at IS NULL", session.GetModelName())
+	_, err = tx.NamedExec(stmt, session)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// RevokeSessionsByWalletAddress revokes every session and refresh token of the user
+func (repository *AuthCommandRepository) RevokeSessionsByWalletAddress(walletAddress string) error {
+	revokedAt := time.Now()
+
+	refreshToken := &entity.RefreshToken{
+		WalletAddress: walletAddress,
+		RevokedAt:     &revokedAt,
+	}
+
+	session := &entity.Session{
+		WalletAddress: walletAddress,
+		RevokedAt:     &revokedAt,
+	}
+
+	tx, err := repository.MySQLDBHandlerInterface.Begin()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+	defer tx.Rollback()
+
+	stmt := fmt.Sprintf("UPDATE %s SET revoked_at=:revoked_at WHERE wallet_address=:wallet_address AND revoked_at IS NULL", refreshToken.GetModelName())
+	_, err = tx.NamedExec(stmt, refreshToken)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	stmt = fmt.Sprintf("UPDATE %s SET revoked_at=:revoked_at WHERE wallet_address=:wallet_address AND revoked_at IS NULL", session.GetModelName())
+	_, err = tx.NamedExec(stmt, session)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// RotateRefreshToken marks the refresh token as rotated, inserts the next token of the family and extends its session
+// Only one caller can rotate a token, concurrent or repeated rotations fail with an invalid refresh token
+func (repository *AuthCommandRepository) RotateRefreshToken(data repositoryTypes.RotateRefreshToken) error {
+	rotatedAt := time.Now()
+
+	refreshToken := &entity.RefreshToken{
+		ID:        data.ID,
+		RotatedAt: &rotatedAt,
+	}
+
+	nextRefreshToken := &entity.RefreshToken{
+		ID:            data.Next.ID,
+		FamilyID:      data.Next.FamilyID,
+		WalletAddress: data.Next.WalletAddress,
+		TokenHash:     data.Next.TokenHash,
+		ExpiresAt:     data.Next.ExpiresAt,
+	}
+
+	session := &entity.Session{
+		ID:        data.Next.FamilyID,
+		ExpiresAt: data.Next.ExpiresAt,
+	}
+
+	tx, err := repository.MySQLDBHandlerInterface.Begin()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+	defer tx.Rollback()
+
+	stmt := fmt.Sprintf("UPDATE %s SET rotated_at=:rotated_at WHERE id=:id AND rotated_at IS NULL AND revoked_at IS NULL", refreshToken.GetModelName())
+	res, err := tx.NamedExec(stmt, refreshToken)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	} else if rowsAffected == 0 {
+		return errors.New(apiError.InvalidRefreshToken)
+	}
+
+	stmt = fmt.Sprintf("INSERT INTO %s (id, family_id, wallet_address, token_hash, expires_at) VALUES (:id, :family_id, :wallet_address, :token_hash, :expires_at)", nextRefreshToken.GetModelName())
+	_, err = tx.NamedExec(stmt, nextRefreshToken)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	stmt = fmt.Sprintf("UPDATE %s SET expires_at=:expires_at, last_seen_at=NOW() WHERE id=:id", session.GetModelName())
+	_, err = tx.NamedExec(stmt, session)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// UpdateAPIKeyLastUsedAt records the API key was just used, at most once a minute to spare writes on busy keys
+func (repository *AuthCommandRepository) UpdateAPIKeyLastUsedAt(id string) error {
+	apiKey := &entity.APIKey{
+		ID: id,
+	}
+
+	stmt := fmt.Sprintf("UPDATE %s SET last_used_at=NOW() WHERE id=:id AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL 1 MINUTE)", apiKey.GetModelName())
+	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, apiKey)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// UpdateSessionLastSeenAt records the session was just used, at most once a minute to spare writes on busy sessions
+func (repository *AuthCommandRepository) UpdateSessionLastSeenAt(id string) error {
+	session := &entity.Session{
+		ID: id,
+	}
+
+	stmt := fmt.Sprintf("UPDATE %s SET last_seen_at=NOW() WHERE id=:id AND last_seen_at < NOW() - INTERVAL 1 MINUTE", session.GetModelName())
+	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, session)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// UpdateWebAuthnCredentialSignCount stores the signature counter of the last login with the credential
+// Counters that did not increase fail with an invalid WebAuthn response, authenticators without a counter always report zero
+// and a login within the same second as the last one leaves their row unchanged, so zero affected rows are only a lost race for counters above zero
+func (repository *AuthCommandRepository) UpdateWebAuthnCredentialSignCount(data repositoryTypes.UpdateWebAuthnCredentialSignCount) error {
+	lastUsedAt := time.Now()
+
+	credential := &entity.WebAuthnCredential{
+		ID:         data.ID,
+		SignCount:  data.SignCount,
+		LastUsedAt: &lastUsedAt,
+	}
+
+	stmt := fmt.Sprintf("UPDATE %s SET sign_count=:sign_count, last_used_at=:last_used_at WHERE id=:id AND (sign_count < :sign_count OR sign_count = 0)", credential.GetModelName())
+	res, err := repository.MySQLDBHandlerInterface.Execute(stmt, credential)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	} else if rowsAffected == 0 && data.SignCount > 0 {
+		return errors.New(apiError.InvalidWebAuthnResponse)
+	}
+
+	return nil
+}
@@ -0,0 +1,475 @@
+package repository
+
+import (
+	"github.com/afex/hystrix-go/hystrix"
+
+	hystrix_config "celeste/configs/hystrix"
+	"celeste/module/auth/domain/repository"
+	repositoryTypes "celeste/module/auth/infrastructure/repository/types"
+)
+
+// AuthCommandRepositoryCircuitBreaker circuit breaker for auth command repository
+type AuthCommandRepositoryCircuitBreaker struct {
+	repository.AuthCommandRepositoryInterface
+}
+
+var config = hystrix_config.Config{}
+
+// DeleteSIWENonce decorator pattern to delete SIWE nonce
+func (repository *AuthCommandRepositoryCircuitBreaker) DeleteSIWENonce(id string) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("delete_siwe_nonce", config.Settings())
+	errors := hystrix.Go("delete_siwe_nonce", func() error {
+		err := repository.AuthCommandRepositoryInterface.DeleteSIWENonce(id)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// DeleteUserRole decorator pattern to delete user role
+func (repository *AuthCommandRepositoryCircuitBreaker) DeleteUserRole(data repositoryTypes.UserRole) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("delete_user_role", config.Settings())
+	errors := hystrix.Go("delete_user_role", func() error {
+		err := repository.AuthCommandRepositoryInterface.DeleteUserRole(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// DeleteWebAuthnChallenge decorator pattern to delete WebAuthn challenge
+func (repository *AuthCommandRepositoryCircuitBreaker) DeleteWebAuthnChallenge(id string) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("delete_webauthn_challenge", config.Settings())
+	errors := hystrix.Go("delete_webauthn_challenge", func() error {
+		err := repository.AuthCommandRepositoryInterface.DeleteWebAuthnChallenge(id)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// DeleteWebAuthnCredentialsByWalletAddress decorator pattern to delete WebAuthn credentials by wallet address
+func (repository *AuthCommandRepositoryCircuitBreaker) DeleteWebAuthnCredentialsByWalletAddress(walletAddress string) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("delete_webauthn_credentials_by_wallet_address", config.Settings())
+	errors := hystrix.Go("delete_webauthn_credentials_by_wallet_address", func() error {
+		err := repository.AuthCommandRepositoryInterface.DeleteWebAuthnCredentialsByWalletAddress(walletAddress)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// InsertAPIKey decorator pattern to insert API key
+func (repository *AuthCommandRepositoryCircuitBreaker) InsertAPIKey(data repositoryTypes.CreateAPIKey) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("insert_api_key", config.Settings())
+	errors := hystrix.Go("insert_api_key", func() error {
+		err := repository.AuthCommandRepositoryInterface.InsertAPIKey(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// InsertSIWENonce decorator pattern to insert SIWE nonce
+func (repository *AuthCommandRepositoryCircuitBreaker) InsertSIWENonce(data repositoryTypes.CreateSIWENonce) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("insert_siwe_nonce", config.Settings())
+	errors := hystrix.Go("insert_siwe_nonce", func() error {
+		err := repository.AuthCommandRepositoryInterface.InsertSIWENonce(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// InsertSession decorator pattern to insert session
+func (repository *AuthCommandRepositoryCircuitBreaker) InsertSession(data repositoryTypes.CreateSession) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("insert_session", config.Settings())
+	errors := hystrix.Go("insert_session", func() error {
+		err := repository.AuthCommandRepositoryInterface.InsertSession(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// InsertUserRole decorator pattern to insert user role
+func (repository *AuthCommandRepositoryCircuitBreaker) InsertUserRole(data repositoryTypes.UserRole) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("insert_user_role", config.Settings())
+	errors := hystrix.Go("insert_user_role", func() error {
+		err := repository.AuthCommandRepositoryInterface.InsertUserRole(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// InsertWebAuthnChallenge decorator pattern to insert WebAuthn challenge
+func (repository *AuthCommandRepositoryCircuitBreaker) InsertWebAuthnChallenge(data repositoryTypes.CreateWebAuthnChallenge) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("insert_webauthn_challenge", config.Settings())
+	errors := hystrix.Go("insert_webauthn_challenge", func() error {
+		err := repository.AuthCommandRepositoryInterface.InsertWebAuthnChallenge(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// InsertWebAuthnCredential decorator pattern to insert WebAuthn credential
+func (repository *AuthCommandRepositoryCircuitBreaker) InsertWebAuthnCredential(data repositoryTypes.CreateWebAuthnCredential) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("insert_webauthn_credential", config.Settings())
+	errors := hystrix.Go("insert_webauthn_credential", func() error {
+		err := repository.AuthCommandRepositoryInterface.InsertWebAuthnCredential(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// RevokeAPIKey decorator pattern to revoke API key
+func (repository *AuthCommandRepositoryCircuitBreaker) RevokeAPIKey(id string) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("revoke_api_key", config.Settings())
+	errors := hystrix.Go("revoke_api_key", func() error {
+		err := repository.AuthCommandRepositoryInterface.RevokeAPIKey(id)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// RevokeRefreshTokenFamily decorator pattern to revoke refresh token family
+func (repository *AuthCommandRepositoryCircuitBreaker) RevokeRefreshTokenFamily(familyID string) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("revoke_refresh_token_family", config.Settings())
+	errors := hystrix.Go("revoke_refresh_token_family", func() error {
+		err := repository.AuthCommandRepositoryInterface.RevokeRefreshTokenFamily(familyID)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// RevokeSessionsByWalletAddress decorator pattern to revoke sessions by wallet address
+func (repository *AuthCommandRepositoryCircuitBreaker) RevokeSessionsByWalletAddress(walletAddress string) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("revoke_sessions_by_wallet_address", config.Settings())
+	errors := hystrix.Go("revoke_sessions_by_wallet_address", func() error {
+		err := repository.AuthCommandRepositoryInterface.RevokeSessionsByWalletAddress(walletAddress)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// RotateRefreshToken decorator pattern to rotate refresh token
+func (repository *AuthCommandRepositoryCircuitBreaker) RotateRefreshToken(data repositoryTypes.RotateRefreshToken) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("rotate_refresh_token", config.Settings())
+	errors := hystrix.Go("rotate_refresh_token", func() error {
+		err := repository.AuthCommandRepositoryInterface.RotateRefreshToken(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// UpdateAPIKeyLastUsedAt decorator pattern to update API key last used at
+func (repository *AuthCommandRepositoryCircuitBreaker) UpdateAPIKeyLastUsedAt(id string) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("update_api_key_last_used_at", config.Settings())
+	errors := hystrix.Go("update_api_key_last_used_at", func() error {
+		err := repository.AuthCommandRepositoryInterface.UpdateAPIKeyLastUsedAt(id)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// UpdateSessionLastSeenAt decorator pattern to update session last seen at
+func (repository *AuthCommandRepositoryCircuitBreaker) UpdateSessionLastSeenAt(id string) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("update_session_last_seen_at", config.Settings())
+	errors := hystrix.Go("update_session_last_seen_at", func() error {
+		err := repository.AuthCommandRepositoryInterface.UpdateSessionLastSeenAt(id)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// UpdateWebAuthnCredentialSignCount decorator pattern to update WebAuthn credential sign count
+func (repository *AuthCommandRepositoryCircuitBreaker) UpdateWebAuthnCredentialSignCount(data repositoryTypes.UpdateWebAuthnCredentialSignCount) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("update_webauthn_credential_sign_count", config.Settings())
+	errors := hystrix.Go("update_webauthn_credential_sign_count", func() error {
+		err := repository.AuthCommandRepositoryInterface.UpdateWebAuthnCredentialSignCount(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"celeste/infrastructures/database/mysql/types"
+	apiError "celeste/internal/errors"
+	repositoryTypes "celeste/module/auth/infrastructure/repository/types"
+)
+
+// fakeMySQLDBHandler reports every statement as matching no rows, like MySQL does for rows left unchanged
+type fakeMySQLDBHandler struct {
+	types.MySQLDBHandlerInterface
+}
+
+func (handler *fakeMySQLDBHandler) Execute(stmt string, model interface{}) (sql.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func TestUpdateWebAuthnCredentialSignCountUnchanged(t *testing.T) {
+	repository := &AuthCommandRepository{MySQLDBHandlerInterface: &fakeMySQLDBHandler{}}
+
+	// authenticators without a counter report zero, a second login within the same second changes nothing
+	err := repository.UpdateWebAuthnCredentialSignCount(repositoryTypes.UpdateWebAuthnCredentialSignCount{ID: "credential"})
+	if err != nil {
+		t.Errorf("expected a counter of zero to be accepted, got %v", err)
+	}
+
+	// a counter above zero that changed no row lost the race against a concurrent login
+	err = repository.UpdateWebAuthnCredentialSignCount(repositoryTypes.UpdateWebAuthnCredentialSignCount{ID: "credential", SignCount: 7})
+	if err == nil || err.Error() != apiError.InvalidWebAuthnResponse {
+		t.Errorf("expected %s for a stale counter, got %v", apiError.InvalidWebAuthnResponse, err)
+	}
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"celeste/infrastructures/database/mysql/types"
+	apiError "celeste/internal/errors"
+	"celeste/module/auth/domain/entity"
+)
+
+// AuthQueryRepository handles the auth query repository logic
+type AuthQueryRepository struct {
+	types.MySQLDBHandlerInterface
+}
+
+// SelectAPIKeyByPrefix select an API key by its lookup prefix
+func (repository *AuthQueryRepository) SelectAPIKeyByPrefix(prefix string) (entity.APIKey, error) {
+	var apiKey entity.APIKey
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE prefix=:prefix", apiKey.GetModelName())
+	err := repository.QueryRow(stmt, map[string]interface{}{
+		"prefix": prefix,
+	}, &apiKey)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apiKey, errors.New(apiError.MissingRecord)
+		}
+
+		log.Println(err)
+		return apiKey, errors.New(apiError.DatabaseError)
+	}
+
+	return apiKey, nil
+}
+
+// SelectAPIKeys select every API key, newest first
+func (repository *AuthQueryRepository) SelectAPIKeys() ([]entity.APIKey, error) {
+	var apiKey entity.APIKey
+	var apiKeys []entity.APIKey
+
+	stmt := fmt.Sprintf("SELECT * FROM %s ORDER BY created_at DESC", apiKey.GetModelName())
+	err := repository.Query(stmt, map[string]interface{}{}, &apiKeys)
+	if err != nil {
+		log.Println(err)
+		return []entity.APIKey{}, errors.New(apiError.DatabaseError)
+	}
+
+	return apiKeys, nil
+}
+
+// SelectRefreshTokenByHash select a refresh token by its hash
+func (repository *AuthQueryRepository) SelectRefreshTokenByHash(tokenHash string) (entity.RefreshToken, error) {
+	var refreshToken entity.RefreshToken
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE token_hash=:token_hash", refreshToken.GetModelName())
+	err := repository.QueryRow(stmt, map[string]interface{}{
+		"token_hash": tokenHash,
+	}, &refreshToken)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return refreshToken, errors.New(apiError.MissingRecord)
+		}
+
+		log.Println(err)
+		return refreshToken, errors.New(apiError.DatabaseError)
+	}
+
+	return refreshToken, nil
+}
+
+// SelectRolePermissions select the permissions granted by every role
+func (repository *AuthQueryRepository) SelectRolePermissions() ([]entity.RolePermission, error) {
+	var rolePermission entity.RolePermission
+	var rolePermissions []entity.RolePermission
+
+	stmt := fmt.Sprintf("SELECT role, permission FROM %s", rolePermission.GetModelName())
+	err := repository.Query(stmt, map[string]interface{}{}, &rolePermissions)
+	if err != nil {
+		log.Println(err)
+		return []entity.RolePermission{}, errors.New(apiError.DatabaseError)
+	}
+
+	return rolePermissions, nil
+}
+
+// SelectSIWENonceByHash select a SIWE nonce by its hash
+func (repository *AuthQueryRepository) SelectSIWENonceByHash(nonceHash string) (entity.SIWENonce, error) {
+	var nonce entity.SIWENonce
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE nonce_hash=:nonce_hash", nonce.GetModelName())
+	err := repository.QueryRow(stmt, map[string]interface{}{
+		"nonce_hash": nonceHash,
+	}, &nonce)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nonce, errors.New(apiError.MissingRecord)
+		}
+
+		log.Println(err)
+		return nonce, errors.New(apiError.DatabaseError)
+	}
+
+	return nonce, nil
+}
+
+// SelectSessionByID select a session by its ID
+func (repository *AuthQueryRepository) SelectSessionByID(id string) (entity.Session, error) {
+	var session entity.Session
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE id=:id", session.GetModelName())
+	err := repository.QueryRow(stmt, map[string]interface{}{
+		"id": id,
+	}, &session)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return session, errors.New(apiError.MissingRecord)
+		}
+
+		log.Println(err)
+		return session, errors.New(apiError.DatabaseError)
+	}
+
+	return session, nil
+}
+
+// SelectSessionsByWalletAddress select the active sessions of a user, most recently seen first
+func (repository *AuthQueryRepository) SelectSessionsByWalletAddress(walletAddress string) ([]entity.Session, error) {
+	var session entity.Session
+	var sessions []entity.Session
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE wallet_address=:wallet_address AND revoked_at IS NULL AND expires_at > NOW() ORDER BY last_seen_at DESC", session.GetModelName())
+	err := repository.Query(stmt, map[string]interface{}{
+		"wallet_address": walletAddress,
+	}, &sessions)
+	if err != nil {
+		log.Println(err)
+		return []entity.Session{}, errors.New(apiError.DatabaseError)
+	}
+
+	return sessions, nil
+}
+
+// SelectUserRolesByWalletAddress select the roles granted to a user
+func (repository *AuthQueryRepository) SelectUserRolesByWalletAddress(walletAddress string) ([]entity.UserRole, error) {
+	var userRole entity.UserRole
+	var userRoles []entity.UserRole
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE wallet_address=:wallet_address ORDER BY role ASC", userRole.GetModelName())
+	err := repository.Query(stmt, map[string]interface{}{
+		"wallet_address": walletAddress,
+	}, &userRoles)
+	if err != nil {
+		log.Println(err)
+		return []entity.UserRole{}, errors.New(apiError.DatabaseError)
+	}
+
+	return userRoles, nil
+}
+
+// SelectWebAuthnChallengeByHash select a WebAuthn challenge by its hash
+func (repository *AuthQueryRepository) SelectWebAuthnChallengeByHash(challengeHash string) (entity.WebAuthnChallenge, error) {
+	var challenge entity.WebAuthnChallenge
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE challenge_hash=:challenge_hash", challenge.GetModelName())
+	err := repository.QueryRow(stmt, map[string]interface{}{
+		"challenge_hash": challengeHash,
+	}, &challenge)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return challenge, errors.New(apiError.MissingRecord)
+		}
+
+		log.Println(err)
+		return challenge, errors.New(apiError.DatabaseError)
+	}
+
+	return challenge, nil
+}
+
+// SelectWebAuthnCredentialByCredentialID select a WebAuthn credential by its credential ID
+func (repository *AuthQueryRepository) SelectWebAuthnCredentialByCredentialID(credentialID string) (entity.WebAuthnCredential, error) {
+	var credential entity.WebAuthnCredential
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE credential_id=:credential_id", credential.GetModelName())
+	err := repository.QueryRow(stmt, map[string]interface{}{
+		"credential_id": credentialID,
+	}, &credential)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return credential, errors.New(apiError.MissingRecord)
+		}
+
+		log.Println(err)
+		return credential, errors.New(apiError.DatabaseError)
+	}
+
+	return credential, nil
+}
+
+// SelectWebAuthnCredentialsByWalletAddress select the WebAuthn credentials of a user
+func (repository *AuthQueryRepository) SelectWebAuthnCredentialsByWalletAddress(walletAddress string) ([]entity.WebAuthnCredential, error) {
+	var credential entity.WebAuthnCredential
+	var credentials []entity.WebAuthnCredential
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE wallet_address=:wallet_address ORDER BY created_at ASC", credential.GetModelName())
+	err := repository.Query(stmt, map[string]interface{}{
+		"wallet_address": walletAddress,
+	}, &credentials)
+	if err != nil {
+		log.Println(err)
+		return []entity.WebAuthnCredential{}, errors.New(apiError.DatabaseError)
+	}
+
+	return credentials, nil
+}
@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"github.com/afex/hystrix-go/hystrix"
+
+	"celeste/module/auth/domain/entity"
+	"celeste/module/auth/domain/repository"
+)
+
+// AuthQueryRepositoryCircuitBreaker holds the implementable methods for auth query circuitbreaker
+type AuthQueryRepositoryCircuitBreaker struct {
+	repository.AuthQueryRepositoryInterface
+}
+
+// SelectAPIKeyByPrefix decorator pattern to select API key by prefix
+func (repository *AuthQueryRepositoryCircuitBreaker) SelectAPIKeyByPrefix(prefix string) (entity.APIKey, error) {
+	output := make(chan entity.APIKey, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_api_key_by_prefix", config.Settings())
+	errors := hystrix.Go("select_api_key_by_prefix", func() error {
+		apiKey, err := repository.AuthQueryRepositoryInterface.SelectAPIKeyByPrefix(prefix)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- apiKey
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return entity.APIKey{}, err
+	case err := <-errors:
+		return entity.APIKey{}, err
+	}
+}
+
+// SelectAPIKeys decorator pattern to select API keys
+func (repository *AuthQueryRepositoryCircuitBreaker) SelectAPIKeys() ([]entity.APIKey, error) {
+	output := make(chan []entity.APIKey, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_api_keys", config.Settings())
+	errors := hystrix.Go("select_api_keys", func() error {
+		apiKeys, err := repository.AuthQueryRepositoryInterface.SelectAPIKeys()
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- apiKeys
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return []entity.APIKey{}, err
+	case err := <-errors:
+		return []entity.APIKey{}, err
+	}
+}
+
+// SelectRefreshTokenByHash decorator pattern for select refresh token repository
+func (repository *AuthQueryRepositoryCircuitBreaker) SelectRefreshTokenByHash(tokenHash string) (entity.RefreshToken, error) {
+	output := make(chan entity.RefreshToken, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_refresh_token_by_hash", config.Settings())
+	errors := hystrix.Go("select_refresh_token_by_hash", func() error {
+		refreshToken, err := repository.AuthQueryRepositoryInterface.SelectRefreshTokenByHash(tokenHash)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- refreshToken
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return entity.RefreshToken{}, err
+	case err := <-errors:
+		return entity.RefreshToken{}, err
+	}
+}
+
+// SelectRolePermissions decorator pattern to select role permissions
+func (repository *AuthQueryRepositoryCircuitBreaker) SelectRolePermissions() ([]entity.RolePermission, error) {
+	output := make(chan []entity.RolePermission, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_role_permissions", config.Settings())
+	errors := hystrix.Go("select_role_permissions", func() error {
+		rolePermissions, err := repository.AuthQueryRepositoryInterface.SelectRolePermissions()
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- rolePermissions
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return []entity.RolePermission{}, err
+	case err := <-errors:
+		return []entity.RolePermission{}, err
+	}
+}
+
+// SelectSIWENonceByHash decorator pattern to select SIWE nonce by hash
+func (repository *AuthQueryRepositoryCircuitBreaker) SelectSIWENonceByHash(nonceHash string) (entity.SIWENonce, error) {
+	output := make(chan entity.SIWENonce, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_siwe_nonce_by_hash", config.Settings())
+	errors := hystrix.Go("select_siwe_nonce_by_hash", func() error {
+		nonce, err := repository.AuthQueryRepositoryInterface.SelectSIWENonceByHash(nonceHash)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nonce
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return entity.SIWENonce{}, err
+	case err := <-errors:
+		return entity.SIWENonce{}, err
+	}
+}
+
+// SelectSessionByID decorator pattern to select session by ID
+func (repository *AuthQueryRepositoryCircuitBreaker) SelectSessionByID(id string) (entity.Session, error) {
+	output := make(chan entity.Session, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_session_by_id", config.Settings())
+	errors := hystrix.Go("select_session_by_id", func() error {
+		session, err := repository.AuthQueryRepositoryInterface.SelectSessionByID(id)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- session
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return entity.Session{}, err
+	case err := <-errors:
+		return entity.Session{}, err
+	}
+}
+
+// SelectSessionsByWalletAddress decorator pattern to select sessions by wallet address
+func (repository *AuthQueryRepositoryCircuitBreaker) SelectSessionsByWalletAddress(walletAddress string) ([]entity.Session, error) {
+	output := make(chan []entity.Session, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_sessions_by_wallet_address", config.Settings())
+	errors := hystrix.Go("select_sessions_by_wallet_address", func() error {
+		sessions, err := repository.AuthQueryRepositoryInterface.SelectSessionsByWalletAddress(walletAddress)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- sessions
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return []entity.Session{}, err
+	case err := <-errors:
+		return []entity.Session{}, err
+	}
+}
+
+// SelectUserRolesByWalletAddress decorator pattern to select user roles by wallet address
+func (repository *AuthQueryRepositoryCircuitBreaker) SelectUserRolesByWalletAddress(walletAddress string) ([]entity.UserRole, error) {
+	output := make(chan []entity.UserRole, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_user_roles_by_wallet_address", config.Settings())
+	errors := hystrix.Go("select_user_roles_by_wallet_address", func() error {
+		userRoles, err := repository.AuthQueryRepositoryInterface.SelectUserRolesByWalletAddress(walletAddress)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- userRoles
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return []entity.UserRole{}, err
+	case err := <-errors:
+		return []entity.UserRole{}, err
+	}
+}
+
+// SelectWebAuthnChallengeByHash decorator pattern to select WebAuthn challenge by hash
+func (repository *AuthQueryRepositoryCircuitBreaker) SelectWebAuthnChallengeByHash(challengeHash string) (entity.WebAuthnChallenge, error) {
+	output := make(chan entity.WebAuthnChallenge, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_webauthn_challenge_by_hash", config.Settings())
+	errors := hystrix.Go("select_webauthn_challenge_by_hash", func() error {
+		challenge, err := repository.AuthQueryRepositoryInterface.SelectWebAuthnChallengeByHash(challengeHash)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- challenge
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return entity.WebAuthnChallenge{}, err
+	case err := <-errors:
+		return entity.WebAuthnChallenge{}, err
+	}
+}
+
+// SelectWebAuthnCredentialByCredentialID decorator pattern to select WebAuthn credential by credential ID
+func (repository *AuthQueryRepositoryCircuitBreaker) SelectWebAuthnCredentialByCredentialID(credentialID string) (entity.WebAuthnCredential, error) {
+	output := make(chan entity.WebAuthnCredential, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_webauthn_credential_by_credential_id", config.Settings())
+	errors := hystrix.Go("select_webauthn_credential_by_credential_id", func() error {
+		credential, err := repository.AuthQueryRepositoryInterface.SelectWebAuthnCredentialByCredentialID(credentialID)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- credential
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return entity.WebAuthnCredential{}, err
+	case err := <-errors:
+		return entity.WebAuthnCredential{}, err
+	}
+}
+
+// SelectWebAuthnCredentialsByWalletAddress decorator pattern to select WebAuthn credentials by wallet address
+func (repository *AuthQueryRepositoryCircuitBreaker) SelectWebAuthnCredentialsByWalletAddress(walletAddress string) ([]entity.WebAuthnCredential, error) {
+	output := make(chan []entity.WebAuthnCredential, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_webauthn_credentials_by_wallet_address", config.Settings())
+	errors := hystrix.Go("select_webauthn_credentials_by_wallet_address", func() error {
+		credentials, err := repository.AuthQueryRepositoryInterface.SelectWebAuthnCredentialsByWalletAddress(walletAddress)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- credentials
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return []entity.WebAuthnCredential{}, err
+	case err := <-errors:
+		return []entity.WebAuthnCredential{}, err
+	}
+}
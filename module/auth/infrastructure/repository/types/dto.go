@@ -0,0 +1,68 @@
+package types
+
+import (
+	"time"
+)
+
+type CreateRefreshToken struct {
+	ID            string
+	FamilyID      string
+	WalletAddress string
+	TokenHash     string
+	ExpiresAt     time.Time
+}
+
+type CreateSession struct {
+	Device       string
+	IPAddress    string
+	UserAgent    string
+	RefreshToken CreateRefreshToken // first token of the family, its family ID is the session ID
+}
+
+type RotateRefreshToken struct {
+	ID   string             // token being rotated
+	Next CreateRefreshToken // token replacing it in the same family
+}
+
+type CreateWebAuthnChallenge struct {
+	ID            string
+	WalletAddress *string // nil for login challenges
+	Ceremony      string
+	ChallengeHash string
+	ExpiresAt     time.Time
+}
+
+type CreateWebAuthnCredential struct {
+	ID            string
+	WalletAddress string
+	CredentialID  string
+	PublicKey     []byte
+	SignCount     uint32
+	Name          string
+}
+
+type UpdateWebAuthnCredentialSignCount struct {
+	ID        string
+	SignCount uint32
+}
+
+type CreateSIWENonce struct {
+	ID        string
+	NonceHash string
+	ExpiresAt time.Time
+}
+
+type UserRole struct {
+	WalletAddress string
+	Role          string
+}
+
+type CreateAPIKey struct {
+	ID        string
+	Name      string
+	Prefix    string
+	KeyHash   string
+	Scopes    string
+	CreatedBy *string
+	ExpiresAt *time.Time
+}
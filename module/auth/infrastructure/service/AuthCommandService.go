@@ -0,0 +1,834 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/segmentio/ksuid"
+
+	iamConfig "celeste/configs/iam"
+	jwtConfig "celeste/configs/jwt"
+	ratelimitConfig "celeste/configs/ratelimit"
+	siweConfig "celeste/configs/siwe"
+	webauthnConfig "celeste/configs/webauthn"
+	"celeste/infrastructures/ratelimit"
+	ratelimitTypes "celeste/infrastructures/ratelimit/types"
+	apiError "celeste/internal/errors"
+	"celeste/internal/password"
+	"celeste/internal/rbac"
+	"celeste/internal/siwe"
+	"celeste/internal/token"
+	"celeste/internal/useragent"
+	"celeste/internal/wallet"
+	"celeste/internal/webauthn"
+	"celeste/module/auth/domain/repository"
+	repositoryTypes "celeste/module/auth/infrastructure/repository/types"
+	"celeste/module/auth/infrastructure/service/types"
+	userApplication "celeste/module/user/application"
+	userRepository "celeste/module/user/domain/repository"
+	userRepositoryTypes "celeste/module/user/infrastructure/repository/types"
+)
+
+// AuthCommandService handles the auth command service logic
+type AuthCommandService struct {
+	repository.AuthCommandRepositoryInterface
+	repository.AuthQueryRepositoryInterface
+	userRepository.UserCommandRepositoryInterface
+	userRepository.UserQueryRepositoryInterface
+	userApplication.UserCommandServiceInterface
+	ratelimitTypes.AttemptStoreInterface
+	*jwtauth.JWTAuth
+}
+
+var (
+	accessConfig  = iamConfig.Config{}
+	limitConfig   = ratelimitConfig.Config{}
+	passkeyConfig = webauthnConfig.Config{}
+	signInConfig  = siweConfig.Config{}
+	tokenConfig   = jwtConfig.Config{}
+
+	// compared against on unknown emails so both failures take the same time, made with the configured hasher on first use
+	dummyPasswordHash     string
+	dummyPasswordHashOnce sync.Once
+)
+
+// AuthenticateAPIKey verifies the API key and returns it with its scopes
+func (service *AuthCommandService) AuthenticateAPIKey(ctx context.Context, apiKey string) (types.APIKeyResult, error) {
+	prefix, ok := token.APIKeyLookupPrefix(apiKey)
+	if !ok {
+		return types.APIKeyResult{}, errors.New(apiError.InvalidAPIKey)
+	}
+
+	storedKey, err := service.AuthQueryRepositoryInterface.SelectAPIKeyByPrefix(prefix)
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return types.APIKeyResult{}, errors.New(apiError.InvalidAPIKey)
+		}
+
+		return types.APIKeyResult{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token.HashOpaqueToken(apiKey)), []byte(storedKey.KeyHash)) != 1 {
+		return types.APIKeyResult{}, errors.New(apiError.InvalidAPIKey)
+	}
+
+	if storedKey.RevokedAt != nil || (storedKey.ExpiresAt != nil && time.Now().After(*storedKey.ExpiresAt)) {
+		return types.APIKeyResult{}, errors.New(apiError.InvalidAPIKey)
+	}
+
+	// the request goes on even when the usage could not be recorded
+	err = service.AuthCommandRepositoryInterface.UpdateAPIKeyLastUsedAt(storedKey.ID)
+	if err != nil {
+		log.Println(err)
+	}
+
+	return types.APIKeyResult{
+		ID:     storedKey.ID,
+		Name:   storedKey.Name,
+		Scopes: strings.Split(storedKey.Scopes, ","),
+	}, nil
+}
+
+// BeginWebAuthnLogin starts a passkey login, the user is identified by the discoverable credential the authenticator picks
+func (service *AuthCommandService) BeginWebAuthnLogin(ctx context.Context) (types.BeginWebAuthnLoginResult, error) {
+	challenge, err := service.newWebAuthnChallenge(nil, webauthn.CeremonyGet)
+	if err != nil {
+		return types.BeginWebAuthnLoginResult{}, err
+	}
+
+	return types.BeginWebAuthnLoginResult{
+		Challenge:        challenge,
+		RPID:             passkeyConfig.RPID(),
+		UserVerification: passkeyConfig.UserVerification(),
+		Timeout:          passkeyConfig.ChallengeTTL(),
+	}, nil
+}
+
+// BeginWebAuthnRegistration starts the registration of a passkey for the user
+func (service *AuthCommandService) BeginWebAuthnRegistration(ctx context.Context, walletAddress string) (types.BeginWebAuthnRegistrationResult, error) {
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(walletAddress)
+	if err != nil {
+		return types.BeginWebAuthnRegistrationResult{}, err
+	}
+
+	credentials, err := service.AuthQueryRepositoryInterface.SelectWebAuthnCredentialsByWalletAddress(user.WalletAddress)
+	if err != nil {
+		return types.BeginWebAuthnRegistrationResult{}, err
+	}
+
+	// authenticators refuse to register a second credential for the same account
+	var excludeCredentials []string
+	for _, credential := range credentials {
+		excludeCredentials = append(excludeCredentials, credential.CredentialID)
+	}
+
+	challenge, err := service.newWebAuthnChallenge(&user.WalletAddress, webauthn.CeremonyCreate)
+	if err != nil {
+		return types.BeginWebAuthnRegistrationResult{}, err
+	}
+
+	return types.BeginWebAuthnRegistrationResult{
+		Challenge:          challenge,
+		RPID:               passkeyConfig.RPID(),
+		RPName:             passkeyConfig.RPName(),
+		UserID:             webauthn.Encoding.EncodeToString([]byte(user.WalletAddress)),
+		UserName:           user.Email,
+		UserDisplayName:    user.Name,
+		Algorithms:         webauthn.SupportedAlgorithms,
+		ExcludeCredentials: excludeCredentials,
+		UserVerification:   passkeyConfig.UserVerification(),
+		Timeout:            passkeyConfig.ChallengeTTL(),
+	}, nil
+}
+
+// CreateAPIKey issues an API key with the scopes, only a hash of the key is stored so it is returned once
+func (service *AuthCommandService) CreateAPIKey(ctx context.Context, data types.CreateAPIKey) (types.CreateAPIKeyResult, error) {
+	scopes := []string{}
+	for _, scope := range data.Scopes {
+		if !slices.Contains(rbac.Permissions, scope) {
+			return types.CreateAPIKeyResult{}, errors.New(apiError.InvalidScope)
+		}
+
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	slices.Sort(scopes)
+
+	apiKey, prefix, keyHash, err := token.GenerateAPIKey()
+	if err != nil {
+		log.Println(err)
+		return types.CreateAPIKeyResult{}, errors.New(apiError.ServerError)
+	}
+
+	id := generateID()
+
+	err = service.AuthCommandRepositoryInterface.InsertAPIKey(repositoryTypes.CreateAPIKey{
+		ID:        id,
+		Name:      data.Name,
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		Scopes:    strings.Join(scopes, ","),
+		CreatedBy: data.CreatedBy,
+		ExpiresAt: data.ExpiresAt,
+	})
+	if err != nil {
+		return types.CreateAPIKeyResult{}, err
+	}
+
+	return types.CreateAPIKeyResult{
+		ID:        id,
+		Name:      data.Name,
+		Key:       apiKey,
+		Prefix:    prefix,
+		Scopes:    scopes,
+		ExpiresAt: data.ExpiresAt,
+	}, nil
+}
+
+// CreateSIWENonce issues a single use nonce for a Sign-In With Ethereum message
+func (service *AuthCommandService) CreateSIWENonce(ctx context.Context) (types.SIWENonceResult, error) {
+	nonce, err := siwe.NewNonce()
+	if err != nil {
+		log.Println(err)
+		return types.SIWENonceResult{}, errors.New(apiError.ServerError)
+	}
+
+	expiresAt := time.Now().Add(signInConfig.NonceTTL())
+
+	err = service.AuthCommandRepositoryInterface.InsertSIWENonce(repositoryTypes.CreateSIWENonce{
+		ID:        generateID(),
+		NonceHash: token.HashOpaqueToken(nonce),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return types.SIWENonceResult{}, err
+	}
+
+	return types.SIWENonceResult{
+		Nonce:     nonce,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// DeleteUserWebAuthnCredentials removes every passkey of the user, used when the user is deactivated
+func (service *AuthCommandService) DeleteUserWebAuthnCredentials(ctx context.Context, walletAddress string) error {
+	return service.AuthCommandRepositoryInterface.DeleteWebAuthnCredentialsByWalletAddress(walletAddress)
+}
+
+// FinishWebAuthnLogin verifies the passkey assertion and issues an access and refresh token like the password login
+func (service *AuthCommandService) FinishWebAuthnLogin(ctx context.Context, data types.FinishWebAuthnLogin) (types.TokenResult, error) {
+	challenge, err := service.consumeWebAuthnChallenge(data.ClientDataJSON, webauthn.CeremonyGet, nil)
+	if err != nil {
+		return types.TokenResult{}, err
+	}
+
+	credential, err := service.AuthQueryRepositoryInterface.SelectWebAuthnCredentialByCredentialID(webauthn.Encoding.EncodeToString(data.CredentialID))
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return types.TokenResult{}, errors.New(apiError.InvalidWebAuthnResponse)
+		}
+
+		return types.TokenResult{}, err
+	}
+
+	// the user handle is the wallet address the credential was registered for
+	if len(data.UserHandle) > 0 && !strings.EqualFold(string(data.UserHandle), credential.WalletAddress) {
+		return types.TokenResult{}, errors.New(apiError.InvalidWebAuthnResponse)
+	}
+
+	signCount, err := relyingParty().VerifyAssertion(challenge, webauthn.Credential{
+		ID:        data.CredentialID,
+		PublicKey: credential.PublicKey,
+		SignCount: credential.SignCount,
+	}, data.ClientDataJSON, data.AuthenticatorData, data.Signature)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrSignCount) {
+			log.Printf("[AUTH] signature counter of WebAuthn credential %s did not increase, the authenticator may be cloned", credential.ID)
+		} else {
+			log.Println(err)
+		}
+
+		return types.TokenResult{}, errors.New(apiError.InvalidWebAuthnResponse)
+	}
+
+	err = service.AuthCommandRepositoryInterface.UpdateWebAuthnCredentialSignCount(repositoryTypes.UpdateWebAuthnCredentialSignCount{
+		ID:        credential.ID,
+		SignCount: signCount,
+	})
+	if err != nil {
+		return types.TokenResult{}, err
+	}
+
+	// deactivated users can no longer log in
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(credential.WalletAddress)
+	if err != nil && err.Error() != apiError.MissingRecord {
+		return types.TokenResult{}, err
+	} else if err != nil || len(user.Password) == 0 {
+		return types.TokenResult{}, errors.New(apiError.InvalidCredentials)
+	}
+
+	// a passkey that verified the user, e.g. with a PIN or biometric check, is a second factor on its own
+	if !webauthn.UserVerified(data.AuthenticatorData) {
+		err = service.UserCommandServiceInterface.VerifyUserTOTP(ctx, user.WalletAddress, data.TOTPCode)
+		if err != nil {
+			return types.TokenResult{}, err
+		}
+	}
+
+	return service.startTokenFamily(user.WalletAddress, data.IPAddress, data.UserAgent)
+}
+
+// FinishWebAuthnRegistration verifies the passkey created by the authenticator and stores it
+func (service *AuthCommandService) FinishWebAuthnRegistration(ctx context.Context, data types.FinishWebAuthnRegistration) error {
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(data.WalletAddress)
+	if err != nil {
+		return err
+	}
+
+	challenge, err := service.consumeWebAuthnChallenge(data.ClientDataJSON, webauthn.CeremonyCreate, &user.WalletAddress)
+	if err != nil {
+		return err
+	}
+
+	credential, err := relyingParty().VerifyRegistration(challenge, data.ClientDataJSON, data.AttestationObject)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.InvalidWebAuthnResponse)
+	}
+
+	name := strings.TrimSpace(data.Name)
+	if len(name) == 0 {
+		name = "Passkey"
+	}
+
+	return service.AuthCommandRepositoryInterface.InsertWebAuthnCredential(repositoryTypes.CreateWebAuthnCredential{
+		ID:            generateID(),
+		WalletAddress: user.WalletAddress,
+		CredentialID:  webauthn.Encoding.EncodeToString(credential.ID),
+		PublicKey:     credential.PublicKey,
+		SignCount:     credential.SignCount,
+		Name:          name,
+	})
+}
+
+// GrantUserRole grants the role to the user, the role is added to the access tokens issued from then on
+func (service *AuthCommandService) GrantUserRole(ctx context.Context, data types.UserRole) error {
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(data.WalletAddress)
+	if err != nil {
+		return err
+	}
+
+	return service.AuthCommandRepositoryInterface.InsertUserRole(repositoryTypes.UserRole{
+		WalletAddress: user.WalletAddress,
+		Role:          data.Role,
+	})
+}
+
+// Login verifies the user credentials and issues an access and refresh token
+// Failed logins are tracked per email and per IP address, unknown emails included so locking out does not reveal registered emails
+func (service *AuthCommandService) Login(ctx context.Context, data types.Login) (types.TokenResult, error) {
+	accountKey := "login:account:" + data.Email
+	ipKey := "login:ip:" + data.IPAddress
+
+	err := service.checkLoginAttempts(accountKey, ipKey)
+	if err != nil {
+		return types.TokenResult{}, err
+	}
+
+	user, err := service.UserQueryRepositoryInterface.SelectUserByEmail(data.Email)
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			password.CheckPasswordHash(data.Password, dummyHash())
+			return types.TokenResult{}, service.recordLoginFailure(accountKey, ipKey)
+		}
+
+		return types.TokenResult{}, err
+	}
+
+	// deactivated users have an empty password and never match
+	if !password.CheckPasswordHash(data.Password, user.Password) {
+		return types.TokenResult{}, service.recordLoginFailure(accountKey, ipKey)
+	}
+
+	// a missing code is not a failed login, a wrong one is counted like a wrong password
+	err = service.UserCommandServiceInterface.VerifyUserTOTP(ctx, user.WalletAddress, data.TOTPCode)
+	if err != nil {
+		if err.Error() == apiError.InvalidTOTPCode {
+			failure := service.recordLoginFailure(accountKey, ipKey)
+			if failure.Error() == apiError.ServerError {
+				return types.TokenResult{}, failure
+			}
+		}
+
+		return types.TokenResult{}, err
+	}
+
+	err = service.AttemptStoreInterface.Reset(accountKey)
+	if err != nil {
+		log.Println(err)
+	}
+
+	// upgrade hashes made with a previous algorithm or parameters while the password is at hand
+	if password.NeedsRehash(user.Password) {
+		service.rehashPassword(user.WalletAddress, data.Password)
+	}
+
+	return service.startTokenFamily(user.WalletAddress, data.IPAddress, data.UserAgent)
+}
+
+// Logout revokes the token family of the refresh token
+func (service *AuthCommandService) Logout(ctx context.Context, refreshToken string) error {
+	storedToken, err := service.AuthQueryRepositoryInterface.SelectRefreshTokenByHash(token.HashOpaqueToken(refreshToken))
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return errors.New(apiError.InvalidRefreshToken)
+		}
+
+		return err
+	}
+
+	err = service.AuthCommandRepositoryInterface.RevokeRefreshTokenFamily(storedToken.FamilyID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RefreshToken rotates the refresh token and issues a new access token
+// Presenting an already rotated token means it leaked, so the whole family is revoked
+func (service *AuthCommandService) RefreshToken(ctx context.Context, refreshToken string) (types.TokenResult, error) {
+	storedToken, err := service.AuthQueryRepositoryInterface.SelectRefreshTokenByHash(token.HashOpaqueToken(refreshToken))
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return types.TokenResult{}, errors.New(apiError.InvalidRefreshToken)
+		}
+
+		return types.TokenResult{}, err
+	}
+
+	if storedToken.RevokedAt != nil || time.Now().After(storedToken.ExpiresAt) {
+		return types.TokenResult{}, errors.New(apiError.InvalidRefreshToken)
+	}
+
+	if storedToken.RotatedAt != nil {
+		log.Printf("[AUTH] refresh token reuse detected for family %s", storedToken.FamilyID)
+		return types.TokenResult{}, service.revokeFamily(storedToken.FamilyID)
+	}
+
+	// deactivated users can no longer refresh
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(storedToken.WalletAddress)
+	if err != nil && err.Error() != apiError.MissingRecord {
+		return types.TokenResult{}, err
+	} else if err != nil || len(user.Password) == 0 {
+		return types.TokenResult{}, service.revokeFamily(storedToken.FamilyID)
+	}
+
+	nextRefreshToken, tokenHash, err := token.GenerateOpaqueToken()
+	if err != nil {
+		log.Println(err)
+		return types.TokenResult{}, errors.New(apiError.ServerError)
+	}
+
+	refreshTokenExpiresAt := time.Now().Add(tokenConfig.RefreshTokenTTL())
+
+	err = service.AuthCommandRepositoryInterface.RotateRefreshToken(repositoryTypes.RotateRefreshToken{
+		ID: storedToken.ID,
+		Next: repositoryTypes.CreateRefreshToken{
+			ID:            generateID(),
+			FamilyID:      storedToken.FamilyID,
+			WalletAddress: storedToken.WalletAddress,
+			TokenHash:     tokenHash,
+			ExpiresAt:     refreshTokenExpiresAt,
+		},
+	})
+	if err != nil {
+		if err.Error() == apiError.InvalidRefreshToken {
+			// lost a race against another use of the same token
+			log.Printf("[AUTH] refresh token reuse detected for family %s", storedToken.FamilyID)
+			return types.TokenResult{}, service.revokeFamily(storedToken.FamilyID)
+		}
+
+		return types.TokenResult{}, err
+	}
+
+	return service.issueAccessToken(storedToken.WalletAddress, storedToken.FamilyID, nextRefreshToken, refreshTokenExpiresAt)
+}
+
+// RevokeAPIKey revokes the API key, requests with it are rejected from then on
+func (service *AuthCommandService) RevokeAPIKey(ctx context.Context, id string) error {
+	return service.AuthCommandRepositoryInterface.RevokeAPIKey(id)
+}
+
+// RevokeSession revokes the session of the user, a session of another user fails with a missing record
+// Access tokens of the session are rejected from then on and its refresh token can no longer be used
+func (service *AuthCommandService) RevokeSession(ctx context.Context, data types.RevokeSession) error {
+	session, err := service.AuthQueryRepositoryInterface.SelectSessionByID(data.ID)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(session.WalletAddress, data.WalletAddress) || session.RevokedAt != nil {
+		return errors.New(apiError.MissingRecord)
+	}
+
+	return service.AuthCommandRepositoryInterface.RevokeRefreshTokenFamily(session.ID)
+}
+
+// RevokeUserRole revokes the role from the user, access tokens already issued keep it until they expire
+func (service *AuthCommandService) RevokeUserRole(ctx context.Context, data types.UserRole) error {
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(data.WalletAddress)
+	if err != nil {
+		return err
+	}
+
+	return service.AuthCommandRepositoryInterface.DeleteUserRole(repositoryTypes.UserRole{
+		WalletAddress: user.WalletAddress,
+		Role:          data.Role,
+	})
+}
+
+// RevokeUserSessions revokes every session and refresh token of the user, used when the password is reset or the user deactivated
+func (service *AuthCommandService) RevokeUserSessions(ctx context.Context, walletAddress string) error {
+	return service.AuthCommandRepositoryInterface.RevokeSessionsByWalletAddress(walletAddress)
+}
+
+// VerifySIWE checks the signed Sign-In With Ethereum message and issues an access and refresh token like the password login
+// The nonce is consumed before the signature is checked so a message can only be tried once
+func (service *AuthCommandService) VerifySIWE(ctx context.Context, data types.VerifySIWE) (types.TokenResult, error) {
+	message, err := siwe.Parse(data.Message)
+	if err != nil {
+		log.Println(err)
+		return types.TokenResult{}, errors.New(apiError.InvalidSIWEMessage)
+	}
+
+	err = message.Validate(signInConfig.Domain(), signInConfig.AllowedChainIDs(), time.Now())
+	if err != nil {
+		log.Println(err)
+		return types.TokenResult{}, errors.New(apiError.InvalidSIWEMessage)
+	}
+
+	nonce, err := service.AuthQueryRepositoryInterface.SelectSIWENonceByHash(token.HashOpaqueToken(message.Nonce))
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return types.TokenResult{}, errors.New(apiError.InvalidSIWEMessage)
+		}
+
+		return types.TokenResult{}, err
+	}
+
+	if time.Now().After(nonce.ExpiresAt) {
+		return types.TokenResult{}, errors.New(apiError.InvalidSIWEMessage)
+	}
+
+	err = service.AuthCommandRepositoryInterface.DeleteSIWENonce(nonce.ID)
+	if err != nil {
+		return types.TokenResult{}, err
+	}
+
+	signer, err := wallet.RecoverMessageSigner([]byte(data.Message), data.Signature)
+	if err != nil || !strings.EqualFold(signer, message.Address) {
+		return types.TokenResult{}, errors.New(apiError.InvalidCredentials)
+	}
+
+	// deactivated users can no longer log in
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(message.Address)
+	if err != nil && err.Error() != apiError.MissingRecord {
+		return types.TokenResult{}, err
+	} else if err != nil || len(user.Password) == 0 {
+		return types.TokenResult{}, errors.New(apiError.InvalidCredentials)
+	}
+
+	err = service.UserCommandServiceInterface.VerifyUserTOTP(ctx, user.WalletAddress, data.TOTPCode)
+	if err != nil {
+		return types.TokenResult{}, err
+	}
+
+	return service.startTokenFamily(user.WalletAddress, data.IPAddress, data.UserAgent)
+}
+
+// VerifySession rejects the session when it was revoked or expired and records it was just used
+func (service *AuthCommandService) VerifySession(ctx context.Context, id string) error {
+	session, err := service.AuthQueryRepositoryInterface.SelectSessionByID(id)
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return errors.New(apiError.SessionRevoked)
+		}
+
+		return err
+	}
+
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return errors.New(apiError.SessionRevoked)
+	}
+
+	// the session is valid, failing to record its use should not fail the request
+	err = service.AuthCommandRepositoryInterface.UpdateSessionLastSeenAt(session.ID)
+	if err != nil {
+		log.Println(err)
+	}
+
+	return nil
+}
+
+// checkLoginAttempts rejects the login while the IP address or the account waits out a backoff or lockout
+func (service *AuthCommandService) checkLoginAttempts(accountKey, ipKey string) error {
+	now := time.Now()
+
+	ipAttempt, err := service.AttemptStoreInterface.Get(ipKey)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.ServerError)
+	}
+
+	if wait, _ := ratelimit.Delay(ipAttempt, loginPolicy(limitConfig.LoginIPMaxFailures()), now); wait > 0 {
+		return errors.New(apiError.TooManyRequests)
+	}
+
+	accountAttempt, err := service.AttemptStoreInterface.Get(accountKey)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.ServerError)
+	}
+
+	wait, locked := ratelimit.Delay(accountAttempt, loginPolicy(limitConfig.LoginMaxFailures()), now)
+	if locked {
+		return errors.New(apiError.AccountLocked)
+	} else if wait > 0 {
+		return errors.New(apiError.TooManyRequests)
+	}
+
+	return nil
+}
+
+// consumeWebAuthnChallenge looks up the challenge answered by the client data and consumes it
+// The challenge must belong to the ceremony and, for registrations, to the user
+func (service *AuthCommandService) consumeWebAuthnChallenge(clientDataJSON []byte, ceremony string, walletAddress *string) (string, error) {
+	clientData, err := webauthn.ParseClientData(clientDataJSON)
+	if err != nil || len(clientData.Challenge) == 0 {
+		return "", errors.New(apiError.InvalidWebAuthnResponse)
+	}
+
+	challenge, err := service.AuthQueryRepositoryInterface.SelectWebAuthnChallengeByHash(token.HashOpaqueToken(clientData.Challenge))
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return "", errors.New(apiError.InvalidWebAuthnResponse)
+		}
+
+		return "", err
+	}
+
+	if challenge.Ceremony != ceremony || time.Now().After(challenge.ExpiresAt) {
+		return "", errors.New(apiError.InvalidWebAuthnResponse)
+	}
+
+	if walletAddress != nil && (challenge.WalletAddress == nil || !strings.EqualFold(*challenge.WalletAddress, *walletAddress)) {
+		return "", errors.New(apiError.InvalidWebAuthnResponse)
+	}
+
+	err = service.AuthCommandRepositoryInterface.DeleteWebAuthnChallenge(challenge.ID)
+	if err != nil {
+		return "", err
+	}
+
+	return clientData.Challenge, nil
+}
+
+// issueAccessToken signs the access token of the session returned along with the refresh token
+func (service *AuthCommandService) issueAccessToken(walletAddress, sessionID, refreshToken string, refreshTokenExpiresAt time.Time) (types.TokenResult, error) {
+	claims, err := service.roleClaims(walletAddress)
+	if err != nil {
+		return types.TokenResult{}, err
+	}
+	claims[token.ClaimSessionID] = sessionID
+
+	accessToken, expiresAt, err := token.IssueAccessToken(service.JWTAuth, tokenConfig, walletAddress, claims)
+	if err != nil {
+		log.Println(err)
+		return types.TokenResult{}, errors.New(apiError.ServerError)
+	}
+
+	return types.TokenResult{
+		WalletAddress:         walletAddress,
+		AccessToken:           accessToken,
+		ExpiresAt:             expiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshTokenExpiresAt,
+	}, nil
+}
+
+// newWebAuthnChallenge stores a hash of a new challenge for the ceremony and returns the challenge
+func (service *AuthCommandService) newWebAuthnChallenge(walletAddress *string, ceremony string) (string, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		log.Println(err)
+		return "", errors.New(apiError.ServerError)
+	}
+
+	err = service.AuthCommandRepositoryInterface.InsertWebAuthnChallenge(repositoryTypes.CreateWebAuthnChallenge{
+		ID:            generateID(),
+		WalletAddress: walletAddress,
+		Ceremony:      ceremony,
+		ChallengeHash: token.HashOpaqueToken(challenge),
+		ExpiresAt:     time.Now().Add(passkeyConfig.ChallengeTTL()),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return challenge, nil
+}
+
+// recordLoginFailure counts the failed login against the account and IP address and returns the invalid credentials error
+func (service *AuthCommandService) recordLoginFailure(accountKey, ipKey string) error {
+	for _, key := range []string{accountKey, ipKey} {
+		_, err := service.AttemptStoreInterface.RecordFailure(key, limitConfig.LoginLockoutDuration())
+		if err != nil {
+			log.Println(err)
+			return errors.New(apiError.ServerError)
+		}
+	}
+
+	return errors.New(apiError.InvalidCredentials)
+}
+
+// rehashPassword stores a new hash of the password, failures are logged since the login already succeeded
+func (service *AuthCommandService) rehashPassword(walletAddress, plainPassword string) {
+	hashedPassword, err := password.HashPassword(plainPassword)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	err = service.UserCommandRepositoryInterface.UpdateUserPassword(userRepositoryTypes.UpdateUserPassword{
+		WalletAddress: walletAddress,
+		Password:      hashedPassword,
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// revokeFamily revokes the token family and returns the invalid refresh token error
+func (service *AuthCommandService) revokeFamily(familyID string) error {
+	err := service.AuthCommandRepositoryInterface.RevokeRefreshTokenFamily(familyID)
+	if err != nil {
+		return err
+	}
+
+	return errors.New(apiError.InvalidRefreshToken)
+}
+
+// roleClaims returns the roles of the user and the permissions they grant as access token claims
+// The configured admin wallet addresses always hold the admin role so the first admin can grant roles
+func (service *AuthCommandService) roleClaims(walletAddress string) (map[string]interface{}, error) {
+	userRoles, err := service.AuthQueryRepositoryInterface.SelectUserRolesByWalletAddress(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := []string{}
+	for _, userRole := range userRoles {
+		roles = append(roles, userRole.Role)
+	}
+
+	for _, adminWalletAddress := range accessConfig.AdminWalletAddresses() {
+		if strings.EqualFold(adminWalletAddress, walletAddress) && !slices.Contains(roles, rbac.RoleAdmin) {
+			roles = append(roles, rbac.RoleAdmin)
+		}
+	}
+
+	permissions := []string{}
+	if len(roles) > 0 {
+		rolePermissions, err := service.AuthQueryRepositoryInterface.SelectRolePermissions()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rolePermission := range rolePermissions {
+			if slices.Contains(roles, rolePermission.Role) && !slices.Contains(permissions, rolePermission.Permission) {
+				permissions = append(permissions, rolePermission.Permission)
+			}
+		}
+	}
+
+	slices.Sort(roles)
+	slices.Sort(permissions)
+
+	return map[string]interface{}{
+		rbac.ClaimRoles:       roles,
+		rbac.ClaimPermissions: permissions,
+	}, nil
+}
+
+// startTokenFamily issues the tokens of a new login, every login starts a new token family along with its session
+func (service *AuthCommandService) startTokenFamily(walletAddress, ipAddress, userAgent string) (types.TokenResult, error) {
+	refreshToken, tokenHash, err := token.GenerateOpaqueToken()
+	if err != nil {
+		log.Println(err)
+		return types.TokenResult{}, errors.New(apiError.ServerError)
+	}
+
+	refreshTokenID := generateID()
+	refreshTokenExpiresAt := time.Now().Add(tokenConfig.RefreshTokenTTL())
+
+	userAgent = useragent.Normalize(userAgent)
+
+	err = service.AuthCommandRepositoryInterface.InsertSession(repositoryTypes.CreateSession{
+		Device:    useragent.Device(userAgent),
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		RefreshToken: repositoryTypes.CreateRefreshToken{
+			ID:            refreshTokenID,
+			FamilyID:      refreshTokenID,
+			WalletAddress: walletAddress,
+			TokenHash:     tokenHash,
+			ExpiresAt:     refreshTokenExpiresAt,
+		},
+	})
+	if err != nil {
+		return types.TokenResult{}, err
+	}
+
+	return service.issueAccessToken(walletAddress, refreshTokenID, refreshToken, refreshTokenExpiresAt)
+}
+
+// dummyHash returns a hash of a random password made with the configured hasher
+func dummyHash() string {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = password.HashPassword(ksuid.New().String())
+	})
+
+	return dummyPasswordHash
+}
+
+// loginPolicy returns the login backoff and lockout policy with the max failures of the tracked key
+func loginPolicy(maxFailures uint) ratelimitTypes.Policy {
+	return ratelimitTypes.Policy{
+		MaxFailures:     maxFailures,
+		BackoffBase:     limitConfig.LoginBackoffBase(),
+		LockoutDuration: limitConfig.LoginLockoutDuration(),
+	}
+}
+
+// generateID generates unique id
+func generateID() string {
+	return ksuid.New().String()
+}
+
+// relyingParty returns the WebAuthn relying party of the configuration
+func relyingParty() *webauthn.RelyingParty {
+	return &webauthn.RelyingParty{
+		ID:                      passkeyConfig.RPID(),
+		Origins:                 passkeyConfig.Origins(),
+		RequireUserVerification: passkeyConfig.UserVerification() == "required",
+	}
+}
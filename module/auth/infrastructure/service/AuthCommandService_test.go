@@ -0,0 +1,928 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-chi/jwtauth/v5"
+
+	"celeste/infrastructures/ratelimit"
+	apiError "celeste/internal/errors"
+	"celeste/internal/password"
+	"celeste/internal/rbac"
+	"celeste/internal/token"
+	"celeste/internal/wallet"
+	"celeste/internal/webauthn"
+	"celeste/module/auth/domain/entity"
+	"celeste/module/auth/domain/repository"
+	repositoryTypes "celeste/module/auth/infrastructure/repository/types"
+	"celeste/module/auth/infrastructure/service/types"
+	userApplication "celeste/module/user/application"
+	userEntity "celeste/module/user/domain/entity"
+	userRepository "celeste/module/user/domain/repository"
+)
+
+const walletAddress string = "0x71C7656EC7ab88b098defB751B7401B5f6d8976F"
+
+// passwordHash is the hash of "correct horse battery staple", made once since hashing is slow on purpose
+var passwordHash = func() string {
+	hash, err := password.HashPassword("correct horse battery staple")
+	if err != nil {
+		panic(err)
+	}
+
+	return hash
+}()
+
+// fakeAuthRepository keeps API keys, sessions, refresh tokens, SIWE nonces, passkeys and roles in memory, with the same conditional updates as the MySQL repository
+type fakeAuthRepository struct {
+	repository.AuthCommandRepositoryInterface
+	repository.AuthQueryRepositoryInterface
+	apiKeys             map[string]*entity.APIKey
+	refreshTokens       map[string]*entity.RefreshToken
+	rolePermissions     []entity.RolePermission
+	sessions            map[string]*entity.Session
+	siweNonces          map[string]*entity.SIWENonce
+	userRoles           []entity.UserRole
+	webAuthnChallenges  map[string]*entity.WebAuthnChallenge
+	webAuthnCredentials map[string]*entity.WebAuthnCredential
+}
+
+func (repository *fakeAuthRepository) DeleteSIWENonce(id string) error {
+	if _, ok := repository.siweNonces[id]; !ok {
+		return errors.New(apiError.InvalidSIWEMessage)
+	}
+
+	delete(repository.siweNonces, id)
+
+	return nil
+}
+
+func (repository *fakeAuthRepository) DeleteUserRole(data repositoryTypes.UserRole) error {
+	for i, userRole := range repository.userRoles {
+		if userRole.WalletAddress == data.WalletAddress && userRole.Role == data.Role {
+			repository.userRoles = slices.Delete(repository.userRoles, i, i+1)
+			return nil
+		}
+	}
+
+	return errors.New(apiError.MissingRecord)
+}
+
+func (repository *fakeAuthRepository) DeleteWebAuthnChallenge(id string) error {
+	if _, ok := repository.webAuthnChallenges[id]; !ok {
+		return errors.New(apiError.InvalidWebAuthnResponse)
+	}
+
+	delete(repository.webAuthnChallenges, id)
+
+	return nil
+}
+
+func (repository *fakeAuthRepository) InsertAPIKey(data repositoryTypes.CreateAPIKey) error {
+	repository.apiKeys[data.ID] = &entity.APIKey{
+		ID:        data.ID,
+		Name:      data.Name,
+		Prefix:    data.Prefix,
+		KeyHash:   data.KeyHash,
+		Scopes:    data.Scopes,
+		CreatedBy: data.CreatedBy,
+		ExpiresAt: data.ExpiresAt,
+	}
+
+	return nil
+}
+
+func (repository *fakeAuthRepository) InsertSIWENonce(data repositoryTypes.CreateSIWENonce) error {
+	repository.siweNonces[data.ID] = &entity.SIWENonce{
+		ID:        data.ID,
+		NonceHash: data.NonceHash,
+		ExpiresAt: data.ExpiresAt,
+	}
+
+	return nil
+}
+
+func (repository *fakeAuthRepository) InsertSession(data repositoryTypes.CreateSession) error {
+	repository.sessions[data.RefreshToken.FamilyID] = &entity.Session{
+		ID:            data.RefreshToken.FamilyID,
+		WalletAddress: data.RefreshToken.WalletAddress,
+		ExpiresAt:     data.RefreshToken.ExpiresAt,
+	}
+
+	return repository.insertRefreshToken(data.RefreshToken)
+}
+
+func (repository *fakeAuthRepository) InsertUserRole(data repositoryTypes.UserRole) error {
+	if !slices.ContainsFunc(repository.rolePermissions, func(rolePermission entity.RolePermission) bool {
+		return rolePermission.Role == data.Role
+	}) {
+		return errors.New(apiError.InvalidRole)
+	}
+
+	// granting a role twice is a no-op like the duplicate key on the user roles
+	for _, userRole := range repository.userRoles {
+		if userRole.WalletAddress == data.WalletAddress && userRole.Role == data.Role {
+			return nil
+		}
+	}
+
+	repository.userRoles = append(repository.userRoles, entity.UserRole{
+		WalletAddress: data.WalletAddress,
+		Role:          data.Role,
+	})
+
+	return nil
+}
+
+func (repository *fakeAuthRepository) InsertWebAuthnChallenge(data repositoryTypes.CreateWebAuthnChallenge) error {
+	repository.webAuthnChallenges[data.ID] = &entity.WebAuthnChallenge{
+		ID:            data.ID,
+		WalletAddress: data.WalletAddress,
+		Ceremony:      data.Ceremony,
+		ChallengeHash: data.ChallengeHash,
+		ExpiresAt:     data.ExpiresAt,
+	}
+
+	return nil
+}
+
+func (repository *fakeAuthRepository) RevokeAPIKey(id string) error {
+	apiKey, ok := repository.apiKeys[id]
+	if !ok || apiKey.RevokedAt != nil {
+		return errors.New(apiError.MissingRecord)
+	}
+
+	revokedAt := time.Now()
+	apiKey.RevokedAt = &revokedAt
+
+	return nil
+}
+
+func (repository *fakeAuthRepository) RevokeRefreshTokenFamily(familyID string) error {
+	revokedAt := time.Now()
+
+	for _, refreshToken := range repository.refreshTokens {
+		if refreshToken.FamilyID == familyID && refreshToken.RevokedAt == nil {
+			refreshToken.RevokedAt = &revokedAt
+		}
+	}
+
+	if session, ok := repository.sessions[familyID]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &revokedAt
+	}
+
+	return nil
+}
+
+func (repository *fakeAuthRepository) RotateRefreshToken(data repositoryTypes.RotateRefreshToken) error {
+	refreshToken, ok := repository.refreshTokens[data.ID]
+	if !ok || refreshToken.RotatedAt != nil || refreshToken.RevokedAt != nil {
+		return errors.New(apiError.InvalidRefreshToken)
+	}
+
+	rotatedAt := time.Now()
+	refreshToken.RotatedAt = &rotatedAt
+	repository.sessions[data.Next.FamilyID].ExpiresAt = data.Next.ExpiresAt
+
+	return repository.insertRefreshToken(data.Next)
+}
+
+func (repository *fakeAuthRepository) SelectAPIKeyByPrefix(prefix string) (entity.APIKey, error) {
+	for _, apiKey := range repository.apiKeys {
+		if apiKey.Prefix == prefix {
+			return *apiKey, nil
+		}
+	}
+
+	return entity.APIKey{}, errors.New(apiError.MissingRecord)
+}
+
+func (repository *fakeAuthRepository) SelectRefreshTokenByHash(tokenHash string) (entity.RefreshToken, error) {
+	for _, refreshToken := range repository.refreshTokens {
+		if refreshToken.TokenHash == tokenHash {
+			return *refreshToken, nil
+		}
+	}
+
+	return entity.RefreshToken{}, errors.New(apiError.MissingRecord)
+}
+
+func (repository *fakeAuthRepository) SelectRolePermissions() ([]entity.RolePermission, error) {
+	return repository.rolePermissions, nil
+}
+
+func (repository *fakeAuthRepository) SelectSIWENonceByHash(nonceHash string) (entity.SIWENonce, error) {
+	for _, nonce := range repository.siweNonces {
+		if nonce.NonceHash == nonceHash {
+			return *nonce, nil
+		}
+	}
+
+	return entity.SIWENonce{}, errors.New(apiError.MissingRecord)
+}
+
+func (repository *fakeAuthRepository) SelectSessionByID(id string) (entity.Session, error) {
+	session, ok := repository.sessions[id]
+	if !ok {
+		return entity.Session{}, errors.New(apiError.MissingRecord)
+	}
+
+	return *session, nil
+}
+
+func (repository *fakeAuthRepository) SelectUserRolesByWalletAddress(walletAddress string) ([]entity.UserRole, error) {
+	var userRoles []entity.UserRole
+	for _, userRole := range repository.userRoles {
+		if userRole.WalletAddress == walletAddress {
+			userRoles = append(userRoles, userRole)
+		}
+	}
+
+	return userRoles, nil
+}
+
+func (repository *fakeAuthRepository) SelectWebAuthnChallengeByHash(challengeHash string) (entity.WebAuthnChallenge, error) {
+	for _, challenge := range repository.webAuthnChallenges {
+		if challenge.ChallengeHash == challengeHash {
+			return *challenge, nil
+		}
+	}
+
+	return entity.WebAuthnChallenge{}, errors.New(apiError.MissingRecord)
+}
+
+func (repository *fakeAuthRepository) SelectWebAuthnCredentialByCredentialID(credentialID string) (entity.WebAuthnCredential, error) {
+	credential, ok := repository.webAuthnCredentials[credentialID]
+	if !ok {
+		return entity.WebAuthnCredential{}, errors.New(apiError.MissingRecord)
+	}
+
+	return *credential, nil
+}
+
+func (repository *fakeAuthRepository) UpdateAPIKeyLastUsedAt(id string) error {
+	lastUsedAt := time.Now()
+	repository.apiKeys[id].LastUsedAt = &lastUsedAt
+
+	return nil
+}
+
+func (repository *fakeAuthRepository) UpdateSessionLastSeenAt(id string) error {
+	repository.sessions[id].LastSeenAt = time.Now()
+
+	return nil
+}
+
+func (repository *fakeAuthRepository) UpdateWebAuthnCredentialSignCount(data repositoryTypes.UpdateWebAuthnCredentialSignCount) error {
+	for _, credential := range repository.webAuthnCredentials {
+		if credential.ID == data.ID {
+			credential.SignCount = data.SignCount
+		}
+	}
+
+	return nil
+}
+
+func (repository *fakeAuthRepository) insertRefreshToken(data repositoryTypes.CreateRefreshToken) error {
+	repository.refreshTokens[data.ID] = &entity.RefreshToken{
+		ID:            data.ID,
+		FamilyID:      data.FamilyID,
+		WalletAddress: data.WalletAddress,
+		TokenHash:     data.TokenHash,
+		ExpiresAt:     data.ExpiresAt,
+	}
+
+	return nil
+}
+
+// fakeUserQueryRepository serves every wallet address as the user, which is also found by its email
+type fakeUserQueryRepository struct {
+	userRepository.UserQueryRepositoryInterface
+	user userEntity.User
+}
+
+func (repository *fakeUserQueryRepository) SelectUserByEmail(email string) (userEntity.User, error) {
+	if email != repository.user.Email {
+		return userEntity.User{}, errors.New(apiError.MissingRecord)
+	}
+
+	return repository.user, nil
+}
+
+func (repository *fakeUserQueryRepository) SelectUserByWalletAddress(walletAddress string) (userEntity.User, error) {
+	user := repository.user
+	user.WalletAddress = walletAddress
+
+	return user, nil
+}
+
+// fakeUserCommandService requires the TOTP code when one is set
+type fakeUserCommandService struct {
+	userApplication.UserCommandServiceInterface
+	totpCode string
+}
+
+func (service *fakeUserCommandService) VerifyUserTOTP(ctx context.Context, walletAddress, code string) error {
+	if len(service.totpCode) == 0 {
+		return nil
+	} else if len(code) == 0 {
+		return errors.New(apiError.TOTPRequired)
+	} else if code != service.totpCode {
+		return errors.New(apiError.InvalidTOTPCode)
+	}
+
+	return nil
+}
+
+// newAuthCommandService returns a service for a single user signing in with user@example.com and the password
+func newAuthCommandService() (*AuthCommandService, *fakeAuthRepository) {
+	authRepository := &fakeAuthRepository{
+		apiKeys:       map[string]*entity.APIKey{},
+		refreshTokens: map[string]*entity.RefreshToken{},
+		rolePermissions: []entity.RolePermission{
+			{Role: rbac.RoleAdmin, Permission: rbac.PermissionUsersList},
+			{Role: rbac.RoleAdmin, Permission: rbac.PermissionUsersRead},
+			{Role: rbac.RoleService, Permission: rbac.PermissionUsersRead},
+		},
+		sessions:            map[string]*entity.Session{},
+		siweNonces:          map[string]*entity.SIWENonce{},
+		webAuthnChallenges:  map[string]*entity.WebAuthnChallenge{},
+		webAuthnCredentials: map[string]*entity.WebAuthnCredential{},
+	}
+
+	service := &AuthCommandService{
+		AuthCommandRepositoryInterface: authRepository,
+		AuthQueryRepositoryInterface:   authRepository,
+		UserQueryRepositoryInterface: &fakeUserQueryRepository{
+			user: userEntity.User{
+				WalletAddress: walletAddress,
+				Email:         "user@example.com",
+				Password:      passwordHash,
+			},
+		},
+		UserCommandServiceInterface: &fakeUserCommandService{},
+		AttemptStoreInterface:       &ratelimit.MemoryAttemptStore{},
+		JWTAuth:                     jwtauth.New("HS256", []byte("secret"), nil),
+	}
+
+	return service, authRepository
+}
+
+// sessionID returns the ID of the only session of the fake repository
+func sessionID(t *testing.T, authRepository *fakeAuthRepository) string {
+	if len(authRepository.sessions) != 1 {
+		t.Fatalf("expected a single session, got %d", len(authRepository.sessions))
+	}
+
+	for id := range authRepository.sessions {
+		return id
+	}
+
+	return ""
+}
+
+// passkeyAssertion signs a login ceremony of the default relying party with the P-256 key, with the authenticator flags given
+func passkeyAssertion(t *testing.T, privateKey *ecdsa.PrivateKey, challenge string, flags byte) ([]byte, []byte, []byte) {
+	clientDataJSON, err := json.Marshal(webauthn.ClientData{
+		Type:      webauthn.CeremonyGet,
+		Challenge: challenge,
+		Origin:    "http://localhost:3000",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rpIDHash := sha256.Sum256([]byte("localhost"))
+	authenticatorData := append(rpIDHash[:], flags, 0, 0, 0, 0)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authenticatorData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return clientDataJSON, authenticatorData, signature
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	service, authRepository := newAuthCommandService()
+
+	_, err := service.CreateAPIKey(context.Background(), types.CreateAPIKey{Name: "billing", Scopes: []string{"users:everything"}})
+	if err == nil || err.Error() != apiError.InvalidScope {
+		t.Errorf("expected %s for an unknown scope, got %v", apiError.InvalidScope, err)
+	}
+
+	apiKey, err := service.CreateAPIKey(context.Background(), types.CreateAPIKey{
+		Name:   "billing",
+		Scopes: []string{rbac.PermissionUsersRead, rbac.PermissionUsersList, rbac.PermissionUsersRead},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := service.AuthenticateAPIKey(context.Background(), apiKey.Key)
+	if err != nil {
+		t.Fatalf("expected the API key to authenticate, got %v", err)
+	}
+	if !slices.Equal(res.Scopes, []string{rbac.PermissionUsersList, rbac.PermissionUsersRead}) {
+		t.Errorf("unexpected scopes %v", res.Scopes)
+	}
+	if authRepository.apiKeys[apiKey.ID].LastUsedAt == nil {
+		t.Error("expected the usage of the API key to be recorded")
+	}
+
+	expiresAt := time.Now().Add(-time.Minute)
+	expiredKey, err := service.CreateAPIKey(context.Background(), types.CreateAPIKey{Name: "expired", ExpiresAt: &expiresAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revokedKey, err := service.CreateAPIKey(context.Background(), types.CreateAPIKey{Name: "revoked"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = service.RevokeAPIKey(context.Background(), revokedKey.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = service.RevokeAPIKey(context.Background(), revokedKey.ID)
+	if err == nil || err.Error() != apiError.MissingRecord {
+		t.Errorf("expected %s when revoking a key twice, got %v", apiError.MissingRecord, err)
+	}
+
+	tests := map[string]string{
+		"malformed key":  "not-an-api-key",
+		"unknown prefix": strings.Replace(apiKey.Key, apiKey.Prefix, strings.Repeat("0", len(apiKey.Prefix)), 1),
+		"wrong secret":   apiKey.Key[:len(apiKey.Key)-4] + "AAAA",
+		"revoked key":    revokedKey.Key,
+		"expired key":    expiredKey.Key,
+	}
+
+	for name, key := range tests {
+		_, err := service.AuthenticateAPIKey(context.Background(), key)
+		if err == nil || err.Error() != apiError.InvalidAPIKey {
+			t.Errorf("%s: expected %s, got %v", name, apiError.InvalidAPIKey, err)
+		}
+	}
+}
+
+func TestFinishWebAuthnLoginTOTP(t *testing.T) {
+	const (
+		userPresent  byte = 0x01
+		userVerified byte = 0x04
+	)
+
+	service, authRepository := newAuthCommandService()
+	service.UserCommandServiceInterface = &fakeUserCommandService{totpCode: "123456"}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// COSE_Key map of the ES256 public key: kty EC2, alg ES256, crv P-256, x and y
+	publicKey := append([]byte{0xa5, 0x01, 0x02, 0x03, 0x26, 0x20, 0x01, 0x21, 0x58, 0x20}, privateKey.X.FillBytes(make([]byte, 32))...)
+	publicKey = append(append(publicKey, 0x22, 0x58, 0x20), privateKey.Y.FillBytes(make([]byte, 32))...)
+
+	credentialID := []byte("test-credential")
+	authRepository.webAuthnCredentials[webauthn.Encoding.EncodeToString(credentialID)] = &entity.WebAuthnCredential{
+		ID:            "credential",
+		WalletAddress: walletAddress,
+		CredentialID:  webauthn.Encoding.EncodeToString(credentialID),
+		PublicKey:     publicKey,
+	}
+
+	tests := map[string]struct {
+		flags     byte
+		code      string
+		errorCode string
+	}{
+		"user present without code":    {userPresent, "", apiError.TOTPRequired},
+		"user present with wrong code": {userPresent, "654321", apiError.InvalidTOTPCode},
+		"user present with code":       {userPresent, "123456", ""},
+		"user verified without code":   {userPresent | userVerified, "", ""},
+	}
+
+	for name, test := range tests {
+		begin, err := service.BeginWebAuthnLogin(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clientDataJSON, authenticatorData, signature := passkeyAssertion(t, privateKey, begin.Challenge, test.flags)
+
+		login, err := service.FinishWebAuthnLogin(context.Background(), types.FinishWebAuthnLogin{
+			CredentialID:      credentialID,
+			ClientDataJSON:    clientDataJSON,
+			AuthenticatorData: authenticatorData,
+			Signature:         signature,
+			TOTPCode:          test.code,
+			IPAddress:         "203.0.113.7",
+		})
+
+		if len(test.errorCode) > 0 {
+			if err == nil || err.Error() != test.errorCode {
+				t.Errorf("%s: expected %s, got %v", name, test.errorCode, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: expected the passkey to sign in, got %v", name, err)
+		} else if login.WalletAddress != walletAddress {
+			t.Errorf("%s: expected a token for %s, got %s", name, walletAddress, login.WalletAddress)
+		}
+	}
+}
+
+func TestLogin(t *testing.T) {
+	// failures are counted, without a backoff the next attempts still reach the password check
+	t.Setenv("LOGIN_BACKOFF_BASE", "0s")
+
+	service, authRepository := newAuthCommandService()
+
+	tests := map[string]struct {
+		email    string
+		password string
+	}{
+		"unknown email":  {"unknown@example.com", "correct horse battery staple"},
+		"wrong password": {"user@example.com", "wrong horse battery staple"},
+	}
+
+	for name, test := range tests {
+		_, err := service.Login(context.Background(), types.Login{
+			Email:     test.email,
+			Password:  test.password,
+			IPAddress: "203.0.113.7",
+		})
+		if err == nil || err.Error() != apiError.InvalidCredentials {
+			t.Errorf("%s: expected %s, got %v", name, apiError.InvalidCredentials, err)
+		}
+	}
+
+	login, err := service.Login(context.Background(), types.Login{
+		Email:     "user@example.com",
+		Password:  "correct horse battery staple",
+		IPAddress: "203.0.113.7",
+	})
+	if err != nil {
+		t.Fatalf("expected the password to sign in, got %v", err)
+	}
+
+	accessToken, err := service.JWTAuth.Decode(login.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sid, _ := accessToken.Get(token.ClaimSessionID)
+	if accessToken.Subject() != walletAddress || sid != sessionID(t, authRepository) {
+		t.Errorf("unexpected access token claims %s %v", accessToken.Subject(), sid)
+	}
+	if len(login.RefreshToken) == 0 {
+		t.Error("expected a refresh token")
+	}
+}
+
+func TestLoginDeactivatedUser(t *testing.T) {
+	service, _ := newAuthCommandService()
+
+	// deactivated users have an empty password
+	service.UserQueryRepositoryInterface.(*fakeUserQueryRepository).user.Password = ""
+
+	_, err := service.Login(context.Background(), types.Login{
+		Email:     "user@example.com",
+		IPAddress: "203.0.113.7",
+	})
+	if err == nil || err.Error() != apiError.InvalidCredentials {
+		t.Errorf("expected %s, got %v", apiError.InvalidCredentials, err)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	service, authRepository := newAuthCommandService()
+
+	_, err := service.RefreshToken(context.Background(), "unknown")
+	if err == nil || err.Error() != apiError.InvalidRefreshToken {
+		t.Errorf("expected %s for an unknown token, got %v", apiError.InvalidRefreshToken, err)
+	}
+
+	login, err := service.startTokenFamily(walletAddress, "203.0.113.7", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, refreshToken := range authRepository.refreshTokens {
+		refreshToken.ExpiresAt = time.Now().Add(-time.Minute)
+	}
+
+	_, err = service.RefreshToken(context.Background(), login.RefreshToken)
+	if err == nil || err.Error() != apiError.InvalidRefreshToken {
+		t.Errorf("expected %s for an expired token, got %v", apiError.InvalidRefreshToken, err)
+	}
+}
+
+func TestRefreshTokenDeactivatedUser(t *testing.T) {
+	service, authRepository := newAuthCommandService()
+
+	login, err := service.startTokenFamily(walletAddress, "203.0.113.7", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service.UserQueryRepositoryInterface.(*fakeUserQueryRepository).user.Password = ""
+
+	_, err = service.RefreshToken(context.Background(), login.RefreshToken)
+	if err == nil || err.Error() != apiError.InvalidRefreshToken {
+		t.Errorf("expected %s for a deactivated user, got %v", apiError.InvalidRefreshToken, err)
+	}
+
+	err = service.VerifySession(context.Background(), sessionID(t, authRepository))
+	if err == nil || err.Error() != apiError.SessionRevoked {
+		t.Errorf("expected the session of a deactivated user to be revoked, got %v", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	service, _ := newAuthCommandService()
+
+	err := service.Logout(context.Background(), "unknown")
+	if err == nil || err.Error() != apiError.InvalidRefreshToken {
+		t.Errorf("expected %s for an unknown token, got %v", apiError.InvalidRefreshToken, err)
+	}
+
+	login, err := service.startTokenFamily(walletAddress, "203.0.113.7", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.Logout(context.Background(), login.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.RefreshToken(context.Background(), login.RefreshToken)
+	if err == nil || err.Error() != apiError.InvalidRefreshToken {
+		t.Errorf("expected %s after logout, got %v", apiError.InvalidRefreshToken, err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	service, authRepository := newAuthCommandService()
+
+	login, err := service.startTokenFamily(walletAddress, "203.0.113.7", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed, err := service.RefreshToken(context.Background(), login.RefreshToken)
+	if err != nil {
+		t.Fatalf("expected the refresh token to rotate, got %v", err)
+	}
+
+	// replaying the rotated token revokes the whole family
+	_, err = service.RefreshToken(context.Background(), login.RefreshToken)
+	if err == nil || err.Error() != apiError.InvalidRefreshToken {
+		t.Errorf("expected %s for a reused token, got %v", apiError.InvalidRefreshToken, err)
+	}
+
+	_, err = service.RefreshToken(context.Background(), refreshed.RefreshToken)
+	if err == nil || err.Error() != apiError.InvalidRefreshToken {
+		t.Errorf("expected the latest token of the family to be revoked, got %v", err)
+	}
+
+	err = service.VerifySession(context.Background(), sessionID(t, authRepository))
+	if err == nil || err.Error() != apiError.SessionRevoked {
+		t.Errorf("expected %s after reuse, got %v", apiError.SessionRevoked, err)
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	service, authRepository := newAuthCommandService()
+
+	login, err := service.startTokenFamily(walletAddress, "203.0.113.7", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := sessionID(t, authRepository)
+
+	if err := service.VerifySession(context.Background(), id); err != nil {
+		t.Fatalf("expected an active session, got %v", err)
+	}
+
+	// a session of another user is not revealed
+	err = service.RevokeSession(context.Background(), types.RevokeSession{
+		WalletAddress: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2",
+		ID:            id,
+	})
+	if err == nil || err.Error() != apiError.MissingRecord {
+		t.Errorf("expected %s for another user, got %v", apiError.MissingRecord, err)
+	}
+
+	err = service.RevokeSession(context.Background(), types.RevokeSession{
+		WalletAddress: walletAddress,
+		ID:            id,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = service.VerifySession(context.Background(), id)
+	if err == nil || err.Error() != apiError.SessionRevoked {
+		t.Errorf("expected %s for a revoked session, got %v", apiError.SessionRevoked, err)
+	}
+
+	_, err = service.RefreshToken(context.Background(), login.RefreshToken)
+	if err == nil || err.Error() != apiError.InvalidRefreshToken {
+		t.Errorf("expected the refresh token of a revoked session to be rejected, got %v", err)
+	}
+
+	err = service.VerifySession(context.Background(), "unknown")
+	if err == nil || err.Error() != apiError.SessionRevoked {
+		t.Errorf("expected %s for an unknown session, got %v", apiError.SessionRevoked, err)
+	}
+}
+
+func TestVerifySIWEConsumesNonce(t *testing.T) {
+	service, authRepository := newAuthCommandService()
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonce, err := service.CreateSIWENonce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := fmt.Sprintf("localhost:3000 wants you to sign in with your Ethereum account:\n%s\n\nURI: http://localhost:3000\nVersion: 1\nChain ID: 1\nNonce: %s\nIssued At: %s",
+		wallet.Address(privateKey), nonce.Nonce, time.Now().UTC().Format(time.RFC3339))
+
+	signature, err := wallet.SignMessage(privateKey, []byte(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := types.VerifySIWE{
+		Message:   message,
+		Signature: signature,
+		IPAddress: "203.0.113.7",
+	}
+
+	login, err := service.VerifySIWE(context.Background(), data)
+	if err != nil {
+		t.Fatalf("expected the signed message to sign in, got %v", err)
+	}
+	if login.WalletAddress != wallet.Address(privateKey) {
+		t.Errorf("expected a token for %s, got %s", wallet.Address(privateKey), login.WalletAddress)
+	}
+	if len(authRepository.siweNonces) != 0 {
+		t.Errorf("expected the nonce to be consumed, %d left", len(authRepository.siweNonces))
+	}
+
+	// a captured message and signature cannot be replayed
+	_, err = service.VerifySIWE(context.Background(), data)
+	if err == nil || err.Error() != apiError.InvalidSIWEMessage {
+		t.Errorf("expected %s for a replayed message, got %v", apiError.InvalidSIWEMessage, err)
+	}
+}
+
+func TestVerifySIWETOTP(t *testing.T) {
+	service, _ := newAuthCommandService()
+	service.UserCommandServiceInterface = &fakeUserCommandService{totpCode: "123456"}
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		code      string
+		errorCode string
+	}{
+		"without code":    {"", apiError.TOTPRequired},
+		"with wrong code": {"654321", apiError.InvalidTOTPCode},
+		"with code":       {"123456", ""},
+	}
+
+	for name, test := range tests {
+		nonce, err := service.CreateSIWENonce(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		message := fmt.Sprintf("localhost:3000 wants you to sign in with your Ethereum account:\n%s\n\nURI: http://localhost:3000\nVersion: 1\nChain ID: 1\nNonce: %s\nIssued At: %s",
+			wallet.Address(privateKey), nonce.Nonce, time.Now().UTC().Format(time.RFC3339))
+
+		signature, err := wallet.SignMessage(privateKey, []byte(message))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		login, err := service.VerifySIWE(context.Background(), types.VerifySIWE{
+			Message:   message,
+			Signature: signature,
+			TOTPCode:  test.code,
+			IPAddress: "203.0.113.7",
+		})
+
+		if len(test.errorCode) > 0 {
+			if err == nil || err.Error() != test.errorCode {
+				t.Errorf("%s: expected %s, got %v", name, test.errorCode, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: expected the signed message to sign in, got %v", name, err)
+		} else if login.WalletAddress != wallet.Address(privateKey) {
+			t.Errorf("%s: expected a token for %s, got %s", name, wallet.Address(privateKey), login.WalletAddress)
+		}
+	}
+}
+
+func TestGrantUserRole(t *testing.T) {
+	service, authRepository := newAuthCommandService()
+
+	err := service.GrantUserRole(context.Background(), types.UserRole{WalletAddress: walletAddress, Role: "owner"})
+	if err == nil || err.Error() != apiError.InvalidRole {
+		t.Errorf("expected %s for an unknown role, got %v", apiError.InvalidRole, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		err = service.GrantUserRole(context.Background(), types.UserRole{WalletAddress: walletAddress, Role: rbac.RoleService})
+		if err != nil {
+			t.Fatalf("expected the role to be granted, got %v", err)
+		}
+	}
+	if len(authRepository.userRoles) != 1 {
+		t.Errorf("expected granting a role twice to keep a single grant, got %d", len(authRepository.userRoles))
+	}
+}
+
+func TestRevokeUserRole(t *testing.T) {
+	service, authRepository := newAuthCommandService()
+
+	err := service.RevokeUserRole(context.Background(), types.UserRole{WalletAddress: walletAddress, Role: rbac.RoleAdmin})
+	if err == nil || err.Error() != apiError.MissingRecord {
+		t.Errorf("expected %s for a role the user does not have, got %v", apiError.MissingRecord, err)
+	}
+
+	authRepository.userRoles = []entity.UserRole{{WalletAddress: walletAddress, Role: rbac.RoleAdmin}}
+
+	err = service.RevokeUserRole(context.Background(), types.UserRole{WalletAddress: walletAddress, Role: rbac.RoleAdmin})
+	if err != nil {
+		t.Fatalf("expected the role to be revoked, got %v", err)
+	}
+	if len(authRepository.userRoles) != 0 {
+		t.Errorf("expected no role left, got %d", len(authRepository.userRoles))
+	}
+}
+
+func TestRoleClaims(t *testing.T) {
+	const adminWalletAddress string = "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+
+	t.Setenv("ADMIN_WALLET_ADDRESSES", adminWalletAddress)
+
+	service, authRepository := newAuthCommandService()
+	authRepository.userRoles = []entity.UserRole{{WalletAddress: walletAddress, Role: rbac.RoleService}}
+
+	tests := map[string]struct {
+		walletAddress string
+		roles         []string
+		permissions   []string
+	}{
+		"granted role":       {walletAddress, []string{rbac.RoleService}, []string{rbac.PermissionUsersRead}},
+		"configured admin":   {strings.ToLower(adminWalletAddress), []string{rbac.RoleAdmin}, []string{rbac.PermissionUsersList, rbac.PermissionUsersRead}},
+		"user without roles": {"0x0000000000000000000000000000000000000001", []string{}, []string{}},
+	}
+
+	for name, test := range tests {
+		claims, err := service.roleClaims(test.walletAddress)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if roles := claims[rbac.ClaimRoles].([]string); !slices.Equal(roles, test.roles) {
+			t.Errorf("%s: expected roles %v, got %v", name, test.roles, roles)
+		}
+		if permissions := claims[rbac.ClaimPermissions].([]string); !slices.Equal(permissions, test.permissions) {
+			t.Errorf("%s: expected permissions %v, got %v", name, test.permissions, permissions)
+		}
+	}
+}
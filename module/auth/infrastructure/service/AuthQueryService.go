@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"celeste/module/auth/domain/entity"
+	"celeste/module/auth/domain/repository"
+)
+
+// AuthQueryService handles the auth query service logic
+type AuthQueryService struct {
+	repository.AuthQueryRepositoryInterface
+}
+
+// GetAPIKeys get all API keys
+func (service *AuthQueryService) GetAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	res, err := service.AuthQueryRepositoryInterface.SelectAPIKeys()
+	if err != nil {
+		return []entity.APIKey{}, err
+	}
+
+	return res, nil
+}
+
+// GetSessions get the active sessions of a user
+func (service *AuthQueryService) GetSessions(ctx context.Context, walletAddress string) ([]entity.Session, error) {
+	res, err := service.AuthQueryRepositoryInterface.SelectSessionsByWalletAddress(walletAddress)
+	if err != nil {
+		return []entity.Session{}, err
+	}
+
+	return res, nil
+}
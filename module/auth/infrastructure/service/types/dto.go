@@ -0,0 +1,104 @@
+package types
+
+import (
+	"time"
+)
+
+type Login struct {
+	Email     string
+	Password  string
+	IPAddress string
+	UserAgent string
+	TOTPCode  string
+}
+
+type TokenResult struct {
+	WalletAddress         string
+	AccessToken           string
+	ExpiresAt             time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+type BeginWebAuthnLoginResult struct {
+	Challenge        string
+	RPID             string
+	UserVerification string
+	Timeout          time.Duration
+}
+
+type BeginWebAuthnRegistrationResult struct {
+	Challenge          string
+	RPID               string
+	RPName             string
+	UserID             string // base64url encoded user handle
+	UserName           string
+	UserDisplayName    string
+	Algorithms         []int64
+	ExcludeCredentials []string // base64url encoded IDs of the credentials already registered
+	UserVerification   string
+	Timeout            time.Duration
+}
+
+type FinishWebAuthnLogin struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+	UserHandle        []byte
+	TOTPCode          string
+	IPAddress         string
+	UserAgent         string
+}
+
+type FinishWebAuthnRegistration struct {
+	WalletAddress     string
+	Name              string
+	ClientDataJSON    []byte
+	AttestationObject []byte
+}
+
+type SIWENonceResult struct {
+	Nonce     string
+	ExpiresAt time.Time
+}
+
+type VerifySIWE struct {
+	Message   string
+	Signature []byte
+	TOTPCode  string
+	IPAddress string
+	UserAgent string
+}
+
+type UserRole struct {
+	WalletAddress string
+	Role          string
+}
+
+type APIKeyResult struct {
+	ID     string
+	Name   string
+	Scopes []string
+}
+
+type CreateAPIKey struct {
+	Name      string
+	Scopes    []string
+	CreatedBy *string // wallet address of the admin, nil for internal services
+	ExpiresAt *time.Time
+}
+
+type CreateAPIKeyResult struct {
+	ID        string
+	Name      string
+	Key       string
+	Prefix    string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+type RevokeSession struct {
+	WalletAddress string
+	ID            string
+}
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	Validate         *validator.Validate = validator.New(validator.WithRequiredStructEnabled())
+	ValidationErrors map[string]string   = map[string]string{
+		"CreateAPIKeyRequest.Name":                                     "Name field is required and must be at most 255 characters.",
+		"CreateAPIKeyRequest.Scopes":                                   "Scopes field must list at least one permission.",
+		"FinishWebAuthnLoginRequest.ID":                                "ID field is required.",
+		"FinishWebAuthnLoginRequest.Type":                              "Type field must be public-key.",
+		"FinishWebAuthnLoginRequest.Response.ClientDataJSON":           "Client data JSON field is required.",
+		"FinishWebAuthnLoginRequest.Response.AuthenticatorData":        "Authenticator data field is required.",
+		"FinishWebAuthnLoginRequest.Response.Signature":                "Signature field is required.",
+		"FinishWebAuthnRegistrationRequest.ID":                         "ID field is required.",
+		"FinishWebAuthnRegistrationRequest.Type":                       "Type field must be public-key.",
+		"FinishWebAuthnRegistrationRequest.Name":                       "Name must be at most 255 characters.",
+		"FinishWebAuthnRegistrationRequest.Response.ClientDataJSON":    "Client data JSON field is required.",
+		"FinishWebAuthnRegistrationRequest.Response.AttestationObject": "Attestation object field is required.",
+		"LoginRequest.Email":                                           "Email field is required.",
+		"LoginRequest.Password":                                        "Password field is required.",
+		"LogoutRequest.RefreshToken":                                   "Refresh token field is required.",
+		"RefreshTokenRequest.RefreshToken":                             "Refresh token field is required.",
+		"VerifySIWERequest.Message":                                    "Message field is required.",
+		"VerifySIWERequest.Signature":                                  "Signature field is required.",
+	}
+)
+
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name" validate:"required,max=255"`
+	Scopes    []string `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *uint64  `json:"expiresAt"` // unix timestamp, the key never expires when empty
+}
+
+// FinishWebAuthnLoginRequest is the JSON form of the PublicKeyCredential returned by navigator.credentials.get, binary fields are base64url encoded
+type FinishWebAuthnLoginRequest struct {
+	ID       string                    `json:"id" validate:"required"`
+	Type     string                    `json:"type" validate:"required,eq=public-key"`
+	Response WebAuthnAssertionResponse `json:"response"`
+	Code     string                    `json:"code"` // TOTP or recovery code, required once two-factor authentication is enabled unless the passkey verified the user
+}
+
+type WebAuthnAssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON" validate:"required"`
+	AuthenticatorData string `json:"authenticatorData" validate:"required"`
+	Signature         string `json:"signature" validate:"required"`
+	UserHandle        string `json:"userHandle"`
+}
+
+// FinishWebAuthnRegistrationRequest is the JSON form of the PublicKeyCredential returned by navigator.credentials.create, binary fields are base64url encoded
+type FinishWebAuthnRegistrationRequest struct {
+	ID       string                      `json:"id" validate:"required"`
+	Type     string                      `json:"type" validate:"required,eq=public-key"`
+	Name     string                      `json:"name" validate:"max=255"` // label shown when listing passkeys
+	Response WebAuthnAttestationResponse `json:"response"`
+}
+
+type WebAuthnAttestationResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON" validate:"required"`
+	AttestationObject string `json:"attestationObject" validate:"required"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code"` // TOTP or recovery code, required once two-factor authentication is enabled
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// VerifySIWERequest holds the EIP-4361 message and its EIP-191 signature
+type VerifySIWERequest struct {
+	Message   string `json:"message" validate:"required"`
+	Signature string `json:"signature" validate:"required"`
+	Code      string `json:"code"` // TOTP or recovery code, required once two-factor authentication is enabled
+}
+
+type TokenResponse struct {
+	WalletAddress         string `json:"walletAddress"`
+	AccessToken           string `json:"accessToken"`
+	TokenType             string `json:"tokenType"`
+	ExpiresIn             uint64 `json:"expiresIn"`
+	ExpiresAt             uint64 `json:"expiresAt"`
+	RefreshToken          string `json:"refreshToken"`
+	RefreshTokenExpiresAt uint64 `json:"refreshTokenExpiresAt"`
+}
+
+// CreateAPIKeyResponse holds the new API key, the key itself is only returned once
+type CreateAPIKeyResponse struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Key       string   `json:"key"`
+	Prefix    string   `json:"prefix"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt *uint64  `json:"expiresAt"`
+}
+
+type APIKeyResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	CreatedBy  *string  `json:"createdBy"`
+	ExpiresAt  *uint64  `json:"expiresAt"`
+	LastUsedAt *uint64  `json:"lastUsedAt"`
+	RevokedAt  *uint64  `json:"revokedAt"`
+	CreatedAt  uint64   `json:"createdAt"`
+}
+
+type APIKeysResponse struct {
+	APIKeys []APIKeyResponse `json:"apiKeys"`
+}
+
+// SessionResponse holds a signed in device, current marks the session of the access token used for the request
+type SessionResponse struct {
+	ID         string `json:"id"`
+	Device     string `json:"device"`
+	IPAddress  string `json:"ipAddress"`
+	UserAgent  string `json:"userAgent"`
+	Current    bool   `json:"current"`
+	LastSeenAt uint64 `json:"lastSeenAt"`
+	ExpiresAt  uint64 `json:"expiresAt"`
+	CreatedAt  uint64 `json:"createdAt"`
+}
+
+type SessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+type SIWENonceResponse struct {
+	Nonce     string `json:"nonce"`
+	ExpiresAt uint64 `json:"expiresAt"`
+}
+
+// BeginWebAuthnLoginResponse holds the PublicKeyCredentialRequestOptions passed to navigator.credentials.get
+type BeginWebAuthnLoginResponse struct {
+	Challenge        string `json:"challenge"`
+	RPID             string `json:"rpId"`
+	Timeout          uint64 `json:"timeout"`
+	UserVerification string `json:"userVerification"`
+}
+
+// BeginWebAuthnRegistrationResponse holds the PublicKeyCredentialCreationOptions passed to navigator.credentials.create
+type BeginWebAuthnRegistrationResponse struct {
+	Challenge              string                         `json:"challenge"`
+	RP                     WebAuthnRelyingPartyResponse   `json:"rp"`
+	User                   WebAuthnUserResponse           `json:"user"`
+	PubKeyCredParams       []WebAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                uint64                         `json:"timeout"`
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                         `json:"attestation"`
+}
+
+type WebAuthnRelyingPartyResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebAuthnUserResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type WebAuthnCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type WebAuthnCredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
@@ -0,0 +1,1212 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+
+	iam "celeste/interfaces/http/rest/middlewares/iam"
+	"celeste/interfaces/http/rest/viewmodels"
+	"celeste/internal/errors"
+	apiError "celeste/internal/errors"
+	"celeste/internal/rbac"
+	"celeste/internal/webauthn"
+	"celeste/module/auth/application"
+	serviceTypes "celeste/module/auth/infrastructure/service/types"
+	types "celeste/module/auth/interfaces/http"
+)
+
+// AuthCommandController request controller for auth command
+type AuthCommandController struct {
+	application.AuthCommandServiceInterface
+}
+
+// BeginWebAuthnLogin request handler to start a passkey login
+func (controller *AuthCommandController) BeginWebAuthnLogin(w http.ResponseWriter, r *http.Request) {
+	res, err := controller.AuthCommandServiceInterface.BeginWebAuthnLogin(context.TODO())
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Sign in with a passkey to finish the login.",
+		Data: &types.BeginWebAuthnLoginResponse{
+			Challenge:        res.Challenge,
+			RPID:             res.RPID,
+			Timeout:          uint64(res.Timeout.Milliseconds()),
+			UserVerification: res.UserVerification,
+		},
+	}
+
+	response.JSON(w)
+}
+
+// BeginWebAuthnRegistration request handler to start the registration of a passkey for the authenticated user
+func (controller *AuthCommandController) BeginWebAuthnRegistration(w http.ResponseWriter, r *http.Request) {
+	walletAddress := iam.Subject(r.Context())
+	if len(walletAddress) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusUnauthorized,
+			Success:   false,
+			Message:   "Invalid token.",
+			ErrorCode: apiError.UnauthorizedAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	res, err := controller.AuthCommandServiceInterface.BeginWebAuthnRegistration(context.TODO(), walletAddress)
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "User not found."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	pubKeyCredParams := make([]types.WebAuthnCredentialParameter, len(res.Algorithms))
+	for i, algorithm := range res.Algorithms {
+		pubKeyCredParams[i] = types.WebAuthnCredentialParameter{Type: "public-key", Alg: algorithm}
+	}
+
+	excludeCredentials := make([]types.WebAuthnCredentialDescriptor, len(res.ExcludeCredentials))
+	for i, credentialID := range res.ExcludeCredentials {
+		excludeCredentials[i] = types.WebAuthnCredentialDescriptor{Type: "public-key", ID: credentialID}
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Create a passkey to finish the registration.",
+		Data: &types.BeginWebAuthnRegistrationResponse{
+			Challenge: res.Challenge,
+			RP: types.WebAuthnRelyingPartyResponse{
+				ID:   res.RPID,
+				Name: res.RPName,
+			},
+			User: types.WebAuthnUserResponse{
+				ID:          res.UserID,
+				Name:        res.UserName,
+				DisplayName: res.UserDisplayName,
+			},
+			PubKeyCredParams:   pubKeyCredParams,
+			Timeout:            uint64(res.Timeout.Milliseconds()),
+			ExcludeCredentials: excludeCredentials,
+			// passkeys are discoverable so the login does not ask for an email first
+			AuthenticatorSelection: types.WebAuthnAuthenticatorSelection{
+				ResidentKey:        "required",
+				RequireResidentKey: true,
+				UserVerification:   res.UserVerification,
+			},
+			Attestation: "none",
+		},
+	}
+
+	response.JSON(w)
+}
+
+// CreateAPIKey request handler to issue an API key for a service caller
+func (controller *AuthCommandController) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var request types.CreateAPIKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	var expiresAt *time.Time
+	if request.ExpiresAt != nil {
+		expiry := time.Unix(int64(*request.ExpiresAt), 0)
+		if !expiry.After(time.Now()) {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   "Expiration must be in the future.",
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		expiresAt = &expiry
+	}
+
+	// callers can only hand out the permissions they hold themselves
+	for _, scope := range request.Scopes {
+		if !iam.HasPermission(r.Context(), scope) {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusForbidden,
+				Success:   false,
+				Message:   "You can only grant scopes you hold.",
+				ErrorCode: apiError.ForbiddenAccess,
+			}
+
+			response.JSON(w)
+			return
+		}
+	}
+
+	var createdBy *string
+	if subject := iam.Subject(r.Context()); len(subject) > 0 {
+		createdBy = &subject
+	}
+
+	res, err := controller.AuthCommandServiceInterface.CreateAPIKey(context.TODO(), serviceTypes.CreateAPIKey{
+		Name:      request.Name,
+		Scopes:    request.Scopes,
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		case errors.InvalidScope:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Scopes must be known permissions."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully created the API key, store it now as it is only shown once.",
+		Data: &types.CreateAPIKeyResponse{
+			ID:        res.ID,
+			Name:      res.Name,
+			Key:       res.Key,
+			Prefix:    res.Prefix,
+			Scopes:    res.Scopes,
+			ExpiresAt: unixTimestamp(res.ExpiresAt),
+		},
+	}
+
+	response.JSON(w)
+}
+
+// CreateSIWENonce request handler to issue the nonce of a Sign-In With Ethereum message
+func (controller *AuthCommandController) CreateSIWENonce(w http.ResponseWriter, r *http.Request) {
+	res, err := controller.AuthCommandServiceInterface.CreateSIWENonce(context.TODO())
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Sign the message with this nonce to finish the login.",
+		Data: &types.SIWENonceResponse{
+			Nonce:     res.Nonce,
+			ExpiresAt: uint64(res.ExpiresAt.Unix()),
+		},
+	}
+
+	response.JSON(w)
+}
+
+// FinishWebAuthnLogin request handler to issue an access token from a passkey assertion
+func (controller *AuthCommandController) FinishWebAuthnLogin(w http.ResponseWriter, r *http.Request) {
+	var request types.FinishWebAuthnLoginRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	data := serviceTypes.FinishWebAuthnLogin{
+		TOTPCode:  request.Code,
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+
+	data.CredentialID, err = decodeBase64URL(request.ID)
+	if err == nil {
+		data.ClientDataJSON, err = decodeBase64URL(request.Response.ClientDataJSON)
+	}
+	if err == nil {
+		data.AuthenticatorData, err = decodeBase64URL(request.Response.AuthenticatorData)
+	}
+	if err == nil {
+		data.Signature, err = decodeBase64URL(request.Response.Signature)
+	}
+	if err == nil {
+		data.UserHandle, err = decodeBase64URL(request.Response.UserHandle)
+	}
+	if err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "WebAuthn response fields must be base64url encoded.",
+			ErrorCode: apiError.InvalidPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	res, err := controller.AuthCommandServiceInterface.FinishWebAuthnLogin(context.TODO(), data)
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.AccountLocked:
+			httpCode = http.StatusLocked
+			errorMsg = "Account is temporarily locked after too many wrong two-factor authentication codes."
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		case errors.InvalidCredentials:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid passkey."
+		case errors.InvalidTOTPCode:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid two-factor authentication code."
+		case errors.InvalidWebAuthnResponse:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Passkey verification failed, please try again."
+		case errors.TOTPRequired:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Two-factor authentication code is required."
+		case errors.TooManyRequests:
+			httpCode = http.StatusTooManyRequests
+			errorMsg = "Too many wrong two-factor authentication codes, please try again later."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully logged in.",
+		Data:    tokenResponse(res),
+	}
+
+	response.JSON(w)
+}
+
+// FinishWebAuthnRegistration request handler to store the passkey of the authenticated user
+func (controller *AuthCommandController) FinishWebAuthnRegistration(w http.ResponseWriter, r *http.Request) {
+	walletAddress := iam.Subject(r.Context())
+	if len(walletAddress) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusUnauthorized,
+			Success:   false,
+			Message:   "Invalid token.",
+			ErrorCode: apiError.UnauthorizedAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	var request types.FinishWebAuthnRegistrationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	data := serviceTypes.FinishWebAuthnRegistration{
+		WalletAddress: walletAddress,
+		Name:          request.Name,
+	}
+
+	data.ClientDataJSON, err = decodeBase64URL(request.Response.ClientDataJSON)
+	if err == nil {
+		data.AttestationObject, err = decodeBase64URL(request.Response.AttestationObject)
+	}
+	if err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "WebAuthn response fields must be base64url encoded.",
+			ErrorCode: apiError.InvalidPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	err = controller.AuthCommandServiceInterface.FinishWebAuthnRegistration(context.TODO(), data)
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		case errors.InvalidWebAuthnResponse:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Passkey verification failed, please try again."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "User not found."
+		case errors.WebAuthnCredentialExists:
+			httpCode = http.StatusConflict
+			errorMsg = "Passkey is already registered."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully registered passkey.",
+	}
+
+	response.JSON(w)
+}
+
+// GrantUserRole request handler to grant a role to a user
+func (controller *AuthCommandController) GrantUserRole(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	role := chi.URLParam(r, "role")
+	if len(walletAddress) == 0 || len(role) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address and role are required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	err := controller.AuthCommandServiceInterface.GrantUserRole(context.TODO(), serviceTypes.UserRole{
+		WalletAddress: walletAddress,
+		Role:          role,
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		case errors.InvalidRole:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Role does not exist."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "User not found."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully granted the role, it applies to access tokens issued from now on.",
+	}
+
+	response.JSON(w)
+}
+
+// Login request handler to issue an access token from email and password
+func (controller *AuthCommandController) Login(w http.ResponseWriter, r *http.Request) {
+	var request types.LoginRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	res, err := controller.AuthCommandServiceInterface.Login(context.TODO(), serviceTypes.Login{
+		Email:     strings.ToLower(request.Email),
+		Password:  request.Password,
+		TOTPCode:  request.Code,
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.AccountLocked:
+			httpCode = http.StatusLocked
+			errorMsg = "Account is temporarily locked after too many failed logins."
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		case errors.InvalidCredentials:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid email or password."
+		case errors.InvalidTOTPCode:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid two-factor authentication code."
+		case errors.TOTPRequired:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Two-factor authentication code is required."
+		case errors.TooManyRequests:
+			httpCode = http.StatusTooManyRequests
+			errorMsg = "Too many failed logins, please try again later."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully logged in.",
+		Data:    tokenResponse(res),
+	}
+
+	response.JSON(w)
+}
+
+// Logout request handler to revoke the refresh token family
+func (controller *AuthCommandController) Logout(w http.ResponseWriter, r *http.Request) {
+	var request types.LogoutRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	err = controller.AuthCommandServiceInterface.Logout(context.TODO(), request.RefreshToken)
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		case errors.InvalidRefreshToken:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid refresh token."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully logged out.",
+	}
+
+	response.JSON(w)
+}
+
+// RefreshToken request handler to rotate the refresh token and issue a new access token
+func (controller *AuthCommandController) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var request types.RefreshTokenRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	res, err := controller.AuthCommandServiceInterface.RefreshToken(context.TODO(), request.RefreshToken)
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		case errors.InvalidRefreshToken:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid refresh token."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully refreshed token.",
+		Data:    tokenResponse(res),
+	}
+
+	response.JSON(w)
+}
+
+// RevokeAPIKey request handler to revoke an API key
+func (controller *AuthCommandController) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if len(id) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "ID is required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	err := controller.AuthCommandServiceInterface.RevokeAPIKey(context.TODO(), id)
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "API key not found or already revoked."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully revoked the API key.",
+	}
+
+	response.JSON(w)
+}
+
+// RevokeSession request handler to sign a device out of the account
+func (controller *AuthCommandController) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	id := chi.URLParam(r, "id")
+	if len(walletAddress) == 0 || len(id) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address and ID are required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// only the account owner, an admin or an internal service may sign the account out
+	if !iam.CanActOn(r.Context(), walletAddress, rbac.PermissionUsersUpdate) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only revoke the sessions of your own account.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	err := controller.AuthCommandServiceInterface.RevokeSession(context.TODO(), serviceTypes.RevokeSession{
+		WalletAddress: walletAddress,
+		ID:            id,
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "Session not found or already revoked."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully revoked the session.",
+	}
+
+	response.JSON(w)
+}
+
+// RevokeUserRole request handler to revoke a role from a user
+func (controller *AuthCommandController) RevokeUserRole(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	role := chi.URLParam(r, "role")
+	if len(walletAddress) == 0 || len(role) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address and role are required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	err := controller.AuthCommandServiceInterface.RevokeUserRole(context.TODO(), serviceTypes.UserRole{
+		WalletAddress: walletAddress,
+		Role:          role,
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "User not found or does not have the role."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully revoked the role, access tokens already issued keep it until they expire.",
+	}
+
+	response.JSON(w)
+}
+
+// VerifySIWE request handler to log in with a signed Sign-In With Ethereum message
+func (controller *AuthCommandController) VerifySIWE(w http.ResponseWriter, r *http.Request) {
+	var request types.VerifySIWERequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	signature, err := hexutil.Decode(request.Signature)
+	if err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Signature must be a 0x prefixed hex string.",
+			ErrorCode: apiError.InvalidPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	res, err := controller.AuthCommandServiceInterface.VerifySIWE(context.TODO(), serviceTypes.VerifySIWE{
+		Message:   request.Message,
+		Signature: signature,
+		TOTPCode:  request.Code,
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.AccountLocked:
+			httpCode = http.StatusLocked
+			errorMsg = "Account is temporarily locked after too many wrong two-factor authentication codes."
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		case errors.InvalidCredentials:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid signature."
+		case errors.InvalidSIWEMessage:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid, expired or already used sign-in message."
+		case errors.InvalidTOTPCode:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid two-factor authentication code."
+		case errors.TOTPRequired:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Two-factor authentication code is required."
+		case errors.TooManyRequests:
+			httpCode = http.StatusTooManyRequests
+			errorMsg = "Too many wrong two-factor authentication codes, please try again later."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully logged in.",
+		Data:    tokenResponse(res),
+	}
+
+	response.JSON(w)
+}
+
+// tokenResponse maps the issued tokens to the response
+func tokenResponse(res serviceTypes.TokenResult) *types.TokenResponse {
+	return &types.TokenResponse{
+		WalletAddress:         res.WalletAddress,
+		AccessToken:           res.AccessToken,
+		TokenType:             "Bearer",
+		ExpiresIn:             uint64(time.Until(res.ExpiresAt).Seconds()),
+		ExpiresAt:             uint64(res.ExpiresAt.Unix()),
+		RefreshToken:          res.RefreshToken,
+		RefreshTokenExpiresAt: uint64(res.RefreshTokenExpiresAt.Unix()),
+	}
+}
+
+// clientIP returns the client address without its port, the real IP middleware already resolved the headers of trusted proxies
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// decodeBase64URL decodes a base64url field of a WebAuthn response, padded or not
+func decodeBase64URL(value string) ([]byte, error) {
+	return webauthn.Encoding.DecodeString(strings.TrimRight(value, "="))
+}
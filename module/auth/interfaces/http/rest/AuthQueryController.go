@@ -0,0 +1,173 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	iam "celeste/interfaces/http/rest/middlewares/iam"
+	"celeste/interfaces/http/rest/viewmodels"
+	"celeste/internal/errors"
+	"celeste/internal/rbac"
+	"celeste/module/auth/application"
+	"celeste/module/auth/domain/entity"
+	types "celeste/module/auth/interfaces/http"
+)
+
+// AuthQueryController request controller for auth query
+type AuthQueryController struct {
+	application.AuthQueryServiceInterface
+}
+
+// GetAPIKeys get all API keys
+func (controller *AuthQueryController) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	res, err := controller.AuthQueryServiceInterface.GetAPIKeys(context.TODO())
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	apiKeys := []types.APIKeyResponse{}
+	for _, apiKey := range res {
+		apiKeys = append(apiKeys, apiKeyResponse(apiKey))
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully fetched all API keys.",
+		Data: &types.APIKeysResponse{
+			APIKeys: apiKeys,
+		},
+	}
+
+	response.JSON(w)
+}
+
+// GetSessions get the active sessions of a user
+func (controller *AuthQueryController) GetSessions(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	if len(walletAddress) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address is required.",
+			ErrorCode: errors.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// only the account owner, an admin or an internal service may view the sessions
+	if !iam.CanActOn(r.Context(), walletAddress, rbac.PermissionUsersRead) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only view the sessions of your own account.",
+			ErrorCode: errors.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	res, err := controller.AuthQueryServiceInterface.GetSessions(context.TODO(), walletAddress)
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	currentSessionID := iam.SessionID(r.Context())
+
+	sessions := []types.SessionResponse{}
+	for _, session := range res {
+		sessions = append(sessions, types.SessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			Current:    session.ID == currentSessionID,
+			LastSeenAt: uint64(session.LastSeenAt.Unix()),
+			ExpiresAt:  uint64(session.ExpiresAt.Unix()),
+			CreatedAt:  uint64(session.CreatedAt.Unix()),
+		})
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully fetched the sessions.",
+		Data: &types.SessionsResponse{
+			Sessions: sessions,
+		},
+	}
+
+	response.JSON(w)
+}
+
+// apiKeyResponse projects the API key into the response, the key hash is never returned
+func apiKeyResponse(apiKey entity.APIKey) types.APIKeyResponse {
+	return types.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     strings.Split(apiKey.Scopes, ","),
+		CreatedBy:  apiKey.CreatedBy,
+		ExpiresAt:  unixTimestamp(apiKey.ExpiresAt),
+		LastUsedAt: unixTimestamp(apiKey.LastUsedAt),
+		RevokedAt:  unixTimestamp(apiKey.RevokedAt),
+		CreatedAt:  uint64(apiKey.CreatedAt.Unix()),
+	}
+}
+
+// unixTimestamp converts an optional time to an optional unix timestamp
+func unixTimestamp(t *time.Time) *uint64 {
+	if t == nil {
+		return nil
+	}
+
+	timestamp := uint64(t.Unix())
+	return &timestamp
+}
@@ -0,0 +1,13 @@
+package application
+
+import (
+	"context"
+)
+
+// UserAuthServiceInterface holds the auth methods the user command service relies on, implemented by the auth command service
+type UserAuthServiceInterface interface {
+	// DeleteUserWebAuthnCredentials removes every passkey of the user
+	DeleteUserWebAuthnCredentials(ctx context.Context, walletAddress string) error
+	// RevokeUserSessions revokes every session and refresh token of the user
+	RevokeUserSessions(ctx context.Context, walletAddress string) error
+}
@@ -8,14 +8,44 @@ import (
 
 // UserCommandServiceInterface holds the implementable methods for the user command service
 type UserCommandServiceInterface interface {
+	// ConfirmUserTOTP enables two-factor authentication and returns the recovery codes
+	ConfirmUserTOTP(ctx context.Context, data types.ConfirmUserTOTP) (types.UserRecoveryCodesResult, error)
 	// CreateUser creates a new user
 	CreateUser(ctx context.Context, data types.CreateUser) (types.CreateUserResult, error)
 	// DeactivateUser deactivates user
 	DeactivateUser(ctx context.Context, walletAddress string) error
+	// DisableUserTOTP disables two-factor authentication
+	DisableUserTOTP(ctx context.Context, data types.DisableUserTOTP) error
+	// EnrollUserTOTP generates a pending TOTP secret for the user
+	EnrollUserTOTP(ctx context.Context, walletAddress string) (types.EnrollUserTOTPResult, error)
+	// RecoverUserWallet reconstructs the user wallet from a client share
+	RecoverUserWallet(ctx context.Context, data types.RecoverUserWallet) (types.RecoverUserWalletResult, error)
+	// ReencryptUserShares encrypts the server shares that are not yet under the active KMS key
+	ReencryptUserShares(ctx context.Context) (uint, error)
+	// RegenerateUserRecoveryCodes replaces the recovery codes of the user
+	RegenerateUserRecoveryCodes(ctx context.Context, data types.RegenerateUserRecoveryCodes) (types.UserRecoveryCodesResult, error)
+	// ResendEmailVerification sends a new email verification token to an unverified user
+	ResendEmailVerification(ctx context.Context, email string) error
+	// ResetUserPassword sets a new password with the emailed reset token and revokes every session of the user
+	ResetUserPassword(ctx context.Context, data types.ResetUserPassword) error
+	// RotateUserShareKey creates a new KMS key version for the server shares
+	RotateUserShareKey(ctx context.Context) error
+	// RotateUserShares re-splits the user wallet key so previously issued shares become useless
+	RotateUserShares(ctx context.Context, data types.RotateUserShares) (types.RotateUserSharesResult, error)
+	// SendPasswordReset emails a password reset token to the user
+	SendPasswordReset(ctx context.Context, email string) error
+	// SignMessage signs an EIP-191 personal message with the user wallet
+	SignMessage(ctx context.Context, data types.SignMessage) (types.SignMessageResult, error)
+	// SignTransaction signs an EIP-1559 transaction with the user wallet
+	SignTransaction(ctx context.Context, data types.SignTransaction) (types.SignTransactionResult, error)
+	// SignTypedData signs EIP-712 typed data with the user wallet
+	SignTypedData(ctx context.Context, data types.SignTypedData) (types.SignTypedDataResult, error)
 	// UpdateUser updates user
 	UpdateUser(ctx context.Context, data types.UpdateUser) error
-	// UpdateUserEmailVerifiedAt updates user email verified at
-	UpdateUserEmailVerifiedAt(ctx context.Context, email string) error
+	// UpdateUserEmailVerifiedAt verifies the user email with the emailed verification token
+	UpdateUserEmailVerifiedAt(ctx context.Context, verificationToken string) error
 	// UpdateUserPassword updates user password
 	UpdateUserPassword(ctx context.Context, data types.UpdateUserPassword) error
+	// VerifyUserTOTP checks the TOTP or recovery code of a user with two-factor authentication enabled
+	VerifyUserTOTP(ctx context.Context, walletAddress, code string) error
 }
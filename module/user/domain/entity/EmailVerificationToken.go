@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+)
+
+// EmailVerificationToken holds the email verification token entity fields
+type EmailVerificationToken struct {
+	ID            string
+	WalletAddress string     `db:"wallet_address"`
+	TokenHash     string     `db:"token_hash"`
+	ExpiresAt     time.Time  `db:"expires_at"`
+	UsedAt        *time.Time `db:"used_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+// GetModelName returns the model name of email verification token entity that can be used for naming schemas
+func (entity *EmailVerificationToken) GetModelName() string {
+	return "email_verification_tokens"
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+)
+
+// PasswordResetToken holds the password reset token entity fields
+type PasswordResetToken struct {
+	ID            string
+	WalletAddress string     `db:"wallet_address"`
+	TokenHash     string     `db:"token_hash"`
+	ExpiresAt     time.Time  `db:"expires_at"`
+	UsedAt        *time.Time `db:"used_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+// GetModelName returns the model name of password reset token entity that can be used for naming schemas
+func (entity *PasswordResetToken) GetModelName() string {
+	return "password_reset_tokens"
+}
@@ -9,7 +9,11 @@ type User struct {
 	WalletAddress   string `db:"wallet_address"`
 	Email           string
 	Password        string
-	SSS1            string `db:"sss_1"`
+	SSS1            string  `db:"sss_1"`
+	SSS1DEK         *string `db:"sss_1_dek"`
+	SSS1KeyID       *string `db:"sss_1_key_id"`
+	ShareThreshold  uint8   `db:"share_threshold"`
+	ShareCount      uint8   `db:"share_count"`
 	Name            string
 	EmailVerifiedAt *time.Time `db:"email_verified_at"`
 	CreatedAt       time.Time  `db:"created_at"`
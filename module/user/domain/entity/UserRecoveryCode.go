@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+)
+
+// UserRecoveryCode holds the user recovery code entity fields
+type UserRecoveryCode struct {
+	ID            string
+	WalletAddress string     `db:"wallet_address"`
+	CodeHash      string     `db:"code_hash"`
+	UsedAt        *time.Time `db:"used_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+// GetModelName returns the model name of user recovery code entity that can be used for naming schemas
+func (entity *UserRecoveryCode) GetModelName() string {
+	return "user_recovery_codes"
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+)
+
+const (
+	// HolderTypeServer is the share kept by the service in the users table
+	HolderTypeServer string = "server"
+	// HolderTypeDevice is the share kept on the user device
+	HolderTypeDevice string = "device"
+	// HolderTypeBackup is the share kept by the user as backup
+	HolderTypeBackup string = "backup"
+	// HolderTypeGuardian is the share kept by a trusted guardian
+	HolderTypeGuardian string = "guardian"
+)
+
+// UserShare holds the user share entity fields
+type UserShare struct {
+	WalletAddress string    `db:"wallet_address"`
+	ShareIndex    uint8     `db:"share_index"`
+	HolderType    string    `db:"holder_type"`
+	Metadata      *string   `db:"metadata"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// GetModelName returns the model name of user share entity that can be used for naming schemas
+func (entity *UserShare) GetModelName() string {
+	return "user_shares"
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+)
+
+// UserTOTP holds the user TOTP entity fields
+// The secret is envelope encrypted like the server share, two-factor authentication is enabled once confirmed
+type UserTOTP struct {
+	WalletAddress string     `db:"wallet_address"`
+	Secret        string     `db:"secret"`
+	SecretDEK     string     `db:"secret_dek"`
+	SecretKeyID   string     `db:"secret_key_id"`
+	LastUsedStep  *int64     `db:"last_used_step"`
+	ConfirmedAt   *time.Time `db:"confirmed_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+// GetModelName returns the model name of user TOTP entity that can be used for naming schemas
+func (entity *UserTOTP) GetModelName() string {
+	return "user_totp"
+}
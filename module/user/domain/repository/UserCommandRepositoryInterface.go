@@ -6,14 +6,34 @@ import (
 
 // UserCommandRepositoryInterface holds the implementable methods for user command repository
 type UserCommandRepositoryInterface interface {
+	// ConfirmUserTOTP enables the pending TOTP secret and replaces the recovery codes of the user
+	ConfirmUserTOTP(data types.ConfirmUserTOTP) error
 	// DeactivateUser deactivates user
 	DeactivateUser(data types.DeactivateUser) error
+	// DeleteUserTOTP disables two-factor authentication and removes the recovery codes of the user
+	DeleteUserTOTP(walletAddress string) error
+	// InsertEmailVerificationToken inserts a new email verification token
+	InsertEmailVerificationToken(data types.CreateEmailVerificationToken) error
+	// InsertPasswordResetToken inserts a new password reset token
+	InsertPasswordResetToken(data types.CreatePasswordResetToken) error
 	// InsertUser inserts a new user
 	InsertUser(data types.CreateUser) error
+	// InsertUserTOTP stores a pending TOTP secret replacing any previous pending one
+	InsertUserTOTP(data types.CreateUserTOTP) error
+	// ReplaceUserRecoveryCodes replaces the recovery codes of the user
+	ReplaceUserRecoveryCodes(data types.ReplaceUserRecoveryCodes) error
+	// ResetUserPassword consumes the reset token and updates user password
+	ResetUserPassword(data types.ResetUserPassword) error
 	// UpdateUser updates user
 	UpdateUser(data types.UpdateUser) error
-	// UpdateUserEmailVerifiedAt updates user email verified at
-	UpdateUserEmailVerifiedAt(email string) error
+	// UpdateUserEmailVerifiedAt consumes the verification token and updates user email verified at
+	UpdateUserEmailVerifiedAt(data types.UpdateUserEmailVerifiedAt) error
 	// UpdateUserPassword updates user password
 	UpdateUserPassword(data types.UpdateUserPassword) error
+	// UpdateUserShare updates the server held share of the user
+	UpdateUserShare(data types.UpdateUserShare) error
+	// UpdateUserTOTPLastUsedStep records the step of the accepted code, earlier or equal steps are rejected
+	UpdateUserTOTPLastUsedStep(data types.UpdateUserTOTPLastUsedStep) error
+	// UseUserRecoveryCode consumes the recovery code
+	UseUserRecoveryCode(data types.UseUserRecoveryCode) error
 }
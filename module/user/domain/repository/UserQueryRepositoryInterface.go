@@ -6,10 +6,20 @@ import (
 
 // UserQueryRepositoryInterface holds the implementable method for user query repository
 type UserQueryRepositoryInterface interface {
+	// SelectEmailVerificationTokenByHash select an email verification token by its hash
+	SelectEmailVerificationTokenByHash(tokenHash string) (entity.EmailVerificationToken, error)
+	// SelectPasswordResetTokenByHash select a password reset token by its hash
+	SelectPasswordResetTokenByHash(tokenHash string) (entity.PasswordResetToken, error)
 	// SelectUsers select all users
 	SelectUsers(page uint, search *string) ([]entity.User, uint, error)
 	// SelectUserByWalletAddress select a user by wallet address
 	SelectUserByWalletAddress(walletAddress string) (entity.User, error)
 	// SelectUserByEmail select a user by email
 	SelectUserByEmail(email string) (entity.User, error)
+	// SelectUserTOTPByWalletAddress select the TOTP secret of a user
+	SelectUserTOTPByWalletAddress(walletAddress string) (entity.UserTOTP, error)
+	// SelectUsersPendingShareReencryption select the users whose server share is not under the key id
+	SelectUsersPendingShareReencryption(keyID string) ([]entity.User, error)
+	// SelectUserSharesByWalletAddress select the share holders of a user
+	SelectUserSharesByWalletAddress(walletAddress string) ([]entity.UserShare, error)
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
 
 	"celeste/infrastructures/database/mysql/types"
 	apiError "celeste/internal/errors"
@@ -19,6 +20,53 @@ type UserCommandRepository struct {
 	types.MySQLDBHandlerInterface
 }
 
+// ConfirmUserTOTP enables the pending TOTP secret and replaces the recovery codes of the user
+func (repository *UserCommandRepository) ConfirmUserTOTP(data repositoryTypes.ConfirmUserTOTP) error {
+	now := time.Now()
+
+	userTOTP := &entity.UserTOTP{
+		WalletAddress: data.WalletAddress,
+		LastUsedStep:  &data.Step,
+		ConfirmedAt:   &now,
+	}
+
+	tx, err := repository.MySQLDBHandlerInterface.Begin()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+	defer tx.Rollback()
+
+	// confirm the pending secret
+	stmt := fmt.Sprintf("UPDATE %s SET confirmed_at=:confirmed_at, last_used_step=:last_used_step WHERE wallet_address=:wallet_address AND confirmed_at IS NULL", userTOTP.GetModelName())
+	res, err := tx.NamedExec(stmt, userTOTP)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	} else if rowsAffected == 0 {
+		return errors.New(apiError.TOTPAlreadyEnabled)
+	}
+
+	err = replaceRecoveryCodes(tx, data.WalletAddress, data.RecoveryCodes)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
 // DeactivateUser deactivates user
 func (repository *UserCommandRepository) DeactivateUser(data repositoryTypes.DeactivateUser) error {
 	user := &entity.User{
@@ -29,9 +77,125 @@ func (repository *UserCommandRepository) DeactivateUser(data repositoryTypes.Dea
 		Name:          data.Name,
 	}
 
+	tx, err := repository.MySQLDBHandlerInterface.Begin()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+	defer tx.Rollback()
+
 	// deactivate user
-	stmt := fmt.Sprintf("UPDATE %s SET email=:email, password=:password, sss_1=:sss_1, name=:name WHERE wallet_address=:wallet_address", user.GetModelName())
-	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, user)
+	stmt := fmt.Sprintf("UPDATE %s SET email=:email, password=:password, sss_1=:sss_1, sss_1_dek=:sss_1_dek, sss_1_key_id=:sss_1_key_id, name=:name WHERE wallet_address=:wallet_address", user.GetModelName())
+	_, err = tx.NamedExec(stmt, user)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	// remove share holder metadata
+	userShare := &entity.UserShare{
+		WalletAddress: data.WalletAddress,
+	}
+
+	stmt = fmt.Sprintf("DELETE FROM %s WHERE wallet_address=:wallet_address", userShare.GetModelName())
+	_, err = tx.NamedExec(stmt, userShare)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	// remove pending email verification tokens
+	verificationToken := &entity.EmailVerificationToken{
+		WalletAddress: data.WalletAddress,
+	}
+
+	stmt = fmt.Sprintf("DELETE FROM %s WHERE wallet_address=:wallet_address", verificationToken.GetModelName())
+	_, err = tx.NamedExec(stmt, verificationToken)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	// remove pending password reset tokens
+	resetToken := &entity.PasswordResetToken{
+		WalletAddress: data.WalletAddress,
+	}
+
+	stmt = fmt.Sprintf("DELETE FROM %s WHERE wallet_address=:wallet_address", resetToken.GetModelName())
+	_, err = tx.NamedExec(stmt, resetToken)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	// remove two-factor authentication
+	err = deleteUserTOTP(tx, data.WalletAddress)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// DeleteUserTOTP disables two-factor authentication and removes the recovery codes of the user
+func (repository *UserCommandRepository) DeleteUserTOTP(walletAddress string) error {
+	tx, err := repository.MySQLDBHandlerInterface.Begin()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+	defer tx.Rollback()
+
+	err = deleteUserTOTP(tx, walletAddress)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// InsertEmailVerificationToken creates a new email verification token
+func (repository *UserCommandRepository) InsertEmailVerificationToken(data repositoryTypes.CreateEmailVerificationToken) error {
+	verificationToken := &entity.EmailVerificationToken{
+		ID:            data.ID,
+		WalletAddress: data.WalletAddress,
+		TokenHash:     data.TokenHash,
+		ExpiresAt:     data.ExpiresAt,
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (id, wallet_address, token_hash, expires_at) VALUES (:id, :wallet_address, :token_hash, :expires_at)", verificationToken.GetModelName())
+	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, verificationToken)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// InsertPasswordResetToken creates a new password reset token
+func (repository *UserCommandRepository) InsertPasswordResetToken(data repositoryTypes.CreatePasswordResetToken) error {
+	resetToken := &entity.PasswordResetToken{
+		ID:            data.ID,
+		WalletAddress: data.WalletAddress,
+		TokenHash:     data.TokenHash,
+		ExpiresAt:     data.ExpiresAt,
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (id, wallet_address, token_hash, expires_at) VALUES (:id, :wallet_address, :token_hash, :expires_at)", resetToken.GetModelName())
+	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, resetToken)
 	if err != nil {
 		log.Println(err)
 		return errors.New(apiError.DatabaseError)
@@ -43,15 +207,36 @@ func (repository *UserCommandRepository) DeactivateUser(data repositoryTypes.Dea
 // InsertUser creates a new user
 func (repository *UserCommandRepository) InsertUser(data repositoryTypes.CreateUser) error {
 	user := entity.User{
-		WalletAddress: data.WalletAddress,
-		Email:         data.Email,
-		Password:      data.Password,
-		SSS1:          data.SSS1,
-		Name:          data.Name,
+		WalletAddress:  data.WalletAddress,
+		Email:          data.Email,
+		Password:       data.Password,
+		SSS1:           data.SSS1,
+		SSS1DEK:        data.SSS1DEK,
+		SSS1KeyID:      data.SSS1KeyID,
+		ShareThreshold: data.ShareThreshold,
+		ShareCount:     data.ShareCount,
+		Name:           data.Name,
 	}
 
-	stmt := fmt.Sprintf("INSERT INTO %s (wallet_address, email, password, sss_1, name) VALUES (:wallet_address, :email, :password, :sss_1, :name)", user.GetModelName())
-	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, user)
+	var userShares []entity.UserShare
+	for _, share := range data.Shares {
+		userShares = append(userShares, entity.UserShare{
+			WalletAddress: data.WalletAddress,
+			ShareIndex:    share.ShareIndex,
+			HolderType:    share.HolderType,
+			Metadata:      share.Metadata,
+		})
+	}
+
+	tx, err := repository.MySQLDBHandlerInterface.Begin()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+	defer tx.Rollback()
+
+	stmt := fmt.Sprintf("INSERT INTO %s (wallet_address, email, password, sss_1, sss_1_dek, sss_1_key_id, share_threshold, share_count, name) VALUES (:wallet_address, :email, :password, :sss_1, :sss_1_dek, :sss_1_key_id, :share_threshold, :share_count, :name)", user.GetModelName())
+	_, err = tx.NamedExec(stmt, user)
 	if err != nil {
 		var mysqlErr *mysql.MySQLError
 		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
@@ -60,6 +245,154 @@ func (repository *UserCommandRepository) InsertUser(data repositoryTypes.CreateU
 		return errors.New(apiError.DatabaseError)
 	}
 
+	// insert share holder metadata
+	if len(userShares) > 0 {
+		stmt = fmt.Sprintf("INSERT INTO %s (wallet_address, share_index, holder_type, metadata) VALUES (:wallet_address, :share_index, :holder_type, :metadata)", (&entity.UserShare{}).GetModelName())
+		_, err = tx.NamedExec(stmt, userShares)
+		if err != nil {
+			log.Println(err)
+			return errors.New(apiError.DatabaseError)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// InsertUserTOTP stores a pending TOTP secret replacing any previous pending one
+// A confirmed secret is never replaced, the insert fails with TOTP already enabled instead
+func (repository *UserCommandRepository) InsertUserTOTP(data repositoryTypes.CreateUserTOTP) error {
+	userTOTP := &entity.UserTOTP{
+		WalletAddress: data.WalletAddress,
+		Secret:        data.Secret,
+		SecretDEK:     data.SecretDEK,
+		SecretKeyID:   data.SecretKeyID,
+	}
+
+	tx, err := repository.MySQLDBHandlerInterface.Begin()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+	defer tx.Rollback()
+
+	// remove the previous pending secret
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE wallet_address=:wallet_address AND confirmed_at IS NULL", userTOTP.GetModelName())
+	_, err = tx.NamedExec(stmt, userTOTP)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	stmt = fmt.Sprintf("INSERT INTO %s (wallet_address, secret, secret_dek, secret_key_id) VALUES (:wallet_address, :secret, :secret_dek, :secret_key_id)", userTOTP.GetModelName())
+	_, err = tx.NamedExec(stmt, userTOTP)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return errors.New(apiError.TOTPAlreadyEnabled)
+		}
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// ReplaceUserRecoveryCodes replaces the recovery codes of the user
+func (repository *UserCommandRepository) ReplaceUserRecoveryCodes(data repositoryTypes.ReplaceUserRecoveryCodes) error {
+	tx, err := repository.MySQLDBHandlerInterface.Begin()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+	defer tx.Rollback()
+
+	err = replaceRecoveryCodes(tx, data.WalletAddress, data.RecoveryCodes)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// ResetUserPassword consumes the reset token and updates user password
+// Only one caller can consume a token, repeated uses fail with an invalid token
+func (repository *UserCommandRepository) ResetUserPassword(data repositoryTypes.ResetUserPassword) error {
+	now := time.Now()
+
+	resetToken := &entity.PasswordResetToken{
+		ID:            data.TokenID,
+		WalletAddress: data.WalletAddress,
+		UsedAt:        &now,
+	}
+
+	user := &entity.User{
+		WalletAddress: data.WalletAddress,
+		Password:      data.Password,
+	}
+
+	tx, err := repository.MySQLDBHandlerInterface.Begin()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+	defer tx.Rollback()
+
+	// consume reset token
+	stmt := fmt.Sprintf("UPDATE %s SET used_at=:used_at WHERE id=:id AND used_at IS NULL", resetToken.GetModelName())
+	res, err := tx.NamedExec(stmt, resetToken)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	} else if rowsAffected == 0 {
+		return errors.New(apiError.InvalidToken)
+	}
+
+	// invalidate the other outstanding reset tokens of the user
+	stmt = fmt.Sprintf("UPDATE %s SET used_at=:used_at WHERE wallet_address=:wallet_address AND used_at IS NULL", resetToken.GetModelName())
+	_, err = tx.NamedExec(stmt, resetToken)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	// update user password
+	stmt = fmt.Sprintf("UPDATE %s SET password=:password WHERE wallet_address=:wallet_address", user.GetModelName())
+	_, err = tx.NamedExec(stmt, user)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
 	return nil
 }
 
@@ -81,18 +414,53 @@ func (repository *UserCommandRepository) UpdateUser(data repositoryTypes.UpdateU
 	return nil
 }
 
-// UpdateUserEmailVerifiedAt updates user email verified at
-func (repository *UserCommandRepository) UpdateUserEmailVerifiedAt(email string) error {
-	emailVerifiedAt := time.Now()
+// UpdateUserEmailVerifiedAt consumes the verification token and updates user email verified at
+// Only one caller can consume a token, repeated uses fail with an invalid token
+func (repository *UserCommandRepository) UpdateUserEmailVerifiedAt(data repositoryTypes.UpdateUserEmailVerifiedAt) error {
+	now := time.Now()
+
+	verificationToken := &entity.EmailVerificationToken{
+		ID:     data.TokenID,
+		UsedAt: &now,
+	}
 
 	user := &entity.User{
-		Email:           email,
-		EmailVerifiedAt: &emailVerifiedAt,
+		WalletAddress:   data.WalletAddress,
+		EmailVerifiedAt: &now,
+	}
+
+	tx, err := repository.MySQLDBHandlerInterface.Begin()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+	defer tx.Rollback()
+
+	// consume verification token
+	stmt := fmt.Sprintf("UPDATE %s SET used_at=:used_at WHERE id=:id AND used_at IS NULL", verificationToken.GetModelName())
+	res, err := tx.NamedExec(stmt, verificationToken)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	} else if rowsAffected == 0 {
+		return errors.New(apiError.InvalidToken)
 	}
 
 	// update user email verified at
-	stmt := fmt.Sprintf("UPDATE %s SET email_verified_at=:email_verified_at WHERE email=:email", user.GetModelName())
-	_, err := repository.MySQLDBHandlerInterface.Execute(stmt, user)
+	stmt = fmt.Sprintf("UPDATE %s SET email_verified_at=:email_verified_at WHERE wallet_address=:wallet_address", user.GetModelName())
+	_, err = tx.NamedExec(stmt, user)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		log.Println(err)
 		return errors.New(apiError.DatabaseError)
@@ -119,3 +487,152 @@ func (repository *UserCommandRepository) UpdateUserPassword(data repositoryTypes
 
 	return nil
 }
+
+// UpdateUserShare updates the server held share of the user
+// Only the share the update was computed from is replaced, a share changed meanwhile fails with a rotated share
+func (repository *UserCommandRepository) UpdateUserShare(data repositoryTypes.UpdateUserShare) error {
+	user := &entity.User{}
+
+	params := map[string]interface{}{
+		"wallet_address": data.WalletAddress,
+		"sss_1":          data.SSS1,
+		"sss_1_dek":      data.SSS1DEK,
+		"sss_1_key_id":   data.SSS1KeyID,
+		"previous_sss_1": data.PreviousSSS1,
+	}
+
+	// update user share, unless another update replaced it since it was read
+	stmt := fmt.Sprintf("UPDATE %s SET sss_1=:sss_1, sss_1_dek=:sss_1_dek, sss_1_key_id=:sss_1_key_id WHERE wallet_address=:wallet_address AND sss_1=:previous_sss_1", user.GetModelName())
+	res, err := repository.MySQLDBHandlerInterface.Execute(stmt, params)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	} else if rowsAffected == 0 {
+		return errors.New(apiError.ShareRotated)
+	}
+
+	return nil
+}
+
+// UpdateUserTOTPLastUsedStep records the step of the accepted code
+// Only steps after the last used one are accepted so a code cannot be replayed, others fail with an invalid TOTP code
+func (repository *UserCommandRepository) UpdateUserTOTPLastUsedStep(data repositoryTypes.UpdateUserTOTPLastUsedStep) error {
+	userTOTP := &entity.UserTOTP{
+		WalletAddress: data.WalletAddress,
+		LastUsedStep:  &data.Step,
+	}
+
+	stmt := fmt.Sprintf("UPDATE %s SET last_used_step=:last_used_step WHERE wallet_address=:wallet_address AND confirmed_at IS NOT NULL AND (last_used_step IS NULL OR last_used_step < :last_used_step)", userTOTP.GetModelName())
+	res, err := repository.MySQLDBHandlerInterface.Execute(stmt, userTOTP)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	} else if rowsAffected == 0 {
+		return errors.New(apiError.InvalidTOTPCode)
+	}
+
+	return nil
+}
+
+// UseUserRecoveryCode consumes the recovery code, unknown or already used codes fail with an invalid TOTP code
+func (repository *UserCommandRepository) UseUserRecoveryCode(data repositoryTypes.UseUserRecoveryCode) error {
+	usedAt := time.Now()
+
+	recoveryCode := &entity.UserRecoveryCode{
+		WalletAddress: data.WalletAddress,
+		CodeHash:      data.CodeHash,
+		UsedAt:        &usedAt,
+	}
+
+	stmt := fmt.Sprintf("UPDATE %s SET used_at=:used_at WHERE wallet_address=:wallet_address AND code_hash=:code_hash AND used_at IS NULL", recoveryCode.GetModelName())
+	res, err := repository.MySQLDBHandlerInterface.Execute(stmt, recoveryCode)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	} else if rowsAffected == 0 {
+		return errors.New(apiError.InvalidTOTPCode)
+	}
+
+	return nil
+}
+
+// deleteUserTOTP removes the TOTP secret and recovery codes of the user within the transaction
+func deleteUserTOTP(tx *sqlx.Tx, walletAddress string) error {
+	userTOTP := &entity.UserTOTP{
+		WalletAddress: walletAddress,
+	}
+
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE wallet_address=:wallet_address", userTOTP.GetModelName())
+	_, err := tx.NamedExec(stmt, userTOTP)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	recoveryCode := &entity.UserRecoveryCode{
+		WalletAddress: walletAddress,
+	}
+
+	stmt = fmt.Sprintf("DELETE FROM %s WHERE wallet_address=:wallet_address", recoveryCode.GetModelName())
+	_, err = tx.NamedExec(stmt, recoveryCode)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
+
+// replaceRecoveryCodes removes the recovery codes of the user and inserts the new ones within the transaction
+func replaceRecoveryCodes(tx *sqlx.Tx, walletAddress string, codes []repositoryTypes.CreateUserRecoveryCode) error {
+	recoveryCode := &entity.UserRecoveryCode{
+		WalletAddress: walletAddress,
+	}
+
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE wallet_address=:wallet_address", recoveryCode.GetModelName())
+	_, err := tx.NamedExec(stmt, recoveryCode)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	if len(codes) == 0 {
+		return nil
+	}
+
+	var recoveryCodes []entity.UserRecoveryCode
+	for _, code := range codes {
+		recoveryCodes = append(recoveryCodes, entity.UserRecoveryCode{
+			ID:            code.ID,
+			WalletAddress: walletAddress,
+			CodeHash:      code.CodeHash,
+		})
+	}
+
+	stmt = fmt.Sprintf("INSERT INTO %s (id, wallet_address, code_hash) VALUES (:id, :wallet_address, :code_hash)", recoveryCode.GetModelName())
+	_, err = tx.NamedExec(stmt, recoveryCodes)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.DatabaseError)
+	}
+
+	return nil
+}
@@ -15,6 +15,33 @@ type UserCommandRepositoryCircuitBreaker struct {
 
 var config = hystrix_config.Config{}
 
+// ConfirmUserTOTP decorator pattern to confirm user TOTP
+func (repository *UserCommandRepositoryCircuitBreaker) ConfirmUserTOTP(data repositoryTypes.ConfirmUserTOTP) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("confirm_user_totp", config.Settings())
+	errors := hystrix.Go("confirm_user_totp", func() error {
+		err := repository.UserCommandRepositoryInterface.ConfirmUserTOTP(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
 // DeactivateUser decorator pattern to deactivate user
 func (repository *UserCommandRepositoryCircuitBreaker) DeactivateUser(data repositoryTypes.DeactivateUser) error {
 	output := make(chan error, 1)
@@ -42,6 +69,87 @@ func (repository *UserCommandRepositoryCircuitBreaker) DeactivateUser(data repos
 	}
 }
 
+// DeleteUserTOTP decorator pattern to delete user TOTP
+func (repository *UserCommandRepositoryCircuitBreaker) DeleteUserTOTP(walletAddress string) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("delete_user_totp", config.Settings())
+	errors := hystrix.Go("delete_user_totp", func() error {
+		err := repository.UserCommandRepositoryInterface.DeleteUserTOTP(walletAddress)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// InsertEmailVerificationToken decorator pattern to insert email verification token
+func (repository *UserCommandRepositoryCircuitBreaker) InsertEmailVerificationToken(data repositoryTypes.CreateEmailVerificationToken) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("insert_email_verification_token", config.Settings())
+	errors := hystrix.Go("insert_email_verification_token", func() error {
+		err := repository.UserCommandRepositoryInterface.InsertEmailVerificationToken(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// InsertPasswordResetToken decorator pattern to insert password reset token
+func (repository *UserCommandRepositoryCircuitBreaker) InsertPasswordResetToken(data repositoryTypes.CreatePasswordResetToken) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("insert_password_reset_token", config.Settings())
+	errors := hystrix.Go("insert_password_reset_token", func() error {
+		err := repository.UserCommandRepositoryInterface.InsertPasswordResetToken(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
 // InsertUser decorator pattern to insert user
 func (repository *UserCommandRepositoryCircuitBreaker) InsertUser(data repositoryTypes.CreateUser) error {
 	output := make(chan error, 1)
@@ -69,6 +177,87 @@ func (repository *UserCommandRepositoryCircuitBreaker) InsertUser(data repositor
 	}
 }
 
+// InsertUserTOTP decorator pattern to insert user TOTP
+func (repository *UserCommandRepositoryCircuitBreaker) InsertUserTOTP(data repositoryTypes.CreateUserTOTP) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("insert_user_totp", config.Settings())
+	errors := hystrix.Go("insert_user_totp", func() error {
+		err := repository.UserCommandRepositoryInterface.InsertUserTOTP(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// ReplaceUserRecoveryCodes decorator pattern to replace user recovery codes
+func (repository *UserCommandRepositoryCircuitBreaker) ReplaceUserRecoveryCodes(data repositoryTypes.ReplaceUserRecoveryCodes) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("replace_user_recovery_codes", config.Settings())
+	errors := hystrix.Go("replace_user_recovery_codes", func() error {
+		err := repository.UserCommandRepositoryInterface.ReplaceUserRecoveryCodes(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// ResetUserPassword decorator pattern to reset user password
+func (repository *UserCommandRepositoryCircuitBreaker) ResetUserPassword(data repositoryTypes.ResetUserPassword) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("reset_user_password", config.Settings())
+	errors := hystrix.Go("reset_user_password", func() error {
+		err := repository.UserCommandRepositoryInterface.ResetUserPassword(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
 // UpdateUser decorator pattern to update user
 func (repository *UserCommandRepositoryCircuitBreaker) UpdateUser(data repositoryTypes.UpdateUser) error {
 	output := make(chan error, 1)
@@ -97,13 +286,13 @@ func (repository *UserCommandRepositoryCircuitBreaker) UpdateUser(data repositor
 }
 
 // UpdateUserEmailVerifiedAt decorator pattern to update user email verified at
-func (repository *UserCommandRepositoryCircuitBreaker) UpdateUserEmailVerifiedAt(email string) error {
+func (repository *UserCommandRepositoryCircuitBreaker) UpdateUserEmailVerifiedAt(data repositoryTypes.UpdateUserEmailVerifiedAt) error {
 	output := make(chan error, 1)
 	errChan := make(chan error, 1)
 
 	hystrix.ConfigureCommand("update_user_email_verified_at", config.Settings())
 	errors := hystrix.Go("update_user_email_verified_at", func() error {
-		err := repository.UserCommandRepositoryInterface.UpdateUserEmailVerifiedAt(email)
+		err := repository.UserCommandRepositoryInterface.UpdateUserEmailVerifiedAt(data)
 		if err != nil {
 			errChan <- err
 			return nil
@@ -149,3 +338,84 @@ func (repository *UserCommandRepositoryCircuitBreaker) UpdateUserPassword(data r
 		return err
 	}
 }
+
+// UpdateUserShare decorator pattern to update user share
+func (repository *UserCommandRepositoryCircuitBreaker) UpdateUserShare(data repositoryTypes.UpdateUserShare) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("update_user_share", config.Settings())
+	errors := hystrix.Go("update_user_share", func() error {
+		err := repository.UserCommandRepositoryInterface.UpdateUserShare(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// UpdateUserTOTPLastUsedStep decorator pattern to update user TOTP last used step
+func (repository *UserCommandRepositoryCircuitBreaker) UpdateUserTOTPLastUsedStep(data repositoryTypes.UpdateUserTOTPLastUsedStep) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("update_user_totp_last_used_step", config.Settings())
+	errors := hystrix.Go("update_user_totp_last_used_step", func() error {
+		err := repository.UserCommandRepositoryInterface.UpdateUserTOTPLastUsedStep(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
+
+// UseUserRecoveryCode decorator pattern to use user recovery code
+func (repository *UserCommandRepositoryCircuitBreaker) UseUserRecoveryCode(data repositoryTypes.UseUserRecoveryCode) error {
+	output := make(chan error, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("use_user_recovery_code", config.Settings())
+	errors := hystrix.Go("use_user_recovery_code", func() error {
+		err := repository.UserCommandRepositoryInterface.UseUserRecoveryCode(data)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- nil
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out
+	case err := <-errChan:
+		return err
+	case err := <-errors:
+		return err
+	}
+}
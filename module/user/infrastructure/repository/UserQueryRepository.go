@@ -17,6 +17,66 @@ type UserQueryRepository struct {
 	types.MySQLDBHandlerInterface
 }
 
+// SelectEmailVerificationTokenByHash select an email verification token by its hash
+func (repository *UserQueryRepository) SelectEmailVerificationTokenByHash(tokenHash string) (entity.EmailVerificationToken, error) {
+	var verificationToken entity.EmailVerificationToken
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE token_hash=:token_hash", verificationToken.GetModelName())
+	err := repository.QueryRow(stmt, map[string]interface{}{
+		"token_hash": tokenHash,
+	}, &verificationToken)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return verificationToken, errors.New(apiError.MissingRecord)
+		}
+
+		log.Println(err)
+		return verificationToken, errors.New(apiError.DatabaseError)
+	}
+
+	return verificationToken, nil
+}
+
+// SelectPasswordResetTokenByHash select a password reset token by its hash
+func (repository *UserQueryRepository) SelectPasswordResetTokenByHash(tokenHash string) (entity.PasswordResetToken, error) {
+	var resetToken entity.PasswordResetToken
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE token_hash=:token_hash", resetToken.GetModelName())
+	err := repository.QueryRow(stmt, map[string]interface{}{
+		"token_hash": tokenHash,
+	}, &resetToken)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return resetToken, errors.New(apiError.MissingRecord)
+		}
+
+		log.Println(err)
+		return resetToken, errors.New(apiError.DatabaseError)
+	}
+
+	return resetToken, nil
+}
+
+// SelectUserTOTPByWalletAddress select the TOTP secret of a user
+func (repository *UserQueryRepository) SelectUserTOTPByWalletAddress(walletAddress string) (entity.UserTOTP, error) {
+	var userTOTP entity.UserTOTP
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE wallet_address=:wallet_address", userTOTP.GetModelName())
+	err := repository.QueryRow(stmt, map[string]interface{}{
+		"wallet_address": walletAddress,
+	}, &userTOTP)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return userTOTP, errors.New(apiError.MissingRecord)
+		}
+
+		log.Println(err)
+		return userTOTP, errors.New(apiError.DatabaseError)
+	}
+
+	return userTOTP, nil
+}
+
 // SelectUsers select all users
 func (repository *UserQueryRepository) SelectUsers(page uint, search *string) ([]entity.User, uint, error) {
 	var user entity.User
@@ -104,3 +164,39 @@ func (repository *UserQueryRepository) SelectUserByEmail(email string) (entity.U
 
 	return user, nil
 }
+
+// SelectUsersPendingShareReencryption select the users whose server share is not under the key id
+func (repository *UserQueryRepository) SelectUsersPendingShareReencryption(keyID string) ([]entity.User, error) {
+	var user entity.User
+	var users []entity.User
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE sss_1 != '' AND (sss_1_key_id IS NULL OR sss_1_key_id != :key_id)", user.GetModelName())
+	err := repository.Query(stmt, map[string]interface{}{
+		"key_id": keyID,
+	}, &users)
+	if err != nil {
+		log.Println(err)
+		return []entity.User{}, errors.New(apiError.DatabaseError)
+	}
+
+	return users, nil
+}
+
+// SelectUserSharesByWalletAddress select the share holders of a user
+func (repository *UserQueryRepository) SelectUserSharesByWalletAddress(walletAddress string) ([]entity.UserShare, error) {
+	var userShare entity.UserShare
+	var userShares []entity.UserShare
+
+	stmt := fmt.Sprintf("SELECT * FROM %s WHERE wallet_address=:wallet_address ORDER BY share_index ASC", userShare.GetModelName())
+	err := repository.Query(stmt, map[string]interface{}{
+		"wallet_address": walletAddress,
+	}, &userShares)
+	if err != nil {
+		log.Println(err)
+		return []entity.UserShare{}, errors.New(apiError.DatabaseError)
+	} else if len(userShares) == 0 {
+		return []entity.UserShare{}, errors.New(apiError.MissingRecord)
+	}
+
+	return userShares, nil
+}
@@ -12,6 +12,60 @@ type UserQueryRepositoryCircuitBreaker struct {
 	repository.UserQueryRepositoryInterface
 }
 
+// SelectEmailVerificationTokenByHash decorator pattern for select email verification token repository
+func (repository *UserQueryRepositoryCircuitBreaker) SelectEmailVerificationTokenByHash(tokenHash string) (entity.EmailVerificationToken, error) {
+	output := make(chan entity.EmailVerificationToken, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_email_verification_token_by_hash", config.Settings())
+	errors := hystrix.Go("select_email_verification_token_by_hash", func() error {
+		verificationToken, err := repository.UserQueryRepositoryInterface.SelectEmailVerificationTokenByHash(tokenHash)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- verificationToken
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return entity.EmailVerificationToken{}, err
+	case err := <-errors:
+		return entity.EmailVerificationToken{}, err
+	}
+}
+
+// SelectPasswordResetTokenByHash decorator pattern for select password reset token repository
+func (repository *UserQueryRepositoryCircuitBreaker) SelectPasswordResetTokenByHash(tokenHash string) (entity.PasswordResetToken, error) {
+	output := make(chan entity.PasswordResetToken, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_password_reset_token_by_hash", config.Settings())
+	errors := hystrix.Go("select_password_reset_token_by_hash", func() error {
+		resetToken, err := repository.UserQueryRepositoryInterface.SelectPasswordResetTokenByHash(tokenHash)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- resetToken
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return entity.PasswordResetToken{}, err
+	case err := <-errors:
+		return entity.PasswordResetToken{}, err
+	}
+}
+
 // SelectUsers is a decorator for the select users repository
 func (repository *UserQueryRepositoryCircuitBreaker) SelectUsers(page uint, search *string) ([]entity.User, uint, error) {
 	type outputData struct {
@@ -100,3 +154,57 @@ func (repository *UserQueryRepositoryCircuitBreaker) SelectUserByEmail(email str
 		return entity.User{}, err
 	}
 }
+
+// SelectUserTOTPByWalletAddress decorator pattern for select user TOTP repository
+func (repository *UserQueryRepositoryCircuitBreaker) SelectUserTOTPByWalletAddress(walletAddress string) (entity.UserTOTP, error) {
+	output := make(chan entity.UserTOTP, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_user_totp_by_wallet_address", config.Settings())
+	errors := hystrix.Go("select_user_totp_by_wallet_address", func() error {
+		userTOTP, err := repository.UserQueryRepositoryInterface.SelectUserTOTPByWalletAddress(walletAddress)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- userTOTP
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return entity.UserTOTP{}, err
+	case err := <-errors:
+		return entity.UserTOTP{}, err
+	}
+}
+
+// SelectUserSharesByWalletAddress decorator pattern for select user shares repository
+func (repository *UserQueryRepositoryCircuitBreaker) SelectUserSharesByWalletAddress(walletAddress string) ([]entity.UserShare, error) {
+	output := make(chan []entity.UserShare, 1)
+	errChan := make(chan error, 1)
+
+	hystrix.ConfigureCommand("select_user_shares_by_wallet_address", config.Settings())
+	errors := hystrix.Go("select_user_shares_by_wallet_address", func() error {
+		userShares, err := repository.UserQueryRepositoryInterface.SelectUserSharesByWalletAddress(walletAddress)
+		if err != nil {
+			errChan <- err
+			return nil
+		}
+
+		output <- userShares
+		return nil
+	}, nil)
+
+	select {
+	case out := <-output:
+		return out, nil
+	case err := <-errChan:
+		return []entity.UserShare{}, err
+	case err := <-errors:
+		return []entity.UserShare{}, err
+	}
+}
@@ -1,12 +1,53 @@
 package types
 
+import (
+	"time"
+)
+
 type CreateUser struct {
+	WalletAddress  string
+	Email          string
+	Password       string
+	SSS1           string
+	SSS1DEK        *string
+	SSS1KeyID      *string
+	ShareThreshold uint8
+	ShareCount     uint8
+	Shares         []CreateUserShare
+	Name           string
+	SSS3           string
+}
+
+type CreateUserShare struct {
+	ShareIndex uint8
+	HolderType string
+	Metadata   *string
+}
+
+type CreateEmailVerificationToken struct {
+	ID            string
 	WalletAddress string
-	Email         string
-	Password      string
-	SSS1          string
-	Name          string
-	SSS3          string
+	TokenHash     string
+	ExpiresAt     time.Time
+}
+
+type CreatePasswordResetToken struct {
+	ID            string
+	WalletAddress string
+	TokenHash     string
+	ExpiresAt     time.Time
+}
+
+type CreateUserRecoveryCode struct {
+	ID       string
+	CodeHash string
+}
+
+type CreateUserTOTP struct {
+	WalletAddress string
+	Secret        string
+	SecretDEK     string
+	SecretKeyID   string
 }
 
 type DeactivateUser struct {
@@ -17,12 +58,52 @@ type DeactivateUser struct {
 	Name          string
 }
 
+type ResetUserPassword struct {
+	WalletAddress string
+	Password      string
+	TokenID       string // consumed reset token
+}
+
+type ConfirmUserTOTP struct {
+	WalletAddress string
+	Step          int64 // step of the code that confirmed the secret
+	RecoveryCodes []CreateUserRecoveryCode
+}
+
+type ReplaceUserRecoveryCodes struct {
+	WalletAddress string
+	RecoveryCodes []CreateUserRecoveryCode
+}
+
 type UpdateUser struct {
 	WalletAddress string
 	Name          string
 }
 
+type UpdateUserEmailVerifiedAt struct {
+	WalletAddress string
+	TokenID       string // consumed verification token
+}
+
+type UpdateUserShare struct {
+	WalletAddress string
+	SSS1          string
+	SSS1DEK       *string
+	SSS1KeyID     *string
+	PreviousSSS1  string // server share the update was computed from
+}
+
 type UpdateUserPassword struct {
 	WalletAddress string
 	Password      string
 }
+
+type UpdateUserTOTPLastUsedStep struct {
+	WalletAddress string
+	Step          int64
+}
+
+type UseUserRecoveryCode struct {
+	WalletAddress string
+	CodeHash      string
+}
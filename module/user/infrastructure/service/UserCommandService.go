@@ -3,16 +3,41 @@ package service
 import (
 	"context"
 	"crypto/ecdsa"
-	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math/big"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/hashicorp/vault/shamir"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/segmentio/ksuid"
 
+	emailConfig "celeste/configs/email"
+	ratelimitConfig "celeste/configs/ratelimit"
+	"celeste/configs/signing"
+	totpConfig "celeste/configs/totp"
+	walletConfig "celeste/configs/wallet"
+	"celeste/infrastructures/kms"
+	kmsTypes "celeste/infrastructures/kms/types"
+	"celeste/infrastructures/mailer"
+	mailerTypes "celeste/infrastructures/mailer/types"
+	"celeste/infrastructures/ratelimit"
+	ratelimitTypes "celeste/infrastructures/ratelimit/types"
+	apiError "celeste/internal/errors"
 	"celeste/internal/password"
+	"celeste/internal/token"
+	"celeste/internal/totp"
+	"celeste/internal/wallet"
+	"celeste/module/user/application"
+	"celeste/module/user/domain/entity"
 	"celeste/module/user/domain/repository"
 	repositoryTypes "celeste/module/user/infrastructure/repository/types"
 	"celeste/module/user/infrastructure/service/types"
@@ -21,71 +46,130 @@ import (
 // UserCommandService handles the user command service logic
 type UserCommandService struct {
 	repository.UserCommandRepositoryInterface
+	repository.UserQueryRepositoryInterface
+	kmsTypes.KMSProviderInterface
+	mailerTypes.MailerInterface
+	application.UserAuthServiceInterface
+	ratelimitTypes.AttemptStoreInterface
+	*password.Policy
 }
 
-// CreateUser create a user
-func (service *UserCommandService) CreateUser(ctx context.Context, data types.CreateUser) (types.CreateUserResult, error) {
-	// generate wallet
-	privateKey, err := crypto.GenerateKey()
+var (
+	limitConfig     = ratelimitConfig.Config{}
+	mailConfig      = emailConfig.Config{}
+	signingConfig   = signing.Config{}
+	sssConfig       = walletConfig.Config{}
+	twoFactorConfig = totpConfig.Config{}
+)
+
+// recoveryCodeCount is the number of recovery codes issued when two-factor authentication is enabled
+const recoveryCodeCount int = 10
+
+// ConfirmUserTOTP enables two-factor authentication with a code of the enrolled secret and returns the recovery codes
+func (service *UserCommandService) ConfirmUserTOTP(ctx context.Context, data types.ConfirmUserTOTP) (types.UserRecoveryCodesResult, error) {
+	userTOTP, err := service.UserQueryRepositoryInterface.SelectUserTOTPByWalletAddress(data.WalletAddress)
 	if err != nil {
-		log.Println(err)
-		return types.CreateUserResult{}, err
+		if err.Error() == apiError.MissingRecord {
+			return types.UserRecoveryCodesResult{}, errors.New(apiError.TOTPNotEnabled)
+		}
+
+		return types.UserRecoveryCodesResult{}, err
 	}
 
-	privateKeyBytes := crypto.FromECDSA(privateKey)
-	privateKeyEncoded := hexutil.Encode(privateKeyBytes)[2:] // strip 0x
+	if userTOTP.ConfirmedAt != nil {
+		return types.UserRecoveryCodesResult{}, errors.New(apiError.TOTPAlreadyEnabled)
+	}
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA, _ := publicKey.(*ecdsa.PublicKey)
-	publicAddress := crypto.PubkeyToAddress(*publicKeyECDSA).Hex()
+	secret, err := service.openTOTPSecret(userTOTP)
+	if err != nil {
+		return types.UserRecoveryCodesResult{}, err
+	}
 
-	// apply Shamir Secret Sharing (SSS)
-	bytesShares, err := shamir.Split([]byte(privateKeyEncoded), 3, 2) // 2 of 3
+	step, ok := totp.Validate(secret, data.Code, time.Now())
+	if !ok {
+		return types.UserRecoveryCodesResult{}, errors.New(apiError.InvalidTOTPCode)
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := newRecoveryCodes()
 	if err != nil {
-		log.Println(err)
-		return types.CreateUserResult{}, err
+		return types.UserRecoveryCodesResult{}, err
 	}
 
-	var sss []string
-	for _, byteShare := range bytesShares {
-		sss = append(sss, base64.StdEncoding.EncodeToString(byteShare))
+	err = service.UserCommandRepositoryInterface.ConfirmUserTOTP(repositoryTypes.ConfirmUserTOTP{
+		WalletAddress: data.WalletAddress,
+		Step:          step,
+		RecoveryCodes: recoveryCodeHashes,
+	})
+	if err != nil {
+		return types.UserRecoveryCodesResult{}, err
 	}
 
-	fmt.Println(privateKeyEncoded)
-	fmt.Println("#1: ", sss[0])
-	fmt.Println("#2: ", sss[1])
-	fmt.Println("#3: ", sss[2])
+	return types.UserRecoveryCodesResult{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
 
-	// TODO: remove test combine
-	byteShare1, err := base64.StdEncoding.DecodeString(sss[0])
+// CreateUser create a user
+func (service *UserCommandService) CreateUser(ctx context.Context, data types.CreateUser) (types.CreateUserResult, error) {
+	err := service.Policy.Validate(data.Password, data.Email, data.Name)
 	if err != nil {
 		return types.CreateUserResult{}, err
 	}
-	//byteShare2, err := base64.StdEncoding.DecodeString(sss[1])
-	// if err != nil {
-	// 	return "", err
-	// }
-	byteShare3, err := base64.StdEncoding.DecodeString(sss[2])
+
+	// generate wallet
+	privateKey, err := crypto.GenerateKey()
 	if err != nil {
+		log.Println(err)
 		return types.CreateUserResult{}, err
 	}
+	defer wallet.ZeroKey(privateKey)
+
+	publicAddress := wallet.Address(privateKey)
 
-	shares := [][]byte{
-		byteShare1,
-		byteShare3,
+	threshold, holders, err := shareScheme(data.Threshold, data.Shares)
+	if err != nil {
+		return types.CreateUserResult{}, err
 	}
 
-	recovered, err := shamir.Combine(shares)
+	// apply Shamir Secret Sharing (SSS)
+	sss, err := wallet.Split(privateKey, len(holders), threshold)
 	if err != nil {
+		log.Println(err)
 		return types.CreateUserResult{}, err
 	}
 
-	fmt.Println(string(recovered), string(recovered) == privateKeyEncoded)
-	// TODO: remove above test code
+	var userShares []repositoryTypes.CreateUserShare
+	var clientShares []types.UserShare
+	for i, holder := range holders {
+		shareIndex := uint8(i + 1)
 
-	sss1 := sss[0] // for user database record
-	sss2 := sss[1] // for device
-	sss3 := sss[2] // for backup
+		var metadata *string
+		if len(holder.Metadata) > 0 {
+			metadataJSON, err := json.Marshal(holder.Metadata)
+			if err != nil {
+				return types.CreateUserResult{}, err
+			}
+
+			metadataStr := string(metadataJSON)
+			metadata = &metadataStr
+		}
+
+		userShares = append(userShares, repositoryTypes.CreateUserShare{
+			ShareIndex: shareIndex,
+			HolderType: holder.HolderType,
+			Metadata:   metadata,
+		})
+
+		if holder.HolderType == entity.HolderTypeServer {
+			continue
+		}
+
+		clientShares = append(clientShares, types.UserShare{
+			ShareIndex: shareIndex,
+			HolderType: holder.HolderType,
+			Share:      sss[i],
+		})
+	}
 
 	// hash password
 	hashedPassword, err := password.HashPassword(data.Password)
@@ -93,21 +177,39 @@ func (service *UserCommandService) CreateUser(ctx context.Context, data types.Cr
 		return types.CreateUserResult{}, err
 	}
 
+	// server share is always first
+	envelope, err := kms.Seal(service.KMSProviderInterface, []byte(sss[0]))
+	if err != nil {
+		log.Println(err)
+		return types.CreateUserResult{}, errors.New(apiError.ServerError)
+	}
+
 	err = service.UserCommandRepositoryInterface.InsertUser(repositoryTypes.CreateUser{
-		WalletAddress: publicAddress,
-		Email:         data.Email,
-		Password:      hashedPassword,
-		SSS1:          sss1,
-		Name:          data.Name,
+		WalletAddress:  publicAddress,
+		Email:          data.Email,
+		Password:       hashedPassword,
+		SSS1:           envelope.Ciphertext,
+		SSS1DEK:        &envelope.WrappedKey,
+		SSS1KeyID:      &envelope.KeyID,
+		ShareThreshold: uint8(threshold),
+		ShareCount:     uint8(len(holders)),
+		Shares:         userShares,
+		Name:           data.Name,
 	})
 	if err != nil {
 		return types.CreateUserResult{}, err
 	}
 
+	// the user can request another verification email if this one fails
+	err = service.sendEmailVerification(publicAddress, data.Email, data.Name)
+	if err != nil {
+		log.Println(err)
+	}
+
 	return types.CreateUserResult{
 		WalletAddress: publicAddress,
-		SSS2:          sss2,
-		SSS3:          sss3,
+		Threshold:     uint8(threshold),
+		Shares:        clientShares,
 	}, nil
 }
 
@@ -124,9 +226,535 @@ func (service *UserCommandService) DeactivateUser(ctx context.Context, walletAdd
 		return err
 	}
 
+	// remove passkeys so the account can no longer sign in
+	err = service.UserAuthServiceInterface.DeleteUserWebAuthnCredentials(ctx, walletAddress)
+	if err != nil {
+		return err
+	}
+
+	// sign out every device
+	return service.UserAuthServiceInterface.RevokeUserSessions(ctx, walletAddress)
+}
+
+// DisableUserTOTP disables two-factor authentication after verifying the password and a TOTP or recovery code
+func (service *UserCommandService) DisableUserTOTP(ctx context.Context, data types.DisableUserTOTP) error {
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(data.WalletAddress)
+	if err != nil {
+		return err
+	}
+
+	if !password.CheckPasswordHash(data.Password, user.Password) {
+		return errors.New(apiError.InvalidPassword)
+	}
+
+	userTOTP, err := service.enabledUserTOTP(data.WalletAddress)
+	if err != nil {
+		return err
+	}
+
+	err = service.verifyTOTPCode(userTOTP, data.Code)
+	if err != nil {
+		return err
+	}
+
+	return service.UserCommandRepositoryInterface.DeleteUserTOTP(data.WalletAddress)
+}
+
+// EnrollUserTOTP generates a pending TOTP secret, two-factor authentication is enabled once a code of it is confirmed
+func (service *UserCommandService) EnrollUserTOTP(ctx context.Context, walletAddress string) (types.EnrollUserTOTPResult, error) {
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(walletAddress)
+	if err != nil {
+		return types.EnrollUserTOTPResult{}, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		log.Println(err)
+		return types.EnrollUserTOTPResult{}, errors.New(apiError.ServerError)
+	}
+
+	envelope, err := kms.Seal(service.KMSProviderInterface, []byte(secret))
+	if err != nil {
+		log.Println(err)
+		return types.EnrollUserTOTPResult{}, errors.New(apiError.ServerError)
+	}
+
+	err = service.UserCommandRepositoryInterface.InsertUserTOTP(repositoryTypes.CreateUserTOTP{
+		WalletAddress: user.WalletAddress,
+		Secret:        envelope.Ciphertext,
+		SecretDEK:     envelope.WrappedKey,
+		SecretKeyID:   envelope.KeyID,
+	})
+	if err != nil {
+		return types.EnrollUserTOTPResult{}, err
+	}
+
+	return types.EnrollUserTOTPResult{
+		Secret: secret,
+		URI:    totp.URI(twoFactorConfig.Issuer(), user.Email, secret),
+	}, nil
+}
+
+// RecoverUserWallet reconstructs the user wallet from the server share and the client shares
+func (service *UserCommandService) RecoverUserWallet(ctx context.Context, data types.RecoverUserWallet) (types.RecoverUserWalletResult, error) {
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(data.WalletAddress)
+	if err != nil {
+		return types.RecoverUserWalletResult{}, err
+	}
+
+	// only the account owner may export the raw private key
+	if data.RevealPrivateKey && !password.CheckPasswordHash(data.Password, user.Password) {
+		return types.RecoverUserWalletResult{}, errors.New(apiError.InvalidPassword)
+	}
+
+	err = service.VerifyUserTOTP(ctx, user.WalletAddress, data.TOTPCode)
+	if err != nil {
+		return types.RecoverUserWalletResult{}, err
+	}
+
+	privateKey, err := service.reconstructPrivateKey(user, data.Shares)
+	if err != nil {
+		return types.RecoverUserWalletResult{}, err
+	}
+	defer wallet.ZeroKey(privateKey)
+
+	result := types.RecoverUserWalletResult{
+		WalletAddress: user.WalletAddress,
+	}
+
+	if data.RevealPrivateKey {
+		privateKeyHex := hexutil.Encode(crypto.FromECDSA(privateKey))
+		result.PrivateKey = &privateKeyHex
+	}
+
+	return result, nil
+}
+
+// ReencryptUserShares encrypts the server shares that are not yet under the active KMS key
+// Shares under a previous key only have their data encryption key re-wrapped, legacy plaintext shares are encrypted
+func (service *UserCommandService) ReencryptUserShares(ctx context.Context) (uint, error) {
+	keyID, err := service.KMSProviderInterface.ActiveKeyID()
+	if err != nil {
+		log.Println(err)
+		return 0, errors.New(apiError.ServerError)
+	}
+
+	users, err := service.UserQueryRepositoryInterface.SelectUsersPendingShareReencryption(keyID)
+	if err != nil {
+		return 0, err
+	}
+
+	var total uint
+	for _, user := range users {
+		var envelope kmsTypes.Envelope
+		if user.SSS1KeyID == nil || user.SSS1DEK == nil {
+			envelope, err = kms.Seal(service.KMSProviderInterface, []byte(user.SSS1))
+		} else {
+			envelope, err = kms.Rewrap(service.KMSProviderInterface, kmsTypes.Envelope{
+				Ciphertext: user.SSS1,
+				WrappedKey: *user.SSS1DEK,
+				KeyID:      *user.SSS1KeyID,
+			})
+		}
+		if err != nil {
+			log.Println(user.WalletAddress, err)
+			return total, errors.New(apiError.ServerError)
+		}
+
+		err = service.UserCommandRepositoryInterface.UpdateUserShare(repositoryTypes.UpdateUserShare{
+			WalletAddress: user.WalletAddress,
+			SSS1:          envelope.Ciphertext,
+			SSS1DEK:       &envelope.WrappedKey,
+			SSS1KeyID:     &envelope.KeyID,
+			PreviousSSS1:  user.SSS1,
+		})
+		if err != nil {
+			if err.Error() == apiError.ShareRotated {
+				continue // rotated meanwhile, the new share is already under the active key
+			}
+
+			return total, err
+		}
+
+		total++
+	}
+
+	return total, nil
+}
+
+// RegenerateUserRecoveryCodes replaces the recovery codes after verifying a TOTP or recovery code
+func (service *UserCommandService) RegenerateUserRecoveryCodes(ctx context.Context, data types.RegenerateUserRecoveryCodes) (types.UserRecoveryCodesResult, error) {
+	userTOTP, err := service.enabledUserTOTP(data.WalletAddress)
+	if err != nil {
+		return types.UserRecoveryCodesResult{}, err
+	}
+
+	err = service.verifyTOTPCode(userTOTP, data.Code)
+	if err != nil {
+		return types.UserRecoveryCodesResult{}, err
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := newRecoveryCodes()
+	if err != nil {
+		return types.UserRecoveryCodesResult{}, err
+	}
+
+	err = service.UserCommandRepositoryInterface.ReplaceUserRecoveryCodes(repositoryTypes.ReplaceUserRecoveryCodes{
+		WalletAddress: data.WalletAddress,
+		RecoveryCodes: recoveryCodeHashes,
+	})
+	if err != nil {
+		return types.UserRecoveryCodesResult{}, err
+	}
+
+	return types.UserRecoveryCodesResult{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// ResendEmailVerification sends a new email verification token to an unverified user
+// Unknown and already verified emails succeed silently so registered emails cannot be enumerated
+func (service *UserCommandService) ResendEmailVerification(ctx context.Context, email string) error {
+	user, err := service.UserQueryRepositoryInterface.SelectUserByEmail(email)
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return nil
+		}
+
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil || len(user.Password) == 0 {
+		return nil
+	}
+
+	err = service.sendEmailVerification(user.WalletAddress, user.Email, user.Name)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.ServerError)
+	}
+
 	return nil
 }
 
+// ResetUserPassword sets a new password with the emailed reset token and signs the user out everywhere
+func (service *UserCommandService) ResetUserPassword(ctx context.Context, data types.ResetUserPassword) error {
+	storedToken, err := service.UserQueryRepositoryInterface.SelectPasswordResetTokenByHash(token.HashOpaqueToken(data.Token))
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return errors.New(apiError.InvalidToken)
+		}
+
+		return err
+	}
+
+	if storedToken.UsedAt != nil || time.Now().After(storedToken.ExpiresAt) {
+		return errors.New(apiError.InvalidToken)
+	}
+
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(storedToken.WalletAddress)
+	if err != nil {
+		return err
+	}
+
+	err = service.Policy.Validate(data.Password, user.Email, user.Name)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := password.HashPassword(data.Password)
+	if err != nil {
+		return err
+	}
+
+	err = service.UserCommandRepositoryInterface.ResetUserPassword(repositoryTypes.ResetUserPassword{
+		WalletAddress: storedToken.WalletAddress,
+		Password:      hashedPassword,
+		TokenID:       storedToken.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// revoke existing sessions
+	return service.UserAuthServiceInterface.RevokeUserSessions(ctx, storedToken.WalletAddress)
+}
+
+// RotateUserShareKey creates a new KMS key version, existing server shares are moved to it by ReencryptUserShares
+func (service *UserCommandService) RotateUserShareKey(ctx context.Context) error {
+	err := service.KMSProviderInterface.RotateKey()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.ServerError)
+	}
+
+	return nil
+}
+
+// RotateUserShares recombines the key from the server and client shares and re-splits it with fresh randomness
+// The wallet address, threshold and share holders stay the same while the previous shares can no longer be combined with the new ones
+func (service *UserCommandService) RotateUserShares(ctx context.Context, data types.RotateUserShares) (types.RotateUserSharesResult, error) {
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(data.WalletAddress)
+	if err != nil {
+		return types.RotateUserSharesResult{}, err
+	}
+
+	err = service.VerifyUserTOTP(ctx, user.WalletAddress, data.TOTPCode)
+	if err != nil {
+		return types.RotateUserSharesResult{}, err
+	}
+
+	serverShare, err := service.openUserShare(user)
+	if err != nil {
+		return types.RotateUserSharesResult{}, err
+	}
+
+	// the server share is combined with the client held shares like when signing, so every scheme can be rotated
+	if len(data.Shares) < int(user.ShareThreshold)-1 || slices.Contains(data.Shares, serverShare) {
+		return types.RotateUserSharesResult{}, errors.New(apiError.InvalidShare)
+	}
+
+	userShares, err := service.UserQueryRepositoryInterface.SelectUserSharesByWalletAddress(user.WalletAddress)
+	if err != nil {
+		return types.RotateUserSharesResult{}, err
+	}
+
+	privateKey, err := wallet.CombineForAddress(append([]string{serverShare}, data.Shares...), user.WalletAddress)
+	if err != nil {
+		return types.RotateUserSharesResult{}, err
+	}
+	defer wallet.ZeroKey(privateKey)
+
+	sss, err := wallet.Split(privateKey, int(user.ShareCount), int(user.ShareThreshold))
+	if err != nil {
+		log.Println(err)
+		return types.RotateUserSharesResult{}, err
+	}
+
+	var clientShares []types.UserShare
+	for _, userShare := range userShares {
+		if userShare.HolderType == entity.HolderTypeServer {
+			continue
+		}
+
+		if userShare.ShareIndex < 1 || int(userShare.ShareIndex) > len(sss) {
+			return types.RotateUserSharesResult{}, errors.New(apiError.ServerError)
+		}
+
+		clientShares = append(clientShares, types.UserShare{
+			ShareIndex: userShare.ShareIndex,
+			HolderType: userShare.HolderType,
+			Share:      sss[userShare.ShareIndex-1],
+		})
+	}
+
+	envelope, err := kms.Seal(service.KMSProviderInterface, []byte(sss[0]))
+	if err != nil {
+		log.Println(err)
+		return types.RotateUserSharesResult{}, errors.New(apiError.ServerError)
+	}
+
+	// fails when a concurrent rotation replaced the server share first, the shares it returned stay valid
+	err = service.UserCommandRepositoryInterface.UpdateUserShare(repositoryTypes.UpdateUserShare{
+		WalletAddress: user.WalletAddress,
+		SSS1:          envelope.Ciphertext,
+		SSS1DEK:       &envelope.WrappedKey,
+		SSS1KeyID:     &envelope.KeyID,
+		PreviousSSS1:  user.SSS1,
+	})
+	if err != nil {
+		return types.RotateUserSharesResult{}, err
+	}
+
+	return types.RotateUserSharesResult{
+		WalletAddress: user.WalletAddress,
+		Threshold:     user.ShareThreshold,
+		Shares:        clientShares,
+	}, nil
+}
+
+// SendPasswordReset emails a password reset token to the user
+// Unknown and deactivated users succeed silently so registered emails cannot be enumerated
+func (service *UserCommandService) SendPasswordReset(ctx context.Context, email string) error {
+	user, err := service.UserQueryRepositoryInterface.SelectUserByEmail(email)
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return nil
+		}
+
+		return err
+	}
+
+	if len(user.Password) == 0 {
+		return nil
+	}
+
+	resetToken, tokenHash, err := token.GenerateOpaqueToken()
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.ServerError)
+	}
+
+	err = service.UserCommandRepositoryInterface.InsertPasswordResetToken(repositoryTypes.CreatePasswordResetToken{
+		ID:            generateID(),
+		WalletAddress: user.WalletAddress,
+		TokenHash:     tokenHash,
+		ExpiresAt:     time.Now().Add(mailConfig.PasswordResetTokenTTL()),
+	})
+	if err != nil {
+		return err
+	}
+
+	message, err := mailer.NewTemplateMessage(mailer.TemplatePasswordReset, []string{user.Email}, mailerTypes.PasswordResetData{
+		Name:      user.Name,
+		Link:      tokenLink(mailConfig.PasswordResetURL(), resetToken),
+		ExpiresIn: mailConfig.PasswordResetTokenTTL().String(),
+	})
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.ServerError)
+	}
+
+	err = service.MailerInterface.Send(message)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.ServerError)
+	}
+
+	return nil
+}
+
+// SignMessage signs an EIP-191 personal message with the key reconstructed from the server share and a client share
+func (service *UserCommandService) SignMessage(ctx context.Context, data types.SignMessage) (types.SignMessageResult, error) {
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(data.WalletAddress)
+	if err != nil {
+		return types.SignMessageResult{}, err
+	}
+
+	err = service.VerifyUserTOTP(ctx, user.WalletAddress, data.TOTPCode)
+	if err != nil {
+		return types.SignMessageResult{}, err
+	}
+
+	privateKey, err := service.reconstructPrivateKey(user, data.Shares)
+	if err != nil {
+		return types.SignMessageResult{}, err
+	}
+	defer wallet.ZeroKey(privateKey)
+
+	signature, err := wallet.SignMessage(privateKey, data.Message)
+	if err != nil {
+		log.Println(err)
+		return types.SignMessageResult{}, err
+	}
+
+	signer, err := wallet.RecoverMessageSigner(data.Message, signature)
+	if err != nil {
+		log.Println(err)
+		return types.SignMessageResult{}, err
+	}
+
+	return types.SignMessageResult{
+		Signature: hexutil.Encode(signature),
+		Address:   signer,
+	}, nil
+}
+
+// SignTransaction signs an EIP-1559 transaction with the key reconstructed from the server share and a client share
+func (service *UserCommandService) SignTransaction(ctx context.Context, data types.SignTransaction) (types.SignTransactionResult, error) {
+	if data.MaxPriorityFeePerGas.Cmp(data.MaxFeePerGas) > 0 {
+		return types.SignTransactionResult{}, errors.New(apiError.InvalidPayload)
+	}
+
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(data.WalletAddress)
+	if err != nil {
+		return types.SignTransactionResult{}, err
+	}
+
+	err = service.VerifyUserTOTP(ctx, user.WalletAddress, data.TOTPCode)
+	if err != nil {
+		return types.SignTransactionResult{}, err
+	}
+
+	privateKey, err := service.reconstructPrivateKey(user, data.Shares)
+	if err != nil {
+		return types.SignTransactionResult{}, err
+	}
+	defer wallet.ZeroKey(privateKey)
+
+	value := data.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	tx, err := wallet.SignDynamicFeeTx(privateKey, &ethTypes.DynamicFeeTx{
+		ChainID:   data.ChainID,
+		Nonce:     data.Nonce,
+		GasTipCap: data.MaxPriorityFeePerGas,
+		GasFeeCap: data.MaxFeePerGas,
+		Gas:       data.Gas,
+		To:        data.To,
+		Value:     value,
+		Data:      data.Data,
+	})
+	if err != nil {
+		log.Println(err)
+		return types.SignTransactionResult{}, errors.New(apiError.InvalidPayload)
+	}
+
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		log.Println(err)
+		return types.SignTransactionResult{}, err
+	}
+
+	return types.SignTransactionResult{
+		RawTransaction: hexutil.Encode(rawTx),
+		Hash:           tx.Hash().Hex(),
+	}, nil
+}
+
+// SignTypedData signs EIP-712 typed data with the key reconstructed from the server share and a client share
+func (service *UserCommandService) SignTypedData(ctx context.Context, data types.SignTypedData) (types.SignTypedDataResult, error) {
+	if !isAllowedDomain(data.TypedData.Domain) {
+		return types.SignTypedDataResult{}, errors.New(apiError.UnsupportedDomain)
+	}
+
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(data.WalletAddress)
+	if err != nil {
+		return types.SignTypedDataResult{}, err
+	}
+
+	err = service.VerifyUserTOTP(ctx, user.WalletAddress, data.TOTPCode)
+	if err != nil {
+		return types.SignTypedDataResult{}, err
+	}
+
+	privateKey, err := service.reconstructPrivateKey(user, data.Shares)
+	if err != nil {
+		return types.SignTypedDataResult{}, err
+	}
+	defer wallet.ZeroKey(privateKey)
+
+	signature, hash, err := wallet.SignTypedData(privateKey, data.TypedData)
+	if err != nil {
+		log.Println(err)
+		return types.SignTypedDataResult{}, errors.New(apiError.InvalidPayload)
+	}
+
+	signer, err := wallet.RecoverHashSigner(hash, signature)
+	if err != nil {
+		log.Println(err)
+		return types.SignTypedDataResult{}, err
+	}
+
+	return types.SignTypedDataResult{
+		Signature: hexutil.Encode(signature),
+		Hash:      hexutil.Encode(hash),
+		Address:   signer,
+	}, nil
+}
+
 // UpdateUser update user by address
 func (service *UserCommandService) UpdateUser(ctx context.Context, data types.UpdateUser) error {
 	err := service.UserCommandRepositoryInterface.UpdateUser(repositoryTypes.UpdateUser{
@@ -140,9 +768,25 @@ func (service *UserCommandService) UpdateUser(ctx context.Context, data types.Up
 	return nil
 }
 
-// UpdateUserEmailVerifiedAt update user email verified at by address
-func (service *UserCommandService) UpdateUserEmailVerifiedAt(ctx context.Context, email string) error {
-	err := service.UserCommandRepositoryInterface.UpdateUserEmailVerifiedAt(email)
+// UpdateUserEmailVerifiedAt verifies the user email with the emailed verification token
+func (service *UserCommandService) UpdateUserEmailVerifiedAt(ctx context.Context, verificationToken string) error {
+	storedToken, err := service.UserQueryRepositoryInterface.SelectEmailVerificationTokenByHash(token.HashOpaqueToken(verificationToken))
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return errors.New(apiError.InvalidToken)
+		}
+
+		return err
+	}
+
+	if storedToken.UsedAt != nil || time.Now().After(storedToken.ExpiresAt) {
+		return errors.New(apiError.InvalidToken)
+	}
+
+	err = service.UserCommandRepositoryInterface.UpdateUserEmailVerifiedAt(repositoryTypes.UpdateUserEmailVerifiedAt{
+		WalletAddress: storedToken.WalletAddress,
+		TokenID:       storedToken.ID,
+	})
 	if err != nil {
 		return err
 	}
@@ -150,9 +794,27 @@ func (service *UserCommandService) UpdateUserEmailVerifiedAt(ctx context.Context
 	return nil
 }
 
-// UpdateUserPassword update user password by address
+// UpdateUserPassword update user password by address after verifying the current password
 func (service *UserCommandService) UpdateUserPassword(ctx context.Context, data types.UpdateUserPassword) error {
-	hashedPassword, err := password.HashPassword(data.Password)
+	user, err := service.UserQueryRepositoryInterface.SelectUserByWalletAddress(data.WalletAddress)
+	if err != nil {
+		return err
+	}
+
+	if !password.CheckPasswordHash(data.CurrentPassword, user.Password) {
+		return errors.New(apiError.InvalidPassword)
+	}
+
+	if data.NewPassword == data.CurrentPassword {
+		return errors.New(apiError.PasswordUnchanged)
+	}
+
+	err = service.Policy.Validate(data.NewPassword, user.Email, user.Name)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := password.HashPassword(data.NewPassword)
 	if err != nil {
 		return err
 	}
@@ -168,6 +830,298 @@ func (service *UserCommandService) UpdateUserPassword(ctx context.Context, data
 	return nil
 }
 
+// VerifyUserTOTP checks the TOTP or recovery code of a user with two-factor authentication enabled, other users always pass
+func (service *UserCommandService) VerifyUserTOTP(ctx context.Context, walletAddress, code string) error {
+	userTOTP, err := service.UserQueryRepositoryInterface.SelectUserTOTPByWalletAddress(walletAddress)
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return nil
+		}
+
+		return err
+	}
+
+	if userTOTP.ConfirmedAt == nil {
+		return nil
+	}
+
+	return service.verifyTOTPCode(userTOTP, code)
+}
+
+// checkTOTPAttempts rejects the code while the user waits out a backoff or lockout of wrong codes
+func (service *UserCommandService) checkTOTPAttempts(attemptKey string) error {
+	attempt, err := service.AttemptStoreInterface.Get(attemptKey)
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.ServerError)
+	}
+
+	wait, locked := ratelimit.Delay(attempt, ratelimitTypes.Policy{
+		MaxFailures:     limitConfig.LoginMaxFailures(),
+		BackoffBase:     limitConfig.LoginBackoffBase(),
+		LockoutDuration: limitConfig.LoginLockoutDuration(),
+	}, time.Now())
+	if locked {
+		return errors.New(apiError.AccountLocked)
+	} else if wait > 0 {
+		return errors.New(apiError.TooManyRequests)
+	}
+
+	return nil
+}
+
+// enabledUserTOTP returns the confirmed TOTP secret of the user
+func (service *UserCommandService) enabledUserTOTP(walletAddress string) (entity.UserTOTP, error) {
+	userTOTP, err := service.UserQueryRepositoryInterface.SelectUserTOTPByWalletAddress(walletAddress)
+	if err != nil {
+		if err.Error() == apiError.MissingRecord {
+			return entity.UserTOTP{}, errors.New(apiError.TOTPNotEnabled)
+		}
+
+		return entity.UserTOTP{}, err
+	}
+
+	if userTOTP.ConfirmedAt == nil {
+		return entity.UserTOTP{}, errors.New(apiError.TOTPNotEnabled)
+	}
+
+	return userTOTP, nil
+}
+
+// openTOTPSecret decrypts the stored TOTP secret
+func (service *UserCommandService) openTOTPSecret(userTOTP entity.UserTOTP) (string, error) {
+	secret, err := kms.Open(service.KMSProviderInterface, kmsTypes.Envelope{
+		Ciphertext: userTOTP.Secret,
+		WrappedKey: userTOTP.SecretDEK,
+		KeyID:      userTOTP.SecretKeyID,
+	})
+	if err != nil {
+		log.Println(err)
+		return "", errors.New(apiError.ServerError)
+	}
+
+	return string(secret), nil
+}
+
+// reconstructPrivateKey combines the stored server share with the client shares and verifies the wallet address
+func (service *UserCommandService) reconstructPrivateKey(user entity.User, shares []string) (*ecdsa.PrivateKey, error) {
+	serverShare, err := service.openUserShare(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet.CombineForAddress(append([]string{serverShare}, shares...), user.WalletAddress)
+}
+
+// openUserShare decrypts the stored server share, legacy plaintext shares are returned as is
+func (service *UserCommandService) openUserShare(user entity.User) (string, error) {
+	if len(user.SSS1) == 0 {
+		return "", errors.New(apiError.MissingRecord) // deactivated user
+	}
+
+	if user.SSS1KeyID == nil || user.SSS1DEK == nil {
+		return user.SSS1, nil
+	}
+
+	share, err := kms.Open(service.KMSProviderInterface, kmsTypes.Envelope{
+		Ciphertext: user.SSS1,
+		WrappedKey: *user.SSS1DEK,
+		KeyID:      *user.SSS1KeyID,
+	})
+	if err != nil {
+		log.Println(err)
+		return "", errors.New(apiError.ServerError)
+	}
+
+	return string(share), nil
+}
+
+// recordTOTPFailure counts a wrong code of the user
+func (service *UserCommandService) recordTOTPFailure(attemptKey string) error {
+	_, err := service.AttemptStoreInterface.RecordFailure(attemptKey, limitConfig.LoginLockoutDuration())
+	if err != nil {
+		log.Println(err)
+		return errors.New(apiError.ServerError)
+	}
+
+	return errors.New(apiError.InvalidTOTPCode)
+}
+
+// sendEmailVerification stores a new hashed verification token and emails it to the user
+func (service *UserCommandService) sendEmailVerification(walletAddress, email, name string) error {
+	verificationToken, tokenHash, err := token.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = service.UserCommandRepositoryInterface.InsertEmailVerificationToken(repositoryTypes.CreateEmailVerificationToken{
+		ID:            generateID(),
+		WalletAddress: walletAddress,
+		TokenHash:     tokenHash,
+		ExpiresAt:     time.Now().Add(mailConfig.VerificationTokenTTL()),
+	})
+	if err != nil {
+		return err
+	}
+
+	message, err := mailer.NewTemplateMessage(mailer.TemplateEmailVerification, []string{email}, mailerTypes.EmailVerificationData{
+		Name:      name,
+		Link:      tokenLink(mailConfig.VerificationURL(), verificationToken),
+		ExpiresIn: mailConfig.VerificationTokenTTL().String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return service.MailerInterface.Send(message)
+}
+
+// useTOTPCode accepts a current TOTP code once or an unused recovery code
+func (service *UserCommandService) useTOTPCode(userTOTP entity.UserTOTP, code string) error {
+	if len(code) != totp.Digits {
+		return service.UserCommandRepositoryInterface.UseUserRecoveryCode(repositoryTypes.UseUserRecoveryCode{
+			WalletAddress: userTOTP.WalletAddress,
+			CodeHash:      token.HashOpaqueToken(totp.NormalizeRecoveryCode(code)),
+		})
+	}
+
+	secret, err := service.openTOTPSecret(userTOTP)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok || (userTOTP.LastUsedStep != nil && step <= *userTOTP.LastUsedStep) {
+		return errors.New(apiError.InvalidTOTPCode)
+	}
+
+	// the conditional update rejects a concurrent use of the same code
+	return service.UserCommandRepositoryInterface.UpdateUserTOTPLastUsedStep(repositoryTypes.UpdateUserTOTPLastUsedStep{
+		WalletAddress: userTOTP.WalletAddress,
+		Step:          step,
+	})
+}
+
+// verifyTOTPCode checks the TOTP or recovery code, wrong codes of the user are throttled like failed logins
+func (service *UserCommandService) verifyTOTPCode(userTOTP entity.UserTOTP, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == 0 {
+		return errors.New(apiError.TOTPRequired)
+	}
+
+	attemptKey := "totp:" + userTOTP.WalletAddress
+
+	err := service.checkTOTPAttempts(attemptKey)
+	if err != nil {
+		return err
+	}
+
+	err = service.useTOTPCode(userTOTP, code)
+	if err != nil {
+		if err.Error() == apiError.InvalidTOTPCode {
+			return service.recordTOTPFailure(attemptKey)
+		}
+
+		return err
+	}
+
+	err = service.AttemptStoreInterface.Reset(attemptKey)
+	if err != nil {
+		log.Println(err)
+	}
+
+	return nil
+}
+
+// tokenLink appends the token to the client page, the bare token is used when no page is set
+func tokenLink(pageURL, opaqueToken string) string {
+	link, err := url.Parse(pageURL)
+	if err != nil || len(link.String()) == 0 {
+		return opaqueToken
+	}
+
+	query := link.Query()
+	query.Set("token", opaqueToken)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
+
+// newRecoveryCodes generates the recovery codes shown once to the user and their hashes to store
+func newRecoveryCodes() ([]string, []repositoryTypes.CreateUserRecoveryCode, error) {
+	recoveryCodes, err := totp.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		log.Println(err)
+		return nil, nil, errors.New(apiError.ServerError)
+	}
+
+	var recoveryCodeHashes []repositoryTypes.CreateUserRecoveryCode
+	for _, recoveryCode := range recoveryCodes {
+		recoveryCodeHashes = append(recoveryCodeHashes, repositoryTypes.CreateUserRecoveryCode{
+			ID:       generateID(),
+			CodeHash: token.HashOpaqueToken(recoveryCode),
+		})
+	}
+
+	return recoveryCodes, recoveryCodeHashes, nil
+}
+
+// shareScheme resolves the threshold and share holders of a new wallet, the server share is always the first holder
+func shareScheme(threshold int, clientShares []types.CreateUserShare) (int, []types.CreateUserShare, error) {
+	if threshold == 0 {
+		threshold = sssConfig.ShareThreshold()
+	}
+
+	if len(clientShares) == 0 {
+		for i := 1; i < sssConfig.ShareCount(); i++ {
+			holderType := entity.HolderTypeGuardian
+			switch i {
+			case 1:
+				holderType = entity.HolderTypeDevice
+			case 2:
+				holderType = entity.HolderTypeBackup
+			}
+
+			clientShares = append(clientShares, types.CreateUserShare{
+				HolderType: holderType,
+			})
+		}
+	}
+
+	holders := append([]types.CreateUserShare{{HolderType: entity.HolderTypeServer}}, clientShares...)
+
+	// the server share alone must never reconstruct the wallet
+	if threshold < 2 || threshold > len(holders) || len(holders) > sssConfig.MaxShareCount() {
+		return 0, nil, errors.New(apiError.InvalidPayload)
+	}
+
+	for _, holder := range clientShares {
+		switch holder.HolderType {
+		case entity.HolderTypeDevice, entity.HolderTypeBackup, entity.HolderTypeGuardian:
+		default:
+			return 0, nil, errors.New(apiError.InvalidPayload) // only one server share is allowed
+		}
+	}
+
+	return threshold, holders, nil
+}
+
+// isAllowedDomain checks the EIP-712 domain chain id and verifying contract against the configured allowlist
+func isAllowedDomain(domain apitypes.TypedDataDomain) bool {
+	if domain.ChainId == nil || !common.IsHexAddress(domain.VerifyingContract) {
+		return false
+	}
+
+	chainID := (*big.Int)(domain.ChainId)
+	if !slices.ContainsFunc(signingConfig.AllowedChainIDs(), func(allowed *big.Int) bool {
+		return allowed.Cmp(chainID) == 0
+	}) {
+		return false
+	}
+
+	return slices.Contains(signingConfig.AllowedVerifyingContracts(), common.HexToAddress(domain.VerifyingContract))
+}
+
 // generateID generates unique id
 func generateID() string {
 	return ksuid.New().String()
@@ -0,0 +1,426 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"celeste/infrastructures/kms"
+	kmsTypes "celeste/infrastructures/kms/types"
+	"celeste/infrastructures/ratelimit"
+	apiError "celeste/internal/errors"
+	"celeste/internal/password"
+	"celeste/internal/token"
+	"celeste/internal/totp"
+	"celeste/internal/wallet"
+	"celeste/module/user/application"
+	"celeste/module/user/domain/entity"
+	"celeste/module/user/domain/repository"
+	repositoryTypes "celeste/module/user/infrastructure/repository/types"
+	"celeste/module/user/infrastructure/service/types"
+)
+
+// fakeUserQueryRepository serves a single user
+type fakeUserQueryRepository struct {
+	repository.UserQueryRepositoryInterface
+	user       entity.User
+	userTOTP   *entity.UserTOTP
+	resetToken *entity.PasswordResetToken
+}
+
+func (repository *fakeUserQueryRepository) SelectPasswordResetTokenByHash(tokenHash string) (entity.PasswordResetToken, error) {
+	if repository.resetToken == nil || tokenHash != repository.resetToken.TokenHash {
+		return entity.PasswordResetToken{}, errors.New(apiError.MissingRecord)
+	}
+
+	return *repository.resetToken, nil
+}
+
+func (repository *fakeUserQueryRepository) SelectUserByEmail(email string) (entity.User, error) {
+	if email != repository.user.Email {
+		return entity.User{}, errors.New(apiError.MissingRecord)
+	}
+
+	return repository.user, nil
+}
+
+func (repository *fakeUserQueryRepository) SelectUserByWalletAddress(walletAddress string) (entity.User, error) {
+	if walletAddress != repository.user.WalletAddress {
+		return entity.User{}, errors.New(apiError.MissingRecord)
+	}
+
+	return repository.user, nil
+}
+
+func (repository *fakeUserQueryRepository) SelectUserSharesByWalletAddress(walletAddress string) ([]entity.UserShare, error) {
+	userShares := []entity.UserShare{{WalletAddress: walletAddress, ShareIndex: 1, HolderType: entity.HolderTypeServer}}
+	for i := 2; i <= int(repository.user.ShareCount); i++ {
+		userShares = append(userShares, entity.UserShare{WalletAddress: walletAddress, ShareIndex: uint8(i), HolderType: entity.HolderTypeDevice})
+	}
+
+	return userShares, nil
+}
+
+func (repository *fakeUserQueryRepository) SelectUserTOTPByWalletAddress(walletAddress string) (entity.UserTOTP, error) {
+	if repository.userTOTP == nil || walletAddress != repository.userTOTP.WalletAddress {
+		return entity.UserTOTP{}, errors.New(apiError.MissingRecord)
+	}
+
+	return *repository.userTOTP, nil
+}
+
+// fakeUserCommandRepository stores the used TOTP steps and server share of the fake query repository, recovery codes never match
+type fakeUserCommandRepository struct {
+	repository.UserCommandRepositoryInterface
+	query *fakeUserQueryRepository
+}
+
+func (repository *fakeUserCommandRepository) ResetUserPassword(data repositoryTypes.ResetUserPassword) error {
+	usedAt := time.Now()
+	repository.query.resetToken.UsedAt = &usedAt
+	repository.query.user.Password = data.Password
+
+	return nil
+}
+
+func (repository *fakeUserCommandRepository) UpdateUserTOTPLastUsedStep(data repositoryTypes.UpdateUserTOTPLastUsedStep) error {
+	lastUsedStep := repository.query.userTOTP.LastUsedStep
+	if lastUsedStep != nil && data.Step <= *lastUsedStep {
+		return errors.New(apiError.InvalidTOTPCode)
+	}
+
+	repository.query.userTOTP.LastUsedStep = &data.Step
+
+	return nil
+}
+
+func (repository *fakeUserCommandRepository) UpdateUserShare(data repositoryTypes.UpdateUserShare) error {
+	if data.PreviousSSS1 != repository.query.user.SSS1 {
+		return errors.New(apiError.ShareRotated)
+	}
+
+	repository.query.user.SSS1 = data.SSS1
+	repository.query.user.SSS1DEK = data.SSS1DEK
+	repository.query.user.SSS1KeyID = data.SSS1KeyID
+
+	return nil
+}
+
+func (repository *fakeUserCommandRepository) UseUserRecoveryCode(data repositoryTypes.UseUserRecoveryCode) error {
+	return errors.New(apiError.InvalidTOTPCode)
+}
+
+// fakeUserAuthService records the users signed out everywhere
+type fakeUserAuthService struct {
+	application.UserAuthServiceInterface
+	revokedWalletAddresses []string
+}
+
+func (service *fakeUserAuthService) RevokeUserSessions(ctx context.Context, walletAddress string) error {
+	service.revokedWalletAddresses = append(service.revokedWalletAddresses, walletAddress)
+
+	return nil
+}
+
+// newUserCommandService returns a service for a single user whose key is split into parts, along with the client shares
+func newUserCommandService(t *testing.T, parts, threshold int) (*UserCommandService, *fakeUserQueryRepository, []string) {
+	provider := &kms.LocalKMS{}
+	err := provider.Load(kmsTypes.LocalKMSParams{KeyFile: filepath.Join(t.TempDir(), "kms.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shares, err := wallet.Split(privateKey, parts, threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := &fakeUserQueryRepository{
+		user: entity.User{
+			WalletAddress:  wallet.Address(privateKey),
+			SSS1:           shares[0],
+			Email:          "user@example.com",
+			Name:           "Test User",
+			ShareThreshold: uint8(threshold),
+			ShareCount:     uint8(parts),
+		},
+	}
+
+	service := &UserCommandService{
+		UserCommandRepositoryInterface: &fakeUserCommandRepository{query: query},
+		UserQueryRepositoryInterface:   query,
+		KMSProviderInterface:           provider,
+		UserAuthServiceInterface:       &fakeUserAuthService{},
+		AttemptStoreInterface:          &ratelimit.MemoryAttemptStore{},
+		Policy:                         &password.Policy{MinLength: 12},
+	}
+
+	return service, query, shares[1:]
+}
+
+// newTOTPService returns a service for a user with two-factor authentication enabled, along with a client share and the TOTP secret
+func newTOTPService(t *testing.T) (*UserCommandService, entity.User, []string, string) {
+	service, query, shares := newUserCommandService(t, 3, 2)
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envelope, err := kms.Seal(service.KMSProviderInterface, []byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confirmedAt := time.Now()
+	query.userTOTP = &entity.UserTOTP{
+		WalletAddress: query.user.WalletAddress,
+		Secret:        envelope.Ciphertext,
+		SecretDEK:     envelope.WrappedKey,
+		SecretKeyID:   envelope.KeyID,
+		ConfirmedAt:   &confirmedAt,
+	}
+
+	return service, query.user, shares[:1], secret
+}
+
+func TestSignMessageRequiresTOTP(t *testing.T) {
+	service, user, shares, secret := newTOTPService(t)
+
+	sign := func(code string) error {
+		_, err := service.SignMessage(context.Background(), types.SignMessage{
+			WalletAddress: user.WalletAddress,
+			Shares:        shares,
+			Message:       []byte("hello"),
+			TOTPCode:      code,
+		})
+
+		return err
+	}
+
+	if err := sign(""); err == nil || err.Error() != apiError.TOTPRequired {
+		t.Errorf("expected %s without a code, got %v", apiError.TOTPRequired, err)
+	}
+
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sign(code); err != nil {
+		t.Fatalf("expected a current code to sign, got %v", err)
+	}
+
+	// a code is only accepted once
+	if err := sign(code); err == nil || err.Error() != apiError.InvalidTOTPCode {
+		t.Errorf("expected %s for a reused code, got %v", apiError.InvalidTOTPCode, err)
+	}
+}
+
+func TestSignMessageThrottlesWrongTOTPCodes(t *testing.T) {
+	t.Setenv("LOGIN_BACKOFF_BASE", "0s")
+	t.Setenv("LOGIN_MAX_FAILURES", "3")
+
+	service, user, shares, secret := newTOTPService(t)
+
+	sign := func(code string) error {
+		_, err := service.SignMessage(context.Background(), types.SignMessage{
+			WalletAddress: user.WalletAddress,
+			Shares:        shares,
+			Message:       []byte("hello"),
+			TOTPCode:      code,
+		})
+
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := sign("recovery-code"); err == nil || err.Error() != apiError.InvalidTOTPCode {
+			t.Fatalf("attempt %d: expected %s, got %v", i+1, apiError.InvalidTOTPCode, err)
+		}
+	}
+
+	// once locked out even a current code is rejected
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sign(code); err == nil || err.Error() != apiError.AccountLocked {
+		t.Errorf("expected %s after too many wrong codes, got %v", apiError.AccountLocked, err)
+	}
+}
+
+func TestRotateUserSharesWithoutSpareShare(t *testing.T) {
+	// a 2-of-2 wallet only has threshold - 1 client shares
+	service, query, shares := newUserCommandService(t, 2, 2)
+
+	rotated, err := service.RotateUserShares(context.Background(), types.RotateUserShares{
+		WalletAddress: query.user.WalletAddress,
+		Shares:        shares,
+	})
+	if err != nil {
+		t.Fatalf("expected a 2-of-2 wallet to rotate, got %v", err)
+	}
+	if len(rotated.Shares) != 1 || rotated.Shares[0].Share == shares[0] {
+		t.Fatalf("expected a new client share, got %+v", rotated.Shares)
+	}
+
+	// the previous client share no longer combines with the new server share
+	_, err = service.SignMessage(context.Background(), types.SignMessage{
+		WalletAddress: query.user.WalletAddress,
+		Shares:        shares,
+		Message:       []byte("hello"),
+	})
+	if err == nil {
+		t.Error("expected the previous client share to be rejected")
+	}
+
+	_, err = service.SignMessage(context.Background(), types.SignMessage{
+		WalletAddress: query.user.WalletAddress,
+		Shares:        []string{rotated.Shares[0].Share},
+		Message:       []byte("hello"),
+	})
+	if err != nil {
+		t.Errorf("expected the rotated client share to sign, got %v", err)
+	}
+}
+
+func TestRotateUserSharesRejectsServerShare(t *testing.T) {
+	service, query, _ := newUserCommandService(t, 3, 2)
+
+	_, err := service.RotateUserShares(context.Background(), types.RotateUserShares{
+		WalletAddress: query.user.WalletAddress,
+		Shares:        []string{query.user.SSS1},
+	})
+	if err == nil || err.Error() != apiError.InvalidShare {
+		t.Errorf("expected %s for the server share, got %v", apiError.InvalidShare, err)
+	}
+}
+
+func TestUpdateUserShareAfterConcurrentRotation(t *testing.T) {
+	service, query, shares := newUserCommandService(t, 3, 2)
+
+	// both rotations read the same server share
+	stale := query.user.SSS1
+
+	_, err := service.RotateUserShares(context.Background(), types.RotateUserShares{
+		WalletAddress: query.user.WalletAddress,
+		Shares:        shares[:1],
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = service.UserCommandRepositoryInterface.UpdateUserShare(repositoryTypes.UpdateUserShare{
+		WalletAddress: query.user.WalletAddress,
+		SSS1:          "share computed from the stale server share",
+		PreviousSSS1:  stale,
+	})
+	if err == nil || err.Error() != apiError.ShareRotated {
+		t.Errorf("expected %s for the later rotation, got %v", apiError.ShareRotated, err)
+	}
+}
+
+func TestResetUserPassword(t *testing.T) {
+	service, query, _ := newUserCommandService(t, 3, 2)
+
+	resetToken, tokenHash, err := token.GenerateOpaqueToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query.resetToken = &entity.PasswordResetToken{
+		ID:            "reset-token",
+		WalletAddress: query.user.WalletAddress,
+		TokenHash:     tokenHash,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+
+	tests := map[string]struct {
+		token    string
+		password string
+		expected string
+	}{
+		"unknown token":  {"unknown", "k7#Rv9!qLm2&Zx", apiError.InvalidToken},
+		"short password": {resetToken, "Sh0rt!pass", apiError.PasswordTooShort},
+	}
+
+	for name, test := range tests {
+		err := service.ResetUserPassword(context.Background(), types.ResetUserPassword{
+			Token:    test.token,
+			Password: test.password,
+		})
+		if err == nil || err.Error() != test.expected {
+			t.Errorf("%s: expected %s, got %v", name, test.expected, err)
+		}
+	}
+
+	err = service.ResetUserPassword(context.Background(), types.ResetUserPassword{
+		Token:    resetToken,
+		Password: "k7#Rv9!qLm2&Zx",
+	})
+	if err != nil {
+		t.Fatalf("expected the password to be reset, got %v", err)
+	}
+
+	if !password.CheckPasswordHash("k7#Rv9!qLm2&Zx", query.user.Password) {
+		t.Error("expected the new password to be stored")
+	}
+
+	revoked := service.UserAuthServiceInterface.(*fakeUserAuthService).revokedWalletAddresses
+	if len(revoked) != 1 || revoked[0] != query.user.WalletAddress {
+		t.Errorf("expected every session of the user to be revoked, got %v", revoked)
+	}
+
+	// a token is only accepted once
+	err = service.ResetUserPassword(context.Background(), types.ResetUserPassword{
+		Token:    resetToken,
+		Password: "k7#Rv9!qLm2&Zy",
+	})
+	if err == nil || err.Error() != apiError.InvalidToken {
+		t.Errorf("expected %s for a used token, got %v", apiError.InvalidToken, err)
+	}
+}
+
+func TestResetUserPasswordExpiredToken(t *testing.T) {
+	service, query, _ := newUserCommandService(t, 3, 2)
+
+	resetToken, tokenHash, err := token.GenerateOpaqueToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query.resetToken = &entity.PasswordResetToken{
+		ID:            "reset-token",
+		WalletAddress: query.user.WalletAddress,
+		TokenHash:     tokenHash,
+		ExpiresAt:     time.Now().Add(-time.Minute),
+	}
+
+	err = service.ResetUserPassword(context.Background(), types.ResetUserPassword{
+		Token:    resetToken,
+		Password: "k7#Rv9!qLm2&Zx",
+	})
+	if err == nil || err.Error() != apiError.InvalidToken {
+		t.Errorf("expected %s for an expired token, got %v", apiError.InvalidToken, err)
+	}
+}
+
+func TestSendPasswordResetUnknownEmail(t *testing.T) {
+	service, _, _ := newUserCommandService(t, 3, 2)
+
+	// unknown emails succeed silently so the endpoint does not reveal registered emails
+	if err := service.SendPasswordReset(context.Background(), "unknown@example.com"); err != nil {
+		t.Errorf("expected no error for an unknown email, got %v", err)
+	}
+}
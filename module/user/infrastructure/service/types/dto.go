@@ -1,15 +1,125 @@
 package types
 
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+type ConfirmUserTOTP struct {
+	WalletAddress string
+	Code          string
+}
+
 type CreateUser struct {
-	Email    string
-	Password string
-	Name     string
+	Email     string
+	Password  string
+	Name      string
+	Threshold int               // zero uses the configured default
+	Shares    []CreateUserShare // client held shares, empty uses the configured default
+}
+
+type CreateUserShare struct {
+	HolderType string
+	Metadata   map[string]string
 }
 
 type CreateUserResult struct {
 	WalletAddress string
-	SSS2          string
-	SSS3          string
+	Threshold     uint8
+	Shares        []UserShare
+}
+
+type UserShare struct {
+	ShareIndex uint8
+	HolderType string
+	Share      string
+}
+
+type DisableUserTOTP struct {
+	WalletAddress string
+	Password      string
+	Code          string // TOTP or recovery code
+}
+
+type EnrollUserTOTPResult struct {
+	Secret string
+	URI    string
+}
+
+type RecoverUserWallet struct {
+	WalletAddress    string
+	Shares           []string
+	RevealPrivateKey bool
+	Password         string
+	TOTPCode         string // TOTP or recovery code, required once two-factor authentication is enabled
+}
+
+type RecoverUserWalletResult struct {
+	WalletAddress string
+	PrivateKey    *string
+}
+
+type RegenerateUserRecoveryCodes struct {
+	WalletAddress string
+	Code          string // TOTP or recovery code
+}
+
+type RotateUserShares struct {
+	WalletAddress string
+	Shares        []string
+	TOTPCode      string
+}
+
+type RotateUserSharesResult struct {
+	WalletAddress string
+	Threshold     uint8
+	Shares        []UserShare
+}
+
+type SignMessage struct {
+	WalletAddress string
+	Shares        []string
+	Message       []byte
+	TOTPCode      string
+}
+
+type SignMessageResult struct {
+	Signature string
+	Address   string
+}
+
+type SignTransaction struct {
+	WalletAddress        string
+	Shares               []string
+	ChainID              *big.Int
+	Nonce                uint64
+	To                   *common.Address
+	Value                *big.Int
+	Data                 []byte
+	Gas                  uint64
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	TOTPCode             string
+}
+
+type SignTransactionResult struct {
+	RawTransaction string
+	Hash           string
+}
+
+type SignTypedData struct {
+	WalletAddress string
+	Shares        []string
+	TypedData     apitypes.TypedData
+	TOTPCode      string
+}
+
+type SignTypedDataResult struct {
+	Signature string
+	Hash      string
+	Address   string
 }
 
 type UpdateUser struct {
@@ -17,7 +127,17 @@ type UpdateUser struct {
 	Name          string
 }
 
+type ResetUserPassword struct {
+	Token    string
+	Password string
+}
+
 type UpdateUserPassword struct {
-	WalletAddress string
-	Password      string
+	WalletAddress   string
+	CurrentPassword string
+	NewPassword     string
+}
+
+type UserRecoveryCodesResult struct {
+	RecoveryCodes []string
 }
@@ -1,25 +1,117 @@
 package http
 
 import (
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/go-playground/validator/v10"
 )
 
 var (
 	Validate         *validator.Validate = validator.New(validator.WithRequiredStructEnabled())
 	ValidationErrors map[string]string   = map[string]string{
-		"CreateUserRequest.Email":                   "Email field is required.",
-		"CreateUserRequest.Password":                "Password field is required.",
-		"CreateUserRequest.Name":                    "Name field is required.",
-		"UpdateUserRequest.Name":                    "Name field is required.",
-		"UpdateUserPasswordRequest.CurrentPassword": "Current password field is required.",
-		"UpdateUserPasswordRequest.NewPassword":     "New password field is required.",
+		"ConfirmUserTOTPRequest.Code":                 "Code field is required.",
+		"CreateUserRequest.Email":                     "Email field is required.",
+		"CreateUserRequest.Password":                  "Password field is required.",
+		"CreateUserRequest.Name":                      "Name field is required.",
+		"CreateUserRequest.Threshold":                 "Threshold must be at least 2.",
+		"DisableUserTOTPRequest.Password":             "Password field is required.",
+		"DisableUserTOTPRequest.Code":                 "Code field is required.",
+		"RecoverUserWalletRequest.Shares":             "Shares field is required.",
+		"RecoverUserWalletRequest.Password":           "Password field is required to reveal the private key.",
+		"RegenerateUserRecoveryCodesRequest.Code":     "Code field is required.",
+		"ResendEmailVerificationRequest.Email":        "Email field must be a valid email.",
+		"ResetUserPasswordRequest.Token":              "Token field is required.",
+		"ResetUserPasswordRequest.Password":           "Password field is required.",
+		"RotateUserSharesRequest.Shares":              "Shares field is required.",
+		"SendPasswordResetRequest.Email":              "Email field must be a valid email.",
+		"SignMessageRequest.Shares":                   "Shares field is required.",
+		"SignMessageRequest.Message":                  "Message field is required.",
+		"SignTransactionRequest.Shares":               "Shares field is required.",
+		"SignTransactionRequest.ChainID":              "Chain ID field is required.",
+		"SignTransactionRequest.To":                   "To field must be a valid address.",
+		"SignTransactionRequest.Value":                "Value field must be a number in wei.",
+		"SignTransactionRequest.Data":                 "Data field must be hex encoded.",
+		"SignTransactionRequest.Gas":                  "Gas field is required.",
+		"SignTransactionRequest.MaxFeePerGas":         "Max fee per gas field is required.",
+		"SignTransactionRequest.MaxPriorityFeePerGas": "Max priority fee per gas field is required.",
+		"SignTypedDataRequest.Shares":                 "Shares field is required.",
+		"SignTypedDataRequest.TypedData":              "Typed data field is required.",
+		"UpdateUserEmailVerifiedAtRequest.Token":      "Token field is required.",
+		"UpdateUserRequest.Name":                      "Name field is required.",
+		"UpdateUserPasswordRequest.CurrentPassword":   "Current password field is required.",
+		"UpdateUserPasswordRequest.NewPassword":       "New password field is required.",
 	}
 )
 
+type ConfirmUserTOTPRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
 type CreateUserRequest struct {
-	Email    string `json:"email" validate:"required"`
+	Email     string                   `json:"email" validate:"required"`
+	Password  string                   `json:"password" validate:"required"`
+	Name      string                   `json:"name" validate:"required"`
+	Threshold int                      `json:"threshold" validate:"omitempty,min=2"`
+	Shares    []CreateUserShareRequest `json:"shares"` // client held shares, the server share is always added
+}
+
+type CreateUserShareRequest struct {
+	HolderType string            `json:"holderType"`
+	Metadata   map[string]string `json:"metadata"`
+}
+
+type DisableUserTOTPRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"` // TOTP or recovery code
+}
+
+type RecoverUserWalletRequest struct {
+	Shares           []string `json:"shares" validate:"required,min=1,dive,base64"`
+	RevealPrivateKey bool     `json:"revealPrivateKey"`
+	Password         string   `json:"password" validate:"required_if=RevealPrivateKey true"`
+	TOTPCode         string   `json:"totpCode"` // required once two-factor authentication is enabled
+}
+
+type RegenerateUserRecoveryCodesRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type ResetUserPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
 	Password string `json:"password" validate:"required"`
-	Name     string `json:"name" validate:"required"`
+}
+
+type RotateUserSharesRequest struct {
+	Shares   []string `json:"shares" validate:"required,min=1,unique,dive,base64"`
+	TOTPCode string   `json:"totpCode"`
+}
+
+type SendPasswordResetRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type SignMessageRequest struct {
+	Shares   []string `json:"shares" validate:"required,min=1,dive,base64"`
+	Message  string   `json:"message" validate:"required"` // 0x-prefixed messages are signed as raw bytes
+	TOTPCode string   `json:"totpCode"`
+}
+
+type SignTransactionRequest struct {
+	Shares               []string `json:"shares" validate:"required,min=1,dive,base64"`
+	ChainID              string   `json:"chainId" validate:"required,number"`
+	Nonce                uint64   `json:"nonce"`
+	To                   *string  `json:"to" validate:"omitempty,eth_addr"` // nil for contract creation
+	Value                string   `json:"value" validate:"omitempty,number"`
+	Data                 string   `json:"data" validate:"omitempty,hexadecimal"`
+	Gas                  uint64   `json:"gas" validate:"required"`
+	MaxFeePerGas         string   `json:"maxFeePerGas" validate:"required,number"`
+	MaxPriorityFeePerGas string   `json:"maxPriorityFeePerGas" validate:"required,number"`
+	TOTPCode             string   `json:"totpCode"`
+}
+
+type SignTypedDataRequest struct {
+	Shares    []string           `json:"shares" validate:"required,min=1,dive,base64"`
+	TypedData apitypes.TypedData `json:"typedData" validate:"required"`
+	TOTPCode  string             `json:"totpCode"`
 }
 
 type UpdateUserRequest struct {
@@ -27,31 +119,91 @@ type UpdateUserRequest struct {
 }
 
 type UpdateUserEmailVerifiedAtRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendEmailVerificationRequest struct {
 	Email string `json:"email" validate:"required,email"`
 }
 
 type UpdateUserPasswordRequest struct {
-	Password string `json:"password" validate:"required"`
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	NewPassword     string `json:"newPassword" validate:"required"`
 }
 
 type CreateUserResponse struct {
-	WalletAddress string `json:"walletAddress"`
-	SSS2          string `json:"sss2"`
-	SSS3          string `json:"sss3"`
+	WalletAddress string              `json:"walletAddress"`
+	Threshold     uint8               `json:"threshold"`
+	Shares        []UserShareResponse `json:"shares"`
+}
+
+type UserShareResponse struct {
+	Index      uint8  `json:"index"`
+	HolderType string `json:"holderType"`
+	Share      string `json:"share"`
+}
+
+type RecoverUserWalletResponse struct {
+	WalletAddress string  `json:"walletAddress"`
+	PrivateKey    *string `json:"privateKey,omitempty"`
+}
+
+type EnrollUserTOTPResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"` // otpauth URI for authenticator app QR codes
+}
+
+type UserRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type RotateUserSharesResponse struct {
+	WalletAddress string              `json:"walletAddress"`
+	Threshold     uint8               `json:"threshold"`
+	Shares        []UserShareResponse `json:"shares"`
+}
+
+type SignMessageResponse struct {
+	Signature string `json:"signature"`
+	Address   string `json:"address"`
+}
+
+type SignTransactionResponse struct {
+	RawTransaction string `json:"rawTransaction"`
+	Hash           string `json:"hash"`
+}
+
+type SignTypedDataResponse struct {
+	Signature string `json:"signature"`
+	Hash      string `json:"hash"`
+	Address   string `json:"address"`
 }
 
 type GetUserResponse struct {
 	WalletAddress   string  `json:"walletAddress"`
 	Email           string  `json:"email"`
-	Password        string  `json:"password"`
-	SSS1            string  `json:"sss1"`
+	ShareThreshold  uint8   `json:"shareThreshold"`
+	ShareCount      uint8   `json:"shareCount"`
 	Name            string  `json:"name"`
 	EmailVerifiedAt *uint64 `json:"emailVerifiedAt"`
 	CreatedAt       uint64  `json:"createdAt"`
 	UpdatedAt       uint64  `json:"updatedAt"`
 }
 
+// GetPrivilegedUserResponse is only returned to callers with the internal scope
+type GetPrivilegedUserResponse struct {
+	GetUserResponse
+	Password  string  `json:"password"`
+	SSS1      string  `json:"sss1"`
+	SSS1KeyID *string `json:"sss1KeyId"`
+}
+
 type GetPaginatedUserResponse struct {
 	Users []GetUserResponse `json:"users"`
 	Total uint              `json:"total"`
 }
+
+type GetPaginatedPrivilegedUserResponse struct {
+	Users []GetPrivilegedUserResponse `json:"users"`
+	Total uint                        `json:"total"`
+}
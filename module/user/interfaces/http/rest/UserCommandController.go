@@ -3,15 +3,20 @@ package rest
 import (
 	"context"
 	"encoding/json"
+	"math/big"
 	"net/http"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 
+	iam "celeste/interfaces/http/rest/middlewares/iam"
 	"celeste/interfaces/http/rest/viewmodels"
 	"celeste/internal/errors"
 	apiError "celeste/internal/errors"
+	"celeste/internal/rbac"
 	"celeste/module/user/application"
 	serviceTypes "celeste/module/user/infrastructure/service/types"
 	types "celeste/module/user/interfaces/http"
@@ -22,6 +27,124 @@ type UserCommandController struct {
 	application.UserCommandServiceInterface
 }
 
+// ConfirmUserTOTP request handler to enable two-factor authentication
+func (controller *UserCommandController) ConfirmUserTOTP(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	if len(walletAddress) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address is required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// only the account owner may manage two-factor authentication
+	if !iam.IsSubject(r.Context(), walletAddress) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only manage your own two-factor authentication.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	var request types.ConfirmUserTOTPRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	res, err := controller.UserCommandServiceInterface.ConfirmUserTOTP(context.TODO(), serviceTypes.ConfirmUserTOTP{
+		WalletAddress: walletAddress,
+		Code:          request.Code,
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Error occurred while enabling two-factor authentication."
+		case errors.InvalidTOTPCode:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Invalid two-factor authentication code."
+		case errors.TOTPAlreadyEnabled:
+			httpCode = http.StatusConflict
+			errorMsg = "Two-factor authentication is already enabled."
+		case errors.TOTPNotEnabled:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Enroll two-factor authentication first."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully enabled two-factor authentication, store the recovery codes safely.",
+		Data: &types.UserRecoveryCodesResponse{
+			RecoveryCodes: res.RecoveryCodes,
+		},
+	}
+
+	response.JSON(w)
+}
+
 // CreateUser request handler to create user
 func (controller *UserCommandController) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var request types.CreateUserRequest
@@ -65,22 +188,1086 @@ func (controller *UserCommandController) CreateUser(w http.ResponseWriter, r *ht
 		return
 	}
 
-	res, err := controller.UserCommandServiceInterface.CreateUser(context.TODO(), serviceTypes.CreateUser{
-		Email:    strings.ToLower(request.Email),
-		Password: request.Password,
-		Name:     request.Name,
+	var shares []serviceTypes.CreateUserShare
+	for _, share := range request.Shares {
+		shares = append(shares, serviceTypes.CreateUserShare{
+			HolderType: share.HolderType,
+			Metadata:   share.Metadata,
+		})
+	}
+
+	res, err := controller.UserCommandServiceInterface.CreateUser(context.TODO(), serviceTypes.CreateUser{
+		Email:     strings.ToLower(request.Email),
+		Password:  request.Password,
+		Name:      request.Name,
+		Threshold: request.Threshold,
+		Shares:    shares,
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Error occurred while saving user."
+		case errors.DuplicateRecord:
+			httpCode = http.StatusConflict
+			errorMsg = "User ID already exist."
+		case errors.InvalidPayload:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Invalid share scheme."
+		case errors.PasswordBreached:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password appears in a known data breach, choose another password."
+		case errors.PasswordContainsBannedWord:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password must not contain your email, name or common words."
+		case errors.PasswordTooLong:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password must be at most 72 bytes."
+		case errors.PasswordTooShort:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password is too short."
+		case errors.PasswordTooSimple:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password must mix lowercase, uppercase, digit and symbol characters."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully created user.",
+		Data: &types.CreateUserResponse{
+			WalletAddress: res.WalletAddress,
+			Threshold:     res.Threshold,
+			Shares:        userShareResponses(res.Shares),
+		},
+	}
+
+	response.JSON(w)
+}
+
+// DeactivateUser request handler to deactivate user
+func (controller *UserCommandController) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	if len(walletAddress) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address is required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// only the account owner or an admin may deactivate the account
+	if !iam.CanActOn(r.Context(), walletAddress, rbac.PermissionUsersDeactivate) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only deactivate your own account.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	err := controller.UserCommandServiceInterface.DeactivateUser(context.TODO(), walletAddress)
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Error occurred while updating user."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully deactivated user.",
+	}
+
+	response.JSON(w)
+}
+
+// DisableUserTOTP request handler to disable two-factor authentication
+func (controller *UserCommandController) DisableUserTOTP(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	if len(walletAddress) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address is required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// only the account owner may manage two-factor authentication
+	if !iam.IsSubject(r.Context(), walletAddress) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only manage your own two-factor authentication.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	var request types.DisableUserTOTPRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	err = controller.UserCommandServiceInterface.DisableUserTOTP(context.TODO(), serviceTypes.DisableUserTOTP{
+		WalletAddress: walletAddress,
+		Password:      request.Password,
+		Code:          request.Code,
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.AccountLocked:
+			httpCode = http.StatusLocked
+			errorMsg = "Two-factor authentication is temporarily locked after too many wrong codes."
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Error occurred while disabling two-factor authentication."
+		case errors.InvalidPassword:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid password."
+		case errors.InvalidTOTPCode:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid two-factor authentication code."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "User not found."
+		case errors.TOTPNotEnabled:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Two-factor authentication is not enabled."
+		case errors.TooManyRequests:
+			httpCode = http.StatusTooManyRequests
+			errorMsg = "Too many wrong two-factor authentication codes, please try again later."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully disabled two-factor authentication.",
+	}
+
+	response.JSON(w)
+}
+
+// EnrollUserTOTP request handler to generate a TOTP secret
+func (controller *UserCommandController) EnrollUserTOTP(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	if len(walletAddress) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address is required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// only the account owner may manage two-factor authentication
+	if !iam.IsSubject(r.Context(), walletAddress) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only manage your own two-factor authentication.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	res, err := controller.UserCommandServiceInterface.EnrollUserTOTP(context.TODO(), walletAddress)
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Error occurred while enrolling two-factor authentication."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "User not found."
+		case errors.TOTPAlreadyEnabled:
+			httpCode = http.StatusConflict
+			errorMsg = "Two-factor authentication is already enabled."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Scan the secret with an authenticator app and confirm a code to enable two-factor authentication.",
+		Data: &types.EnrollUserTOTPResponse{
+			Secret: res.Secret,
+			URI:    res.URI,
+		},
+	}
+
+	response.JSON(w)
+}
+
+// RecoverUserWallet request handler to recover the user wallet from a client share
+func (controller *UserCommandController) RecoverUserWallet(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	if len(walletAddress) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address is required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// only the account owner may use the wallet key, there is no admin bypass
+	if !iam.IsSubject(r.Context(), walletAddress) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only use your own wallet.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	var request types.RecoverUserWalletRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	res, err := controller.UserCommandServiceInterface.RecoverUserWallet(context.TODO(), serviceTypes.RecoverUserWallet{
+		WalletAddress:    walletAddress,
+		Shares:           request.Shares,
+		RevealPrivateKey: request.RevealPrivateKey,
+		Password:         request.Password,
+		TOTPCode:         request.TOTPCode,
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.AccountLocked:
+			httpCode = http.StatusLocked
+			errorMsg = "Two-factor authentication is temporarily locked after too many wrong codes."
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Error occurred while recovering wallet."
+		case errors.InvalidPassword:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid password."
+		case errors.InvalidShare:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Share does not match the wallet."
+		case errors.InvalidTOTPCode:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid two-factor authentication code."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "No records found."
+		case errors.TOTPRequired:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Two-factor authentication code is required."
+		case errors.TooManyRequests:
+			httpCode = http.StatusTooManyRequests
+			errorMsg = "Too many wrong two-factor authentication codes, please try again later."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully recovered wallet.",
+		Data: &types.RecoverUserWalletResponse{
+			WalletAddress: res.WalletAddress,
+			PrivateKey:    res.PrivateKey,
+		},
+	}
+
+	response.JSON(w)
+}
+
+// RegenerateUserRecoveryCodes request handler to replace the recovery codes
+func (controller *UserCommandController) RegenerateUserRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	if len(walletAddress) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address is required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// only the account owner may manage two-factor authentication
+	if !iam.IsSubject(r.Context(), walletAddress) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only manage your own two-factor authentication.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	var request types.RegenerateUserRecoveryCodesRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	res, err := controller.UserCommandServiceInterface.RegenerateUserRecoveryCodes(context.TODO(), serviceTypes.RegenerateUserRecoveryCodes{
+		WalletAddress: walletAddress,
+		Code:          request.Code,
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.AccountLocked:
+			httpCode = http.StatusLocked
+			errorMsg = "Two-factor authentication is temporarily locked after too many wrong codes."
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Error occurred while regenerating recovery codes."
+		case errors.InvalidTOTPCode:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid two-factor authentication code."
+		case errors.TOTPNotEnabled:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Two-factor authentication is not enabled."
+		case errors.TooManyRequests:
+			httpCode = http.StatusTooManyRequests
+			errorMsg = "Too many wrong two-factor authentication codes, please try again later."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully regenerated recovery codes, the previous codes no longer work.",
+		Data: &types.UserRecoveryCodesResponse{
+			RecoveryCodes: res.RecoveryCodes,
+		},
+	}
+
+	response.JSON(w)
+}
+
+// ResendEmailVerification request handler to send a new email verification token
+func (controller *UserCommandController) ResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	var request types.ResendEmailVerificationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	err = controller.UserCommandServiceInterface.ResendEmailVerification(context.TODO(), strings.ToLower(request.Email))
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Verification email sent if the email is registered and not yet verified.",
+	}
+
+	response.JSON(w)
+}
+
+// ResetUserPassword request handler to reset user password with the emailed token
+func (controller *UserCommandController) ResetUserPassword(w http.ResponseWriter, r *http.Request) {
+	var request types.ResetUserPasswordRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	err = controller.UserCommandServiceInterface.ResetUserPassword(context.TODO(), serviceTypes.ResetUserPassword{
+		Token:    request.Token,
+		Password: request.Password,
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Error occurred while resetting user password."
+		case errors.InvalidToken:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Invalid or expired password reset token."
+		case errors.PasswordBreached:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password appears in a known data breach, choose another password."
+		case errors.PasswordContainsBannedWord:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password must not contain your email, name or common words."
+		case errors.PasswordTooLong:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password must be at most 72 bytes."
+		case errors.PasswordTooShort:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password is too short."
+		case errors.PasswordTooSimple:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password must mix lowercase, uppercase, digit and symbol characters."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Password reset, please sign in again.",
+	}
+
+	response.JSON(w)
+}
+
+// RotateUserShares request handler to rotate the user wallet shares
+func (controller *UserCommandController) RotateUserShares(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	if len(walletAddress) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address is required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// only the account owner may use the wallet key, there is no admin bypass
+	if !iam.IsSubject(r.Context(), walletAddress) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only use your own wallet.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	var request types.RotateUserSharesRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	res, err := controller.UserCommandServiceInterface.RotateUserShares(context.TODO(), serviceTypes.RotateUserShares{
+		WalletAddress: walletAddress,
+		Shares:        request.Shares,
+		TOTPCode:      request.TOTPCode,
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.AccountLocked:
+			httpCode = http.StatusLocked
+			errorMsg = "Two-factor authentication is temporarily locked after too many wrong codes."
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Error occurred while rotating shares."
+		case errors.InvalidShare:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Shares do not match the wallet."
+		case errors.InvalidTOTPCode:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid two-factor authentication code."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "No records found."
+		case errors.ShareRotated:
+			httpCode = http.StatusConflict
+			errorMsg = "Shares were rotated by another request, use the shares it returned."
+		case errors.TOTPRequired:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Two-factor authentication code is required."
+		case errors.TooManyRequests:
+			httpCode = http.StatusTooManyRequests
+			errorMsg = "Too many wrong two-factor authentication codes, please try again later."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully rotated shares.",
+		Data: &types.RotateUserSharesResponse{
+			WalletAddress: res.WalletAddress,
+			Threshold:     res.Threshold,
+			Shares:        userShareResponses(res.Shares),
+		},
+	}
+
+	response.JSON(w)
+}
+
+// SendPasswordReset request handler to email a password reset token
+func (controller *UserCommandController) SendPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var request types.SendPasswordResetRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	err = controller.UserCommandServiceInterface.SendPasswordReset(context.TODO(), strings.ToLower(request.Email))
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Database error."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Password reset email sent if the email is registered.",
+	}
+
+	response.JSON(w)
+}
+
+// SignMessage request handler to sign an EIP-191 personal message
+func (controller *UserCommandController) SignMessage(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	if len(walletAddress) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address is required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// only the account owner may use the wallet key, there is no admin bypass
+	if !iam.IsSubject(r.Context(), walletAddress) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only use your own wallet.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	var request types.SignMessageRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// hex encoded messages are signed as raw bytes, same as personal_sign
+	message := []byte(request.Message)
+	if decoded, err := hexutil.Decode(request.Message); err == nil {
+		message = decoded
+	}
+
+	res, err := controller.UserCommandServiceInterface.SignMessage(context.TODO(), serviceTypes.SignMessage{
+		WalletAddress: walletAddress,
+		Shares:        request.Shares,
+		Message:       message,
+		TOTPCode:      request.TOTPCode,
 	})
 	if err != nil {
 		var httpCode int
 		var errorMsg string
 
 		switch err.Error() {
+		case errors.AccountLocked:
+			httpCode = http.StatusLocked
+			errorMsg = "Two-factor authentication is temporarily locked after too many wrong codes."
 		case errors.DatabaseError:
 			httpCode = http.StatusInternalServerError
-			errorMsg = "Error occurred while saving user."
-		case errors.DuplicateRecord:
-			httpCode = http.StatusConflict
-			errorMsg = "User ID already exist."
+			errorMsg = "Error occurred while signing message."
+		case errors.InvalidShare:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Share does not match the wallet."
+		case errors.InvalidTOTPCode:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid two-factor authentication code."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "No records found."
+		case errors.TOTPRequired:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Two-factor authentication code is required."
+		case errors.TooManyRequests:
+			httpCode = http.StatusTooManyRequests
+			errorMsg = "Too many wrong two-factor authentication codes, please try again later."
 		default:
 			httpCode = http.StatusInternalServerError
 			errorMsg = "Please contact technical support."
@@ -100,19 +1287,18 @@ func (controller *UserCommandController) CreateUser(w http.ResponseWriter, r *ht
 	response := viewmodels.HTTPResponseVM{
 		Status:  http.StatusOK,
 		Success: true,
-		Message: "Successfully created user.",
-		Data: &types.CreateUserResponse{
-			WalletAddress: res.WalletAddress,
-			SSS2:          res.SSS2,
-			SSS3:          res.SSS3,
+		Message: "Successfully signed message.",
+		Data: &types.SignMessageResponse{
+			Signature: res.Signature,
+			Address:   res.Address,
 		},
 	}
 
 	response.JSON(w)
 }
 
-// DeactivateUser request handler to deactivate user
-func (controller *UserCommandController) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+// SignTransaction request handler to sign an EIP-1559 transaction
+func (controller *UserCommandController) SignTransaction(w http.ResponseWriter, r *http.Request) {
 	walletAddress := chi.URLParam(r, "walletAddress")
 	if len(walletAddress) == 0 {
 		response := viewmodels.HTTPResponseVM{
@@ -126,15 +1312,130 @@ func (controller *UserCommandController) DeactivateUser(w http.ResponseWriter, r
 		return
 	}
 
-	err := controller.UserCommandServiceInterface.DeactivateUser(context.TODO(), walletAddress)
+	// only the account owner may use the wallet key, there is no admin bypass
+	if !iam.IsSubject(r.Context(), walletAddress) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only use your own wallet.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	var request types.SignTransactionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	tx := serviceTypes.SignTransaction{
+		WalletAddress: walletAddress,
+		Shares:        request.Shares,
+		Nonce:         request.Nonce,
+		Gas:           request.Gas,
+		TOTPCode:      request.TOTPCode,
+	}
+
+	var ok bool
+	tx.ChainID, ok = new(big.Int).SetString(request.ChainID, 10)
+	if ok {
+		tx.MaxFeePerGas, ok = new(big.Int).SetString(request.MaxFeePerGas, 10)
+	}
+	if ok {
+		tx.MaxPriorityFeePerGas, ok = new(big.Int).SetString(request.MaxPriorityFeePerGas, 10)
+	}
+	if ok && len(request.Value) > 0 {
+		tx.Value, ok = new(big.Int).SetString(request.Value, 10)
+	}
+	if ok && len(request.Data) > 0 {
+		tx.Data, err = hexutil.Decode(request.Data)
+		ok = err == nil
+	}
+	if !ok {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid transaction values.",
+			ErrorCode: apiError.InvalidPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	if request.To != nil {
+		to := common.HexToAddress(*request.To)
+		tx.To = &to
+	}
+
+	res, err := controller.UserCommandServiceInterface.SignTransaction(context.TODO(), tx)
 	if err != nil {
 		var httpCode int
 		var errorMsg string
 
 		switch err.Error() {
+		case errors.AccountLocked:
+			httpCode = http.StatusLocked
+			errorMsg = "Two-factor authentication is temporarily locked after too many wrong codes."
 		case errors.DatabaseError:
 			httpCode = http.StatusInternalServerError
-			errorMsg = "Error occurred while updating user."
+			errorMsg = "Error occurred while signing transaction."
+		case errors.InvalidPayload:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Invalid transaction values."
+		case errors.InvalidShare:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Share does not match the wallet."
+		case errors.InvalidTOTPCode:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid two-factor authentication code."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "No records found."
+		case errors.TOTPRequired:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Two-factor authentication code is required."
+		case errors.TooManyRequests:
+			httpCode = http.StatusTooManyRequests
+			errorMsg = "Too many wrong two-factor authentication codes, please try again later."
 		default:
 			httpCode = http.StatusInternalServerError
 			errorMsg = "Please contact technical support."
@@ -154,13 +1455,154 @@ func (controller *UserCommandController) DeactivateUser(w http.ResponseWriter, r
 	response := viewmodels.HTTPResponseVM{
 		Status:  http.StatusOK,
 		Success: true,
-		Message: "Successfully deactivated user.",
+		Message: "Successfully signed transaction.",
+		Data: &types.SignTransactionResponse{
+			RawTransaction: res.RawTransaction,
+			Hash:           res.Hash,
+		},
+	}
+
+	response.JSON(w)
+}
+
+// SignTypedData request handler to sign EIP-712 typed data
+func (controller *UserCommandController) SignTypedData(w http.ResponseWriter, r *http.Request) {
+	walletAddress := chi.URLParam(r, "walletAddress")
+	if len(walletAddress) == 0 {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Wallet address is required.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// only the account owner may use the wallet key, there is no admin bypass
+	if !iam.IsSubject(r.Context(), walletAddress) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only use your own wallet.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	var request types.SignTypedDataRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// validate request
+	err := types.Validate.Struct(request)
+	if err != nil {
+		errors := err.(validator.ValidationErrors)
+		if len(errors) > 0 {
+			response := viewmodels.HTTPResponseVM{
+				Status:    http.StatusBadRequest,
+				Success:   false,
+				Message:   types.ValidationErrors[errors[0].StructNamespace()],
+				ErrorCode: apiError.InvalidPayload,
+			}
+
+			response.JSON(w)
+			return
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusBadRequest,
+			Success:   false,
+			Message:   "Invalid payload request.",
+			ErrorCode: apiError.InvalidRequestPayload,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	res, err := controller.UserCommandServiceInterface.SignTypedData(context.TODO(), serviceTypes.SignTypedData{
+		WalletAddress: walletAddress,
+		Shares:        request.Shares,
+		TypedData:     request.TypedData,
+		TOTPCode:      request.TOTPCode,
+	})
+	if err != nil {
+		var httpCode int
+		var errorMsg string
+
+		switch err.Error() {
+		case errors.AccountLocked:
+			httpCode = http.StatusLocked
+			errorMsg = "Two-factor authentication is temporarily locked after too many wrong codes."
+		case errors.DatabaseError:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Error occurred while signing typed data."
+		case errors.InvalidPayload:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Invalid typed data."
+		case errors.InvalidShare:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Share does not match the wallet."
+		case errors.InvalidTOTPCode:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Invalid two-factor authentication code."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "No records found."
+		case errors.UnsupportedDomain:
+			httpCode = http.StatusForbidden
+			errorMsg = "Typed data domain chain or verifying contract is not allowed."
+		case errors.TOTPRequired:
+			httpCode = http.StatusUnauthorized
+			errorMsg = "Two-factor authentication code is required."
+		case errors.TooManyRequests:
+			httpCode = http.StatusTooManyRequests
+			errorMsg = "Too many wrong two-factor authentication codes, please try again later."
+		default:
+			httpCode = http.StatusInternalServerError
+			errorMsg = "Please contact technical support."
+		}
+
+		response := viewmodels.HTTPResponseVM{
+			Status:    httpCode,
+			Success:   false,
+			Message:   errorMsg,
+			ErrorCode: err.Error(),
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	response := viewmodels.HTTPResponseVM{
+		Status:  http.StatusOK,
+		Success: true,
+		Message: "Successfully signed typed data.",
+		Data: &types.SignTypedDataResponse{
+			Signature: res.Signature,
+			Hash:      res.Hash,
+			Address:   res.Address,
+		},
 	}
 
 	response.JSON(w)
 }
 
-// UpdateUserEmailVerifiedAt request handler to update user email verified at
+// UpdateUserEmailVerifiedAt request handler to verify user email with the emailed token
 func (controller *UserCommandController) UpdateUserEmailVerifiedAt(w http.ResponseWriter, r *http.Request) {
 	var request types.UpdateUserEmailVerifiedAtRequest
 
@@ -203,7 +1645,7 @@ func (controller *UserCommandController) UpdateUserEmailVerifiedAt(w http.Respon
 		return
 	}
 
-	err = controller.UserCommandServiceInterface.UpdateUserEmailVerifiedAt(context.TODO(), request.Email)
+	err = controller.UserCommandServiceInterface.UpdateUserEmailVerifiedAt(context.TODO(), request.Token)
 	if err != nil {
 		var httpCode int
 		var errorMsg string
@@ -212,6 +1654,9 @@ func (controller *UserCommandController) UpdateUserEmailVerifiedAt(w http.Respon
 		case errors.DatabaseError:
 			httpCode = http.StatusInternalServerError
 			errorMsg = "Error occurred while updating user email verified at."
+		case errors.InvalidToken:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Invalid or expired verification token."
 		default:
 			httpCode = http.StatusInternalServerError
 			errorMsg = "Please contact technical support."
@@ -252,6 +1697,19 @@ func (controller *UserCommandController) UpdateUserByWalletAddress(w http.Respon
 		return
 	}
 
+	// only the account owner or an admin may update the account
+	if !iam.CanActOn(r.Context(), walletAddress, rbac.PermissionUsersUpdate) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only update your own account.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
 	var request types.UpdateUserRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -345,6 +1803,19 @@ func (controller *UserCommandController) UpdateUserPassword(w http.ResponseWrite
 		return
 	}
 
+	// only the account owner may change the password
+	if !iam.IsSubject(r.Context(), walletAddress) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only change your own password.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
 	var request types.UpdateUserPasswordRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -387,8 +1858,9 @@ func (controller *UserCommandController) UpdateUserPassword(w http.ResponseWrite
 	}
 
 	err = controller.UserCommandServiceInterface.UpdateUserPassword(context.TODO(), serviceTypes.UpdateUserPassword{
-		WalletAddress: walletAddress,
-		Password:      request.Password,
+		WalletAddress:   walletAddress,
+		CurrentPassword: request.CurrentPassword,
+		NewPassword:     request.NewPassword,
 	})
 	if err != nil {
 		var httpCode int
@@ -398,6 +1870,30 @@ func (controller *UserCommandController) UpdateUserPassword(w http.ResponseWrite
 		case errors.DatabaseError:
 			httpCode = http.StatusInternalServerError
 			errorMsg = "Error occurred while updating password."
+		case errors.InvalidPassword:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Current password is incorrect."
+		case errors.MissingRecord:
+			httpCode = http.StatusNotFound
+			errorMsg = "User not found."
+		case errors.PasswordUnchanged:
+			httpCode = http.StatusBadRequest
+			errorMsg = "New password must differ from the current password."
+		case errors.PasswordBreached:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password appears in a known data breach, choose another password."
+		case errors.PasswordContainsBannedWord:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password must not contain your email, name or common words."
+		case errors.PasswordTooLong:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password must be at most 72 bytes."
+		case errors.PasswordTooShort:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password is too short."
+		case errors.PasswordTooSimple:
+			httpCode = http.StatusBadRequest
+			errorMsg = "Password must mix lowercase, uppercase, digit and symbol characters."
 		default:
 			httpCode = http.StatusInternalServerError
 			errorMsg = "Please contact technical support."
@@ -422,3 +1918,17 @@ func (controller *UserCommandController) UpdateUserPassword(w http.ResponseWrite
 
 	response.JSON(w)
 }
+
+// userShareResponses maps the client held shares to its response
+func userShareResponses(shares []serviceTypes.UserShare) []types.UserShareResponse {
+	responses := []types.UserShareResponse{}
+	for _, share := range shares {
+		responses = append(responses, types.UserShareResponse{
+			Index:      share.ShareIndex,
+			HolderType: share.HolderType,
+			Share:      share.Share,
+		})
+	}
+
+	return responses
+}
@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+
+	apiError "celeste/internal/errors"
+	"celeste/internal/rbac"
+	"celeste/module/user/application"
+)
+
+const (
+	walletAddress      string = "0x71C7656EC7ab88b098defB751B7401B5f6d8976F"
+	otherWalletAddress string = "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"
+)
+
+// fakeUserCommandService records the deactivated users
+type fakeUserCommandService struct {
+	application.UserCommandServiceInterface
+	deactivated []string
+}
+
+func (service *fakeUserCommandService) DeactivateUser(ctx context.Context, walletAddress string) error {
+	service.deactivated = append(service.deactivated, walletAddress)
+
+	return nil
+}
+
+// newRequest returns a request on the wallet address route, authenticated with an access token of the subject granting the permissions
+func newRequest(t *testing.T, body, subject string, permissions ...interface{}) *http.Request {
+	tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+
+	accessToken, _, err := tokenAuth.Encode(map[string]interface{}{
+		"sub":                 subject,
+		rbac.ClaimPermissions: permissions,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("walletAddress", walletAddress)
+
+	r := httptest.NewRequest("PUT", "/", strings.NewReader(body))
+	ctx := context.WithValue(r.Context(), chi.RouteCtxKey, routeContext)
+
+	return r.WithContext(jwtauth.NewContext(ctx, accessToken, nil))
+}
+
+// errorCode returns the error code of the response, empty on success
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	var response struct {
+		ErrorCode string `json:"errorCode"`
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	return response.ErrorCode
+}
+
+func TestDeactivateUserAuthorization(t *testing.T) {
+	tests := map[string]struct {
+		r      *http.Request
+		status int
+	}{
+		"owner":                         {newRequest(t, "", walletAddress), http.StatusOK},
+		"other user":                    {newRequest(t, "", otherWalletAddress), http.StatusForbidden},
+		"admin with permission":         {newRequest(t, "", otherWalletAddress, rbac.PermissionUsersDeactivate), http.StatusOK},
+		"admin with another permission": {newRequest(t, "", otherWalletAddress, rbac.PermissionUsersUpdate), http.StatusForbidden},
+	}
+
+	for name, test := range tests {
+		service := &fakeUserCommandService{}
+		controller := &UserCommandController{UserCommandServiceInterface: service}
+
+		w := httptest.NewRecorder()
+		controller.DeactivateUser(w, test.r)
+
+		if w.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", name, test.status, w.Code)
+			continue
+		}
+
+		if test.status == http.StatusForbidden {
+			if code := errorCode(t, w); code != apiError.ForbiddenAccess {
+				t.Errorf("%s: expected error code %s, got %s", name, apiError.ForbiddenAccess, code)
+			}
+			if len(service.deactivated) > 0 {
+				t.Errorf("%s: expected the user to stay active", name)
+			}
+		}
+	}
+}
+
+func TestUpdateUserPasswordOwnerOnly(t *testing.T) {
+	controller := &UserCommandController{UserCommandServiceInterface: &fakeUserCommandService{}}
+
+	// the password is never updated on behalf of the user, even by callers allowed to update any user
+	w := httptest.NewRecorder()
+	controller.UpdateUserPassword(w, newRequest(t, `{}`, otherWalletAddress, rbac.PermissionUsersUpdate))
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+	if code := errorCode(t, w); code != apiError.ForbiddenAccess {
+		t.Errorf("expected error code %s, got %s", apiError.ForbiddenAccess, code)
+	}
+}
@@ -7,10 +7,14 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	iam "celeste/interfaces/http/rest/middlewares/iam"
+	"celeste/interfaces/http/rest/middlewares/scope"
 	"celeste/interfaces/http/rest/viewmodels"
 	"celeste/internal/errors"
 	apiError "celeste/internal/errors"
+	"celeste/internal/rbac"
 	"celeste/module/user/application"
+	"celeste/module/user/domain/entity"
 	types "celeste/module/user/interfaces/http"
 )
 
@@ -52,6 +56,20 @@ func (controller *UserQueryController) GetUsers(w http.ResponseWriter, r *http.R
 		}
 	}
 
+	// sensitive fields are only projected for internal callers
+	privileged := r.URL.Query().Get("projection") == "privileged"
+	if privileged && !scope.HasInternalScope(r.Context()) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "Privileged projection requires the internal scope.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
 	// optional
 	var search *string
 	searchStr := r.URL.Query().Get("query")
@@ -84,34 +102,34 @@ func (controller *UserQueryController) GetUsers(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	users := []types.GetUserResponse{}
-	for _, user := range res {
-		var emailVerifiedTimestamp *uint64
-		if user.EmailVerifiedAt != nil {
-			timestamp := uint64(user.EmailVerifiedAt.Unix())
-			emailVerifiedTimestamp = &timestamp
+	var data interface{}
+	if privileged {
+		users := []types.GetPrivilegedUserResponse{}
+		for _, user := range res {
+			users = append(users, privilegedUserResponse(user))
 		}
 
-		users = append(users, types.GetUserResponse{
-			WalletAddress:   user.WalletAddress,
-			Email:           user.Email,
-			Password:        user.Password,
-			SSS1:            user.SSS1,
-			Name:            user.Name,
-			EmailVerifiedAt: emailVerifiedTimestamp,
-			CreatedAt:       uint64(user.CreatedAt.Unix()),
-			UpdatedAt:       uint64(user.UpdatedAt.Unix()),
-		})
+		data = &types.GetPaginatedPrivilegedUserResponse{
+			Users: users,
+			Total: totalCount,
+		}
+	} else {
+		users := []types.GetUserResponse{}
+		for _, user := range res {
+			users = append(users, userResponse(user))
+		}
+
+		data = &types.GetPaginatedUserResponse{
+			Users: users,
+			Total: totalCount,
+		}
 	}
 
 	response := viewmodels.HTTPResponseVM{
 		Status:  http.StatusOK,
 		Success: true,
 		Message: "Successfully fetched all users.",
-		Data: &types.GetPaginatedUserResponse{
-			Users: users,
-			Total: totalCount,
-		},
+		Data:    data,
 	}
 
 	response.JSON(w)
@@ -132,6 +150,20 @@ func (controller *UserQueryController) GetUserByEmail(w http.ResponseWriter, r *
 		return
 	}
 
+	// sensitive fields are only projected for internal callers
+	privileged := r.URL.Query().Get("projection") == "privileged"
+	if privileged && !scope.HasInternalScope(r.Context()) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "Privileged projection requires the internal scope.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
 	res, err := controller.UserQueryServiceInterface.GetUserByEmail(context.TODO(), email)
 	if err != nil {
 		var httpCode int
@@ -157,19 +189,22 @@ func (controller *UserQueryController) GetUserByEmail(w http.ResponseWriter, r *
 		return
 	}
 
-	user := &types.GetUserResponse{
-		WalletAddress: res.WalletAddress,
-		Email:         res.Email,
-		Password:      res.Password,
-		SSS1:          res.SSS1,
-		Name:          res.Name,
-		CreatedAt:     uint64(res.CreatedAt.Unix()),
-		UpdatedAt:     uint64(res.UpdatedAt.Unix()),
+	// other users are reported as missing so emails cannot be enumerated
+	if !iam.CanActOn(r.Context(), res.WalletAddress, rbac.PermissionUsersRead) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusNotFound,
+			Success:   false,
+			Message:   "No records found.",
+			ErrorCode: apiError.MissingRecord,
+		}
+
+		response.JSON(w)
+		return
 	}
 
-	if res.EmailVerifiedAt != nil {
-		timestamp := uint64(res.EmailVerifiedAt.Unix())
-		user.EmailVerifiedAt = &timestamp
+	var user interface{} = userResponse(res)
+	if privileged {
+		user = privilegedUserResponse(res)
 	}
 
 	response := viewmodels.HTTPResponseVM{
@@ -196,6 +231,34 @@ func (controller *UserQueryController) GetUserByWalletAddress(w http.ResponseWri
 		response.JSON(w)
 		return
 	}
+
+	// only the account owner, an admin or an internal service may view the account
+	if !iam.CanActOn(r.Context(), walletAddress, rbac.PermissionUsersRead) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "You can only view your own account.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
+	// sensitive fields are only projected for internal callers
+	privileged := r.URL.Query().Get("projection") == "privileged"
+	if privileged && !scope.HasInternalScope(r.Context()) {
+		response := viewmodels.HTTPResponseVM{
+			Status:    http.StatusForbidden,
+			Success:   false,
+			Message:   "Privileged projection requires the internal scope.",
+			ErrorCode: apiError.ForbiddenAccess,
+		}
+
+		response.JSON(w)
+		return
+	}
+
 	res, err := controller.UserQueryServiceInterface.GetUserByWalletAddress(context.TODO(), walletAddress)
 	if err != nil {
 		var httpCode int
@@ -222,19 +285,9 @@ func (controller *UserQueryController) GetUserByWalletAddress(w http.ResponseWri
 		return
 	}
 
-	user := &types.GetUserResponse{
-		WalletAddress: res.WalletAddress,
-		Email:         res.Email,
-		Password:      res.Password,
-		SSS1:          res.SSS1,
-		Name:          res.Name,
-		CreatedAt:     uint64(res.CreatedAt.Unix()),
-		UpdatedAt:     uint64(res.UpdatedAt.Unix()),
-	}
-
-	if res.EmailVerifiedAt != nil {
-		timestamp := uint64(res.EmailVerifiedAt.Unix())
-		user.EmailVerifiedAt = &timestamp
+	var user interface{} = userResponse(res)
+	if privileged {
+		user = privilegedUserResponse(res)
 	}
 
 	response := viewmodels.HTTPResponseVM{
@@ -246,3 +299,33 @@ func (controller *UserQueryController) GetUserByWalletAddress(w http.ResponseWri
 
 	response.JSON(w)
 }
+
+// userResponse projects the user into the public response
+func userResponse(user entity.User) types.GetUserResponse {
+	response := types.GetUserResponse{
+		WalletAddress:  user.WalletAddress,
+		Email:          user.Email,
+		ShareThreshold: user.ShareThreshold,
+		ShareCount:     user.ShareCount,
+		Name:           user.Name,
+		CreatedAt:      uint64(user.CreatedAt.Unix()),
+		UpdatedAt:      uint64(user.UpdatedAt.Unix()),
+	}
+
+	if user.EmailVerifiedAt != nil {
+		timestamp := uint64(user.EmailVerifiedAt.Unix())
+		response.EmailVerifiedAt = &timestamp
+	}
+
+	return response
+}
+
+// privilegedUserResponse projects the user including the password hash and the encrypted server share
+func privilegedUserResponse(user entity.User) types.GetPrivilegedUserResponse {
+	return types.GetPrivilegedUserResponse{
+		GetUserResponse: userResponse(user),
+		Password:        user.Password,
+		SSS1:            user.SSS1,
+		SSS1KeyID:       user.SSS1KeyID,
+	}
+}